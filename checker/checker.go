@@ -0,0 +1,342 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	// ErrInvalidEvent is returned for events of unknown types or operations
+	ErrInvalidEvent = errors.New("invalid event")
+
+	// ErrUnmatchedEvent is returned for completions without an invocation and for repeated invocations
+	ErrUnmatchedEvent = errors.New("unmatched event")
+)
+
+// Operation is an operation of a history, from its invocation to its completion
+type Operation struct {
+	ID     uint64 `json:"id" yaml:"id"`
+	Op     string `json:"op" yaml:"op"`
+	Holder string `json:"holder,omitempty" yaml:"holder,omitempty"`
+	// Outcome is one of OK, Fail, and Info, or empty if the operation never completed
+	Outcome   string    `json:"outcome" yaml:"outcome"`
+	Invoked   time.Time `json:"invoked" yaml:"invoked"`
+	Completed time.Time `json:"completed" yaml:"completed"`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// invoke and complete are the positions of the events in the history, complete is math.MaxInt32 if the operation
+	// never completed
+	invoke   int
+	complete int
+}
+
+// determinate returns true if the operation is known to have taken effect. Operations that failed, timed out, or
+// never completed may or may not have taken effect, at any time after their invocation.
+func (o Operation) determinate() bool {
+	return o.Outcome == OK
+}
+
+// Operations pairs the invocations and completions of a history. Operations are ordered by invocation.
+func Operations(events []Event) ([]Operation, error) {
+	ops := []Operation{}
+	index := make(map[uint64]int)
+	for pos, e := range events {
+		if e.Op != Acquire && e.Op != Release {
+			return nil, fmt.Errorf("%w: event %v: unknown operation `%v`", ErrInvalidEvent, e.ID, e.Op)
+		}
+		switch e.Type {
+		case Invoke:
+			if _, ok := index[e.ID]; ok {
+				return nil, fmt.Errorf("%w: event %v: invoked twice", ErrUnmatchedEvent, e.ID)
+			}
+			index[e.ID] = len(ops)
+			ops = append(ops, Operation{
+				ID:       e.ID,
+				Op:       e.Op,
+				Holder:   e.Holder,
+				Invoked:  e.Time,
+				invoke:   pos,
+				complete: math.MaxInt32,
+			})
+		case OK, Fail, Info:
+			i, ok := index[e.ID]
+			if !ok || ops[i].Outcome != "" {
+				return nil, fmt.Errorf("%w: event %v: completed without invocation", ErrUnmatchedEvent, e.ID)
+			}
+			ops[i].Outcome = e.Type
+			ops[i].Completed = e.Time
+			ops[i].Error = e.Error
+			ops[i].complete = pos
+		default:
+			return nil, fmt.Errorf("%w: event %v: unknown type `%v`", ErrInvalidEvent, e.ID, e.Type)
+		}
+	}
+	return ops, nil
+}
+
+// Result is the result of checking a history
+type Result struct {
+	// Linearizable is true if the history is consistent with a lock that has at most one holder at a time
+	Linearizable bool
+	// Operations is the number of operations in the history
+	Operations int
+	// Violation is a minimal sub-history that is not linearizable, nil if the history is linearizable. It contains the
+	// acquires that conflict and every release that could have happened in between.
+	Violation []Operation
+}
+
+// Check checks whether a history is linearizable with respect to a lock. A lock starts free. Acquiring succeeds if the
+// lock is free or already held by the same holder. Releasing frees the lock, unless a holder is given that does not
+// hold the lock.
+func Check(events []Event) (*Result, error) {
+	ops, err := Operations(events)
+	if err != nil {
+		return nil, err
+	}
+	result := Result{
+		Linearizable: linearizable(ops),
+		Operations:   len(ops),
+	}
+	if !result.Linearizable {
+		result.Violation = minimize(ops)
+	}
+	return &result, nil
+}
+
+// apply applies an operation to the state of a lock, i.e. its holder. It returns false if the operation can not have
+// succeeded in that state.
+func apply(state string, o Operation) (string, bool) {
+	if state != "" && o.Holder != "" && state != o.Holder {
+		return state, false
+	}
+	if o.Op == Acquire {
+		return o.Holder, true
+	}
+	return "", true
+}
+
+// search is a depth-first search for a linearization of a history. Each operation that is known to have taken effect
+// is placed at a point between its invocation and its completion. Releases that may have taken effect are placed
+// wherever they help. Acquires that may have taken effect are left out entirely, they could only keep others from
+// acquiring the lock.
+type search struct {
+	ops      []Operation
+	done     []bool
+	required int
+	// hash identifies the set of linearized operations. It is the XOR of the random keys of all linearized operations,
+	// with 128 bits collisions are practically impossible.
+	hash    [2]uint64
+	keys    [][2]uint64
+	visited map[visit]bool
+}
+
+// visit is a combination of linearized operations and the resulting state that has been searched already
+type visit struct {
+	hash  [2]uint64
+	state string
+}
+
+// linearizable returns true if the operations, ordered by invocation, can be linearized
+func linearizable(ops []Operation) bool {
+	s := search{
+		visited: make(map[visit]bool),
+	}
+	for _, o := range ops {
+		if o.Op == Acquire && !o.determinate() {
+			continue
+		}
+		if o.determinate() {
+			s.required++
+		}
+		s.ops = append(s.ops, o)
+	}
+	s.done = make([]bool, len(s.ops))
+	rnd := rand.New(rand.NewSource(1))
+	for range s.ops {
+		s.keys = append(s.keys, [2]uint64{rnd.Uint64(), rnd.Uint64()})
+	}
+	return s.run("", 0, 0)
+}
+
+// run linearizes the remaining operations, starting with state. Operations before from have all been linearized.
+func (s *search) run(state string, from, linearized int) bool {
+	if linearized == s.required {
+		return true
+	}
+	v := visit{
+		hash:  s.hash,
+		state: state,
+	}
+	if s.visited[v] {
+		return false
+	}
+	s.visited[v] = true
+
+	for from < len(s.ops) && s.done[from] {
+		from++
+	}
+	// an operation can only go next if it was invoked before every remaining operation that must be linearized
+	// completed
+	deadline := math.MaxInt32
+	end := from
+	for ; end < len(s.ops) && s.ops[end].invoke < deadline; end++ {
+		if !s.done[end] && s.ops[end].determinate() && s.ops[end].complete < deadline {
+			deadline = s.ops[end].complete
+		}
+	}
+	var tried map[string]bool
+	for i := from; i < end; i++ {
+		o := s.ops[i]
+		if s.done[i] || o.invoke > deadline {
+			continue
+		}
+		if !o.determinate() {
+			// uncertain releases that are interchangeable are only tried once, and only if they change anything
+			if state == "" || tried[o.Holder] {
+				continue
+			}
+			if tried == nil {
+				tried = make(map[string]bool)
+			}
+			tried[o.Holder] = true
+		}
+		next, ok := apply(state, o)
+		if !ok {
+			continue
+		}
+		s.mark(i, true)
+		count := linearized
+		if o.determinate() {
+			count++
+		}
+		if s.run(next, from, count) {
+			return true
+		}
+		s.mark(i, false)
+	}
+	return false
+}
+
+// mark marks an operation as linearized or not
+func (s *search) mark(i int, done bool) {
+	s.done[i] = done
+	s.hash[0] ^= s.keys[i][0]
+	s.hash[1] ^= s.keys[i][1]
+}
+
+// prefix returns the operations invoked before the given position of the history. Operations that completed later
+// are treated as if they never completed.
+func prefix(ops []Operation, pos int) []Operation {
+	cut := []Operation{}
+	for _, o := range ops {
+		if o.invoke >= pos {
+			break
+		}
+		if o.complete >= pos {
+			o.Outcome = ""
+			o.complete = math.MaxInt32
+		}
+		cut = append(cut, o)
+	}
+	return cut
+}
+
+// minimize returns a minimal sub-history of a history that is not linearizable
+func minimize(ops []Operation) []Operation {
+	// find the shortest prefix that is not linearizable
+	low, high := 0, 0
+	for _, o := range ops {
+		if o.complete != math.MaxInt32 && o.complete >= high {
+			high = o.complete + 1
+		}
+	}
+	for low < high {
+		mid := (low + high) / 2
+		if linearizable(prefix(ops, mid)) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	cut := prefix(ops, high)
+
+	// leave out as many acquires as possible, in ever smaller chunks. Leaving out an acquire never introduces a
+	// violation, so what remains is still a violation of the original history.
+	acquires := []Operation{}
+	releases := []Operation{}
+	for _, o := range cut {
+		switch {
+		case o.Op == Release:
+			releases = append(releases, o)
+		case o.determinate():
+			acquires = append(acquires, o)
+		}
+	}
+	for chunk := len(acquires) / 2; chunk >= 1; chunk /= 2 {
+		for i := 0; i < len(acquires); {
+			j := i + chunk
+			if j > len(acquires) {
+				j = len(acquires)
+			}
+			kept := append(append([]Operation{}, acquires[:i]...), acquires[j:]...)
+			if !linearizable(merge(kept, window(kept, releases))) {
+				acquires = kept
+			} else {
+				i = j
+			}
+		}
+	}
+
+	// report the operations as they were recorded, unless a release that completed after the prefix makes a
+	// difference, which is unlikely
+	original := []Operation{}
+	for _, o := range ops {
+		if o.Op == Release {
+			original = append(original, o)
+		}
+	}
+	violation := merge(acquires, window(acquires, original))
+	if linearizable(violation) {
+		violation = merge(acquires, window(acquires, releases))
+	}
+	return violation
+}
+
+// window returns the releases that could make a difference to the acquires. Releases that completed before the first
+// acquire was invoked or were invoked after the last acquire completed can not.
+func window(acquires, releases []Operation) []Operation {
+	start, end := math.MaxInt32, 0
+	for _, o := range acquires {
+		if o.invoke < start {
+			start = o.invoke
+		}
+		if o.complete > end {
+			end = o.complete
+		}
+	}
+	kept := []Operation{}
+	for _, o := range releases {
+		if o.invoke < end && o.complete > start {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
+
+// merge merges two lists of operations ordered by invocation
+func merge(a, b []Operation) []Operation {
+	merged := make([]Operation, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		if len(b) == 0 || (len(a) > 0 && a[0].invoke < b[0].invoke) {
+			merged = append(merged, a[0])
+			a = a[1:]
+		} else {
+			merged = append(merged, b[0])
+			b = b[1:]
+		}
+	}
+	return merged
+}
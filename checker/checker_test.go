@@ -0,0 +1,353 @@
+package checker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* --- begin: test helper: history --- */
+
+// history parses events of the form "<id> <type> [<op> [<holder>]]", e.g. "1 invoke acquire beaver" and "1 ok"
+func history(t *testing.T, lines ...string) []Event {
+	t.Helper()
+	ops := make(map[uint64]Event)
+	events := []Event{}
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, line := range lines {
+		var e Event
+		fields := strings.Fields(line)
+		if _, err := fmt.Sscan(fields[0], &e.ID); err != nil || len(fields) < 2 {
+			t.Fatalf("invalid event `%v`", line)
+		}
+		e.Type = fields[1]
+		if e.Type == Invoke {
+			e.Op = fields[2]
+			if len(fields) > 3 {
+				e.Holder = fields[3]
+			}
+			ops[e.ID] = e
+		} else {
+			e.Op = ops[e.ID].Op
+			e.Holder = ops[e.ID].Holder
+		}
+		e.Time = start.Add(time.Duration(i) * time.Millisecond)
+		events = append(events, e)
+	}
+	return events
+}
+
+// ids returns the IDs of operations
+func ids(ops []Operation) []uint64 {
+	ids := []uint64{}
+	for _, o := range ops {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+/* --- end: test helper: history --- */
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	acquire := r.Invoke(Acquire, "beaver")
+	release := r.Invoke(Release, "")
+	r.Complete(acquire, OK, nil)
+	r.Complete(release, Info, errors.New("deadline exceeded"))
+	r.Complete(release, OK, nil)
+	if err := r.Err(); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	events, err := ReadHistory(&buf)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected `%v` events, got `%v`", 4, len(events))
+	}
+	want := []Event{
+		{ID: 1, Type: Invoke, Op: Acquire, Holder: "beaver"},
+		{ID: 2, Type: Invoke, Op: Release},
+		{ID: 1, Type: OK, Op: Acquire, Holder: "beaver"},
+		{ID: 2, Type: Info, Op: Release, Error: "deadline exceeded"},
+	}
+	for i, e := range events {
+		e.Time = time.Time{}
+		if e != want[i] {
+			t.Errorf("expected `%v`, got `%v`", want[i], e)
+		}
+	}
+
+	t.Run("invalid history", func(t *testing.T) {
+		_, err := ReadHistory(strings.NewReader("{\"id\":1}\nnot json\n"))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+			t.Errorf("expected error on line 2, got `%v`", err)
+		}
+	})
+}
+
+func TestOperations(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		events []Event
+		want   error
+	}{
+		{"unknown operation", history(t, "1 invoke steal beaver"), ErrInvalidEvent},
+		{"unknown type", history(t, "1 invoke acquire beaver", "1 maybe"), ErrInvalidEvent},
+		{"completed twice", history(t, "1 invoke acquire beaver", "1 ok", "1 ok"), ErrUnmatchedEvent},
+		{"invoked twice", history(t, "1 invoke acquire beaver", "1 invoke acquire beaver"), ErrUnmatchedEvent},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Operations(tc.events)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected `%v`, got `%v`", tc.want, err)
+			}
+		})
+	}
+
+	ops, err := Operations(history(t, "1 invoke acquire beaver", "2 invoke release", "2 fail", "1 ok"))
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if len(ops) != 2 || ops[0].Outcome != OK || ops[1].Outcome != Fail {
+		t.Errorf("expected acquire ok and release failed, got `%+v`", ops)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		events []Event
+		want   bool
+	}{
+		{
+			name:   "empty",
+			events: history(t),
+			want:   true,
+		},
+		{
+			name: "sequential",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke release", "2 ok",
+				"3 invoke acquire alien", "3 ok",
+			),
+			want: true,
+		},
+		{
+			name: "reentrant",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke acquire beaver", "2 ok",
+			),
+			want: true,
+		},
+		{
+			name: "two holders",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke acquire alien", "2 ok",
+			),
+			want: false,
+		},
+		{
+			name: "concurrent release",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke acquire alien",
+				"3 invoke release",
+				"2 ok",
+				"3 ok",
+			),
+			want: true,
+		},
+		{
+			name: "release too late",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke acquire alien", "2 ok",
+				"3 invoke release", "3 ok",
+			),
+			want: false,
+		},
+		{
+			name: "release may have taken effect",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke release", "2 info",
+				"3 invoke acquire alien", "3 ok",
+			),
+			want: true,
+		},
+		{
+			name: "release may take effect only once",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke release", "2 fail",
+				"3 invoke acquire alien", "3 ok",
+				"4 invoke acquire beaver", "4 ok",
+			),
+			want: false,
+		},
+		{
+			name: "failed acquire",
+			events: history(t,
+				"1 invoke acquire beaver", "1 fail",
+				"2 invoke acquire alien", "2 ok",
+			),
+			want: true,
+		},
+		{
+			name: "release of someone else's lock",
+			events: history(t,
+				"1 invoke acquire beaver", "1 ok",
+				"2 invoke release alien", "2 ok",
+			),
+			want: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Check(tc.events)
+			if err != nil {
+				t.Fatalf("expected `%v`, got `%v`", nil, err)
+			}
+			if result.Linearizable != tc.want {
+				t.Errorf("expected `%v`, got `%v`", tc.want, result.Linearizable)
+			}
+			if result.Linearizable != (result.Violation == nil) {
+				t.Errorf("expected violation only if not linearizable, got `%v`", ids(result.Violation))
+			}
+		})
+	}
+}
+
+func TestCheckViolation(t *testing.T) {
+	events := history(t,
+		"1 invoke acquire beaver", "1 ok",
+		"2 invoke release", "2 ok",
+		"3 invoke acquire alien", "3 ok",
+		"4 invoke acquire beaver", "4 fail",
+		"5 invoke release", "5 ok",
+		"6 invoke acquire beaver", "6 ok",
+		"7 invoke acquire alien",
+		"8 invoke release",
+		"7 ok",
+		"9 invoke acquire dolphin", "9 ok",
+		"8 ok",
+		"10 invoke release", "10 ok",
+	)
+	result, err := Check(events)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if result.Linearizable {
+		t.Fatalf("expected `%v`, got `%v`", false, result.Linearizable)
+	}
+	if result.Operations != 10 {
+		t.Errorf("expected `%v`, got `%v`", 10, result.Operations)
+	}
+	// beaver held the lock when alien and dolphin acquired it, a single release can not free it twice
+	want := fmt.Sprint([]uint64{6, 7, 8, 9})
+	if got := fmt.Sprint(ids(result.Violation)); got != want {
+		t.Errorf("expected `%v`, got `%v`", want, got)
+	}
+	for _, o := range result.Violation {
+		if o.Outcome != OK {
+			t.Errorf("expected operation %v to be reported as recorded, got `%v`", o.ID, o.Outcome)
+		}
+	}
+}
+
+func TestCheckLargeHistory(t *testing.T) {
+	// clients take turns acquiring and releasing, with plenty of contention and uncertainty
+	rnd := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	holder := ""
+	pending := []func(){}
+	for i := 0; i < 5000; i++ {
+		client := fmt.Sprintf("client-%v", rnd.Intn(5))
+		if holder == client {
+			id := r.Invoke(Release, "")
+			holder = ""
+			pending = append(pending, func() { r.Complete(id, OK, nil) })
+		} else {
+			id := r.Invoke(Acquire, client)
+			switch {
+			case holder == "":
+				holder = client
+				pending = append(pending, func() { r.Complete(id, OK, nil) })
+			case rnd.Intn(10) == 0:
+				pending = append(pending, func() { r.Complete(id, Info, nil) })
+			default:
+				pending = append(pending, func() { r.Complete(id, Fail, nil) })
+			}
+		}
+		// complete some operations late, they overlap with the following ones
+		for len(pending) > 0 && rnd.Intn(3) > 0 {
+			pending[0]()
+			pending = pending[1:]
+		}
+	}
+	for _, complete := range pending {
+		complete()
+	}
+	events, err := ReadHistory(&buf)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	result, err := Check(events)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if !result.Linearizable {
+		t.Fatalf("expected `%v`, got `%v`", true, result.Linearizable)
+	}
+
+	// grant the lock to a second holder in the middle of the history
+	for i := len(events) / 2; i < len(events); i++ {
+		e := events[i]
+		if e.Type == OK && e.Op == Acquire {
+			id := uint64(len(events))
+			events = append(events[:i+1], append([]Event{
+				{ID: id, Type: Invoke, Op: Acquire, Holder: "intruder"},
+				{ID: id, Type: OK, Op: Acquire, Holder: "intruder"},
+			}, events[i+1:]...)...)
+			break
+		}
+	}
+	result, err = Check(events)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if result.Linearizable {
+		t.Fatalf("expected `%v`, got `%v`", false, result.Linearizable)
+	}
+	intruder := false
+	for _, o := range result.Violation {
+		intruder = intruder || o.Holder == "intruder"
+	}
+	if !intruder {
+		t.Errorf("expected the intruder in the violation, got `%v`", ids(result.Violation))
+	}
+	if linearizable(result.Violation) {
+		t.Errorf("expected the violation not to be linearizable")
+	}
+	// the violation is minimal, every acquire is necessary
+	for i, o := range result.Violation {
+		if o.Op != Acquire {
+			continue
+		}
+		without := append(append([]Operation{}, result.Violation[:i]...), result.Violation[i+1:]...)
+		if !linearizable(without) {
+			t.Errorf("expected the violation without operation %v to be linearizable", o.ID)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package checker verifies recorded histories of lock operations. A history lists when clients invoked acquires and
+// releases and how they completed. The checker proves that the history is linearizable with respect to a lock, i.e.
+// that the lock was never granted to two holders at the same time.
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// operations
+const (
+	Acquire = "acquire"
+	Release = "release"
+)
+
+// event types
+const (
+	// Invoke is recorded when a client sends a request
+	Invoke = "invoke"
+	// OK is recorded when the request succeeded
+	OK = "ok"
+	// Fail is recorded when the quorum refused the request. The request may still take effect later, e.g. if a
+	// minority of instances committed it and a later proposal adopts it.
+	Fail = "fail"
+	// Info is recorded when the outcome is unknown, e.g. the request timed out
+	Info = "info"
+)
+
+// Event is the invocation or the completion of an operation. Events of the same operation share an ID.
+type Event struct {
+	ID     uint64    `json:"id"`
+	Type   string    `json:"type"`
+	Op     string    `json:"op"`
+	Holder string    `json:"holder,omitempty"`
+	Time   time.Time `json:"time"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Recorder writes a history as one JSON event per line. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	next    uint64
+	pending map[uint64]Event
+	err     error
+}
+
+// NewRecorder creates a recorder that writes to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		enc:     json.NewEncoder(w),
+		pending: make(map[uint64]Event),
+	}
+}
+
+// Invoke records the invocation of an operation and returns its ID
+func (r *Recorder) Invoke(op, holder string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	e := Event{
+		ID:     r.next,
+		Type:   Invoke,
+		Op:     op,
+		Holder: holder,
+		Time:   time.Now(),
+	}
+	r.pending[e.ID] = e
+	r.write(e)
+	return e.ID
+}
+
+// Complete records the completion of an operation. The type of the event is one of OK, Fail, and Info.
+func (r *Recorder) Complete(id uint64, typ string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.pending[id]
+	if !ok {
+		return
+	}
+	delete(r.pending, id)
+	e.Type = typ
+	e.Time = time.Now()
+	if err != nil {
+		e.Error = err.Error()
+	}
+	r.write(e)
+}
+
+// write writes an event, the first error sticks. It must be called with the lock held so that events are written in
+// the order they happened.
+func (r *Recorder) write(e Event) {
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(e)
+}
+
+// Err returns the first error that occurred while writing the history
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadHistory reads a history as written by a recorder
+func ReadHistory(r io.Reader) ([]Event, error) {
+	events := []Event{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,420 @@
+// Package client implements a client for a quorum of Skinny instances. It takes care of failing over to other
+// instances of the quorum, so that applications do not have to.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danrl/skinny/checker"
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPollInterval is the default interval in which a blocking Acquire retries
+const DefaultPollInterval = 500 * time.Millisecond
+
+// Strategy is the order in which the instances of a quorum are tried
+type Strategy int
+
+// Strategies
+const (
+	// Ordered tries instances in the order of the quorum configuration
+	Ordered Strategy = iota
+	// Random tries instances in a random order
+	Random
+	// LeaderFirst tries the instance first that promised and committed the highest ID. Skinny has no designated
+	// leader, but that instance's next proposal is the least likely to be rejected.
+	LeaderFirst
+)
+
+// Strategies maps the names of the strategies to the strategies
+var Strategies = map[string]Strategy{
+	"ordered":      Ordered,
+	"random":       Random,
+	"leader-first": LeaderFirst,
+}
+
+var (
+	// ErrNoInstances is returned when a client is created without any instances
+	ErrNoInstances = errors.New("no instances")
+
+	// ErrUnknownInstance is returned when an instance is not part of the quorum
+	ErrUnknownInstance = errors.New("unknown instance")
+
+	// ErrUnknownStrategy is returned when a strategy name is not known
+	ErrUnknownStrategy = errors.New("unknown strategy")
+
+	// ErrLockHeld is returned when the lock is held by someone else
+	ErrLockHeld = errors.New("lock held by someone else")
+
+	// ErrNotAcquired is returned when the quorum could not agree on a holder
+	ErrNotAcquired = errors.New("lock not acquired")
+
+	// ErrNotReleased is returned when the quorum could not agree on releasing the lock
+	ErrNotReleased = errors.New("lock not released")
+
+	// ErrLockLost is returned when a lock that was held got released by someone else
+	ErrLockLost = errors.New("lock lost")
+
+	// ErrNoMajority is returned when no majority of the instances agrees on who holds the lock
+	ErrNoMajority = errors.New("no majority agrees on the holder")
+)
+
+// ParseStrategy returns the strategy of the given name
+func ParseStrategy(name string) (Strategy, error) {
+	strategy, ok := Strategies[name]
+	if !ok {
+		return Ordered, ErrUnknownStrategy
+	}
+	return strategy, nil
+}
+
+// Result describes how a request has been served
+type Result struct {
+	// Instance is the instance that served the request
+	Instance config.Instance
+	// Holder is the holder of the lock as reported by the instance
+	Holder string
+	// Failures lists the instances that were tried before, one error per instance
+	Failures []error
+}
+
+// Client talks to a quorum of Skinny instances
+type Client struct {
+	mu           sync.Mutex
+	instances    []config.Instance
+	timeout      time.Duration
+	strategy     Strategy
+	preferred    string
+	pollInterval time.Duration
+	dialOptions  []grpc.DialOption
+	conns        map[string]*grpc.ClientConn
+	rand         *rand.Rand
+	recorder     *checker.Recorder
+}
+
+// New creates a client for the quorum described by the quorum configuration
+func New(quorum *config.QuorumConfig) (*Client, error) {
+	if len(quorum.Instances) == 0 {
+		return nil, ErrNoInstances
+	}
+	return &Client{
+		instances:    append([]config.Instance{}, quorum.Instances...),
+		timeout:      quorum.Timeout,
+		strategy:     Ordered,
+		pollInterval: DefaultPollInterval,
+		dialOptions:  []grpc.DialOption{grpc.WithInsecure()},
+		conns:        make(map[string]*grpc.ClientConn),
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// NewFromAddresses creates a client for the quorum of instances reachable at the given addresses. Instances are named
+// after their addresses.
+func NewFromAddresses(timeout time.Duration, addresses ...string) (*Client, error) {
+	quorum := config.QuorumConfig{
+		Timeout: timeout,
+	}
+	for _, address := range addresses {
+		quorum.Instances = append(quorum.Instances, config.Instance{
+			Name:    address,
+			Address: address,
+		})
+	}
+	return New(&quorum)
+}
+
+// SetStrategy sets the order in which instances are tried
+func (c *Client) SetStrategy(strategy Strategy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strategy = strategy
+}
+
+// SetPreferred sets an instance that is always tried first, regardless of the strategy. An empty name removes the
+// preference.
+func (c *Client) SetPreferred(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name != "" {
+		if _, ok := c.instance(name); !ok {
+			return ErrUnknownInstance
+		}
+	}
+	c.preferred = name
+	return nil
+}
+
+// SetPollInterval sets the interval in which a blocking Acquire retries
+func (c *Client) SetPollInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pollInterval = interval
+}
+
+// SetDialOptions replaces the options used to connect to instances. It must be called before the first request.
+func (c *Client) SetDialOptions(opts ...grpc.DialOption) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialOptions = opts
+}
+
+// SetRecorder sets a recorder that records every attempt to acquire or release the lock, e.g. to check the history
+// for linearizability later. A nil recorder stops recording.
+func (c *Client) SetRecorder(recorder *checker.Recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recorder = recorder
+}
+
+// Close closes all connections to the instances of the quorum
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for name, conn := range c.conns {
+		if e := conn.Close(); e != nil {
+			err = e
+		}
+		delete(c.conns, name)
+	}
+	return err
+}
+
+// TryAcquire tries to acquire the lock on behalf of holder once. It returns ErrLockHeld if the lock is held by someone
+// else.
+func (c *Client) TryAcquire(ctx context.Context, holder string) (*Result, error) {
+	complete := c.record(checker.Acquire, holder)
+	result, err := c.tryAcquire(ctx, holder)
+	complete(err)
+	return result, err
+}
+
+// tryAcquire tries to acquire the lock on behalf of holder once
+func (c *Client) tryAcquire(ctx context.Context, holder string) (*Result, error) {
+	var resp *lock.AcquireResponse
+	result, err := c.call(ctx, func(ctx context.Context, conn *grpc.ClientConn) error {
+		var err error
+		resp, err = lock.NewLockClient(conn).Acquire(ctx, &lock.AcquireRequest{Holder: holder})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Holder = resp.Holder
+	switch {
+	case resp.Acquired:
+		return result, nil
+	case resp.Holder != "":
+		return result, ErrLockHeld
+	default:
+		return result, ErrNotAcquired
+	}
+}
+
+// Acquire acquires the lock on behalf of holder. It blocks until the lock is acquired or the context is done. In the
+// latter case, the error of the last attempt is returned.
+func (c *Client) Acquire(ctx context.Context, holder string) (*Result, error) {
+	c.mu.Lock()
+	interval := c.pollInterval
+	c.mu.Unlock()
+
+	var result *Result
+	var err error
+	for {
+		r, e := c.TryAcquire(ctx, holder)
+		if e == nil {
+			return r, nil
+		}
+		if ctx.Err() != nil && err != nil {
+			// report why the lock could not be acquired rather than the interruption of the last attempt
+			return result, err
+		}
+		result, err = r, e
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return result, err
+		}
+	}
+}
+
+// Release releases the lock held by holder. It returns ErrNotReleased if the lock is held by someone else, the result
+// tells by whom. An empty holder releases the lock whoever holds it.
+func (c *Client) Release(ctx context.Context, holder string) (*Result, error) {
+	complete := c.record(checker.Release, holder)
+	result, err := c.release(ctx, holder)
+	complete(err)
+	return result, err
+}
+
+// release releases the lock held by holder
+func (c *Client) release(ctx context.Context, holder string) (*Result, error) {
+	var resp *lock.ReleaseResponse
+	result, err := c.call(ctx, func(ctx context.Context, conn *grpc.ClientConn) error {
+		var err error
+		resp, err = lock.NewLockClient(conn).Release(ctx, &lock.ReleaseRequest{Holder: holder})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Holder = resp.Holder
+	if !resp.Released {
+		return result, ErrNotReleased
+	}
+	return result, nil
+}
+
+// record records the invocation of an operation, if a recorder is set. The returned function records the completion.
+// Refused requests and errors are recorded as uncertain, since the quorum may still agree on them later.
+func (c *Client) record(op, holder string) func(err error) {
+	c.mu.Lock()
+	recorder := c.recorder
+	c.mu.Unlock()
+	if recorder == nil {
+		return func(error) {}
+	}
+	id := recorder.Invoke(op, holder)
+	return func(err error) {
+		switch err {
+		case nil:
+			recorder.Complete(id, checker.OK, nil)
+		case ErrLockHeld, ErrNotAcquired, ErrNotReleased:
+			recorder.Complete(id, checker.Fail, err)
+		default:
+			recorder.Complete(id, checker.Info, err)
+		}
+	}
+}
+
+// call calls fn on the instances of the quorum until one of them serves the call. Another instance is only tried if
+// the previous one is unavailable.
+func (c *Client) call(ctx context.Context, fn func(ctx context.Context, conn *grpc.ClientConn) error) (*Result, error) {
+	result := &Result{}
+	var err error
+	for _, in := range c.order(ctx) {
+		result.Instance = in
+		err = c.callInstance(ctx, in, fn)
+		if status.Code(err) != codes.Unavailable || ctx.Err() != nil {
+			return result, err
+		}
+		result.Failures = append(result.Failures, fmt.Errorf("%v: %w", in.Name, err))
+	}
+	return result, err
+}
+
+// callInstance calls fn on a single instance, bounded by the client's timeout
+func (c *Client) callInstance(ctx context.Context, in config.Instance,
+	fn func(ctx context.Context, conn *grpc.ClientConn) error) error {
+	conn, err := c.conn(in)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return fn(ctx, conn)
+}
+
+// conn returns the connection to an instance, connecting to it if necessary
+func (c *Client) conn(in config.Instance) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conn, ok := c.conns[in.Name]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(in.Address, c.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[in.Name] = conn
+	return conn, nil
+}
+
+// instance returns the instance of the given name. Caller must hold a lock on c (Client).
+func (c *Client) instance(name string) (config.Instance, bool) {
+	for _, in := range c.instances {
+		if in.Name == name {
+			return in, true
+		}
+	}
+	return config.Instance{}, false
+}
+
+// order returns the instances of the quorum in the order they are to be tried
+func (c *Client) order(ctx context.Context) []config.Instance {
+	c.mu.Lock()
+	instances := append([]config.Instance{}, c.instances...)
+	strategy := c.strategy
+	preferred := c.preferred
+	if strategy == Random {
+		c.rand.Shuffle(len(instances), func(i, j int) {
+			instances[i], instances[j] = instances[j], instances[i]
+		})
+	}
+	c.mu.Unlock()
+
+	if strategy == LeaderFirst {
+		instances = c.byLeadership(ctx, instances)
+	}
+
+	// a preferred instance always goes first
+	if preferred == "" {
+		return instances
+	}
+	order := []config.Instance{}
+	for _, in := range instances {
+		if in.Name == preferred {
+			order = append([]config.Instance{in}, order...)
+		} else {
+			order = append(order, in)
+		}
+	}
+	return order
+}
+
+// byLeadership sorts instances by the highest ID they promised, and among those by the highest ID they committed.
+// Unreachable and draining instances go last.
+func (c *Client) byLeadership(ctx context.Context, instances []config.Instance) []config.Instance {
+	type rank struct {
+		reachable bool
+		promised  uint64
+		id        uint64
+	}
+	ranks := make(map[string]rank)
+	for _, status := range c.Status(ctx) {
+		if status.Err != nil || status.Status.Draining {
+			continue
+		}
+		ranks[status.Instance.Name] = rank{
+			reachable: true,
+			promised:  status.Status.Promised,
+			id:        status.Status.ID,
+		}
+	}
+
+	sorted := append([]config.Instance{}, instances...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		ra, rb := ranks[sorted[a].Name], ranks[sorted[b].Name]
+		if ra.reachable != rb.reachable {
+			return ra.reachable
+		}
+		if ra.promised != rb.promised {
+			return ra.promised > rb.promised
+		}
+		return ra.id > rb.id
+	})
+	return sorted
+}
@@ -0,0 +1,360 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/checker"
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+	"github.com/danrl/skinny/skinny"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+/* --- begin: test helper: quorum ----------------------------------------------------------------------------------- */
+
+type testQuorum struct {
+	t         *testing.T
+	config    *config.QuorumConfig
+	listeners map[string]*bufconn.Listener
+	servers   map[string]*grpc.Server
+	instances map[string]*skinny.Instance
+}
+
+func newTestQuorum(t *testing.T, size int) *testQuorum {
+	q := testQuorum{
+		t:         t,
+		config:    &config.QuorumConfig{Timeout: time.Second},
+		listeners: make(map[string]*bufconn.Listener),
+		servers:   make(map[string]*grpc.Server),
+		instances: make(map[string]*skinny.Instance),
+	}
+
+	for i := 1; i <= size; i++ {
+		name := fmt.Sprintf("instance-%v", i)
+		q.config.Instances = append(q.config.Instances, config.Instance{Name: name, Address: name})
+
+		in := skinny.New(name, uint64(i), 200*time.Millisecond)
+		in.SetLogLevel(skinny.LogQuiet)
+		server := grpc.NewServer()
+		consensus.RegisterConsensusServer(server, in)
+		control.RegisterControlServer(server, in)
+		lock.RegisterLockServer(server, in)
+		listener := bufconn.Listen(1024 * 1024)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+
+		q.instances[name] = in
+		q.servers[name] = server
+		q.listeners[name] = listener
+	}
+
+	for _, in := range q.config.Instances {
+		for _, peer := range q.config.Instances {
+			if in.Name == peer.Name {
+				continue
+			}
+			conn, err := grpc.Dial(peer.Address, q.dialOptions()...)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			err = q.instances[in.Name].AddPeer(peer.Name, skinny.NewGRPCTransport(conn))
+			if err != nil {
+				t.Fatalf("add peer: %v", err)
+			}
+		}
+	}
+	return &q
+}
+
+func (q *testQuorum) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return q.listeners[address].Dial()
+		}),
+	}
+}
+
+func (q *testQuorum) client() *Client {
+	c, err := New(q.config)
+	if err != nil {
+		q.t.Fatalf("new client: %v", err)
+	}
+	c.SetDialOptions(q.dialOptions()...)
+	c.SetPollInterval(10 * time.Millisecond)
+	return c
+}
+
+func (q *testQuorum) stop(name string) {
+	q.servers[name].Stop()
+}
+
+func (q *testQuorum) destroy() {
+	for _, server := range q.servers {
+		server.Stop()
+	}
+}
+
+func (q *testQuorum) holder(name string) string {
+	resp, err := q.instances[name].Status(context.Background(), &control.StatusRequest{})
+	if err != nil {
+		q.t.Fatalf("status: %v", err)
+	}
+	return resp.Holder
+}
+
+/* --- end: test helper: quorum ------------------------------------------------------------------------------------- */
+
+func TestNew(t *testing.T) {
+	t.Run("no instances", func(t *testing.T) {
+		_, err := New(&config.QuorumConfig{})
+		if err != ErrNoInstances {
+			t.Errorf("expected `%v`, got `%v`", ErrNoInstances, err)
+		}
+	})
+
+	t.Run("addresses", func(t *testing.T) {
+		c, err := NewFromAddresses(time.Second, "localhost:9000", "localhost:9001")
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(c.instances) != 2 {
+			t.Errorf("expected `%v`, got `%v`", 2, len(c.instances))
+		}
+		if c.instances[1].Name != "localhost:9001" {
+			t.Errorf("expected `%v`, got `%v`", "localhost:9001", c.instances[1].Name)
+		}
+		if c.timeout != time.Second {
+			t.Errorf("expected `%v`, got `%v`", time.Second, c.timeout)
+		}
+	})
+}
+
+func TestParseStrategy(t *testing.T) {
+	for name, expected := range Strategies {
+		got, err := ParseStrategy(name)
+		if err != nil || got != expected {
+			t.Errorf("%v: expected `%v`, got `%v` (%v)", name, expected, got, err)
+		}
+	}
+	_, err := ParseStrategy("round-robin")
+	if err != ErrUnknownStrategy {
+		t.Errorf("expected `%v`, got `%v`", ErrUnknownStrategy, err)
+	}
+}
+
+func TestClientSetPreferred(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	err := c.SetPreferred("instance-4")
+	if err != ErrUnknownInstance {
+		t.Errorf("expected `%v`, got `%v`", ErrUnknownInstance, err)
+	}
+	err = c.SetPreferred("instance-3")
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	order := c.order(context.Background())
+	if order[0].Name != "instance-3" || order[1].Name != "instance-1" || order[2].Name != "instance-2" {
+		t.Errorf("expected `%v`, got `%v`", "instance-3, instance-1, instance-2", order)
+	}
+}
+
+func TestClientAcquireRelease(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	t.Run("try acquire", func(t *testing.T) {
+		result, err := c.TryAcquire(context.Background(), "beaver")
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if result.Instance.Name != "instance-1" {
+			t.Errorf("expected `%v`, got `%v`", "instance-1", result.Instance.Name)
+		}
+		if result.Holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", result.Holder)
+		}
+	})
+
+	t.Run("lock held", func(t *testing.T) {
+		result, err := c.TryAcquire(context.Background(), "hamster")
+		if err != ErrLockHeld {
+			t.Errorf("expected `%v`, got `%v`", ErrLockHeld, err)
+		}
+		if result.Holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", result.Holder)
+		}
+	})
+
+	t.Run("acquire times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.Acquire(ctx, "hamster")
+		if err != ErrLockHeld {
+			t.Errorf("expected `%v`, got `%v`", ErrLockHeld, err)
+		}
+	})
+
+	t.Run("release held by someone else", func(t *testing.T) {
+		result, err := c.Release(context.Background(), "hamster")
+		if err != ErrNotReleased {
+			t.Errorf("expected `%v`, got `%v`", ErrNotReleased, err)
+		}
+		if result.Holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", result.Holder)
+		}
+		for name := range q.instances {
+			if holder := q.holder(name); holder != "beaver" {
+				t.Errorf("%v: expected `%v`, got `%v`", name, "beaver", holder)
+			}
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		_, err := c.Release(context.Background(), "beaver")
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		for name := range q.instances {
+			if holder := q.holder(name); holder != "" {
+				t.Errorf("%v: expected `%v`, got `%v`", name, "", holder)
+			}
+		}
+	})
+
+	t.Run("acquire waits", func(t *testing.T) {
+		_, err := c.TryAcquire(context.Background(), "beaver")
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = c.Release(context.Background(), "beaver")
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		result, err := c.Acquire(ctx, "hamster")
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if result.Holder != "hamster" {
+			t.Errorf("expected `%v`, got `%v`", "hamster", result.Holder)
+		}
+	})
+}
+
+func TestClientFailover(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	q.stop("instance-1")
+
+	for name, strategy := range Strategies {
+		strategy := strategy
+		t.Run(name, func(t *testing.T) {
+			c := q.client()
+			defer c.Close()
+			c.SetStrategy(strategy)
+			if strategy == Ordered {
+				// make sure the unavailable instance is tried
+				_ = c.SetPreferred("instance-1")
+			}
+
+			result, err := c.TryAcquire(context.Background(), "beaver")
+			if err != nil {
+				t.Fatalf("expected `%v`, got `%v`", nil, err)
+			}
+			if result.Instance.Name == "instance-1" {
+				t.Errorf("expected other instance than `%v`, got `%v`", "instance-1", result.Instance.Name)
+			}
+			if strategy == Ordered && len(result.Failures) != 1 {
+				t.Errorf("expected `%v`, got `%v`", 1, len(result.Failures))
+			}
+			if strategy == LeaderFirst && len(result.Failures) != 0 {
+				t.Errorf("expected `%v`, got `%v`", 0, len(result.Failures))
+			}
+			if _, err := c.Release(context.Background(), "beaver"); err != nil {
+				t.Errorf("expected `%v`, got `%v`", nil, err)
+			}
+		})
+	}
+
+	t.Run("all unavailable", func(t *testing.T) {
+		q.stop("instance-2")
+		q.stop("instance-3")
+		c := q.client()
+		defer c.Close()
+		result, err := c.TryAcquire(context.Background(), "beaver")
+		if err == nil {
+			t.Errorf("expected error, got `%v`", err)
+		}
+		if len(result.Failures) != 3 {
+			t.Errorf("expected `%v`, got `%v`", 3, len(result.Failures))
+		}
+		if errors.Is(err, ErrLockHeld) {
+			t.Errorf("expected connection error, got `%v`", err)
+		}
+	})
+}
+
+func TestClientRecorder(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+
+	var buf bytes.Buffer
+	recorder := checker.NewRecorder(&buf)
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		c := q.client()
+		defer c.Close()
+		c.SetRecorder(recorder)
+		if err := c.SetPreferred(fmt.Sprintf("instance-%v", i)); err != nil {
+			t.Fatalf("set preferred: %v", err)
+		}
+		holder := fmt.Sprintf("client-%v", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := c.TryAcquire(context.Background(), holder); err == nil {
+					_, _ = c.Release(context.Background(), holder)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := recorder.Err(); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	events, err := checker.ReadHistory(&buf)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	result, err := checker.Check(events)
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if result.Operations < 30 {
+		t.Errorf("expected at least `%v` operations, got `%v`", 30, result.Operations)
+	}
+	if !result.Linearizable {
+		t.Errorf("expected `%v`, got `%v`", true, result.Linearizable)
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Lease watches a held lock in the background. Skinny locks do not expire, so there is nothing to keep alive. Instead,
+// renewing a lease asks the instances of the quorum who holds the lock, without ever acquiring it. The lock is lost
+// once a majority of the instances agrees that someone else holds it, or that nobody does, e.g. because someone
+// released it.
+type Lease struct {
+	client   *Client
+	holder   string
+	interval time.Duration
+	notify   func(*Result, error)
+
+	mu     sync.Mutex
+	heldBy string
+
+	lost     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Renew starts checking in the given interval that holder still holds the lock, until the lease is stopped or the lock
+// is lost. If notify is not nil, it is called with the outcome of every check. A lost lock is reported as ErrLockHeld
+// if someone else holds it, or as ErrLockLost if nobody does.
+func (c *Client) Renew(holder string, interval time.Duration, notify func(*Result, error)) *Lease {
+	l := &Lease{
+		client:   c,
+		holder:   holder,
+		interval: interval,
+		notify:   notify,
+		heldBy:   holder,
+		lost:     make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// run checks the lock until the lease is stopped or the lock is lost
+func (l *Lease) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		result, err := l.check()
+		if l.notify != nil {
+			l.notify(result, err)
+		}
+		if errors.Is(err, ErrLockHeld) || errors.Is(err, ErrLockLost) {
+			l.mu.Lock()
+			l.heldBy = result.Holder
+			l.mu.Unlock()
+			close(l.lost)
+			return
+		}
+	}
+}
+
+// check asks the instances who holds the lock. The holder is known if a majority of the instances agrees on it.
+func (l *Lease) check() (*Result, error) {
+	statuses := l.client.Status(context.Background())
+	result := &Result{}
+	votes := make(map[string]int)
+	for _, s := range statuses {
+		if s.Err != nil {
+			result.Failures = append(result.Failures, fmt.Errorf("%v: %w", s.Instance.Name, s.Err))
+			continue
+		}
+		votes[s.Status.Holder]++
+	}
+	for holder, n := range votes {
+		if n <= len(statuses)/2 {
+			continue
+		}
+		result.Holder = holder
+		switch holder {
+		case l.holder:
+			return result, nil
+		case "":
+			return result, ErrLockLost
+		default:
+			return result, ErrLockHeld
+		}
+	}
+	return result, ErrNoMajority
+}
+
+// Lost returns a channel that is closed when the lock got lost
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Holder returns the holder of the lock as last seen by the lease, empty if the lock got lost and nobody holds it
+func (l *Lease) Holder() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.heldBy
+}
+
+// Stop stops checking the lock. It does not release the lock.
+func (l *Lease) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc"
+)
+
+// Link is the connection from one instance of the quorum to one of its peers
+type Link struct {
+	From string
+	To   string
+	// Probed is false if From could not be asked to probe its peers. Nothing is known about the link then.
+	Probed    bool
+	Reachable bool
+	RTT       time.Duration
+	Err       error
+}
+
+// Mesh is the connectivity between every pair of instances of a quorum
+type Mesh struct {
+	// Instances are the names of all instances in the order of the quorum configuration
+	Instances []string
+	// Links are ordered by the instance they start from, then by the order of its peers
+	Links []Link
+}
+
+// Mesh asks every instance of the quorum concurrently to probe its peers and assembles the results
+func (c *Client) Mesh(ctx context.Context) *Mesh {
+	c.mu.Lock()
+	instances := c.instances
+	c.mu.Unlock()
+
+	mesh := Mesh{
+		Instances: []string{},
+		Links:     []Link{},
+	}
+	links := make([][]Link, len(instances))
+	wg := sync.WaitGroup{}
+	for i, in := range instances {
+		mesh.Instances = append(mesh.Instances, in.Name)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var resp *control.ProbePeersResponse
+			err := c.callInstance(ctx, instances[i], func(ctx context.Context, conn *grpc.ClientConn) error {
+				var err error
+				resp, err = control.NewControlClient(conn).ProbePeers(ctx, &control.ProbePeersRequest{})
+				return err
+			})
+			links[i] = probeLinks(instances[i].Name, instances, resp, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, l := range links {
+		mesh.Links = append(mesh.Links, l...)
+	}
+	return &mesh
+}
+
+// probeLinks converts the probes of an instance into links. If the instance could not be probed, its links to all
+// other instances are reported as not probed.
+func probeLinks(from string, instances []config.Instance, resp *control.ProbePeersResponse, err error) []Link {
+	links := []Link{}
+	if err != nil {
+		for _, in := range instances {
+			if in.Name != from {
+				links = append(links, Link{From: from, To: in.Name, Err: err})
+			}
+		}
+		return links
+	}
+	for _, probe := range resp.Probes {
+		link := Link{
+			From:      from,
+			To:        probe.Name,
+			Probed:    true,
+			Reachable: probe.Reachable,
+		}
+		if probe.Reachable {
+			link.RTT, _ = time.ParseDuration(probe.RTT)
+		} else {
+			link.Err = errors.New(probe.Error)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// Link returns the link from one instance to another
+func (m *Mesh) Link(from, to string) (Link, bool) {
+	for _, link := range m.Links {
+		if link.From == from && link.To == to {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// Up returns the number of links that are reachable
+func (m *Mesh) Up() int {
+	up := 0
+	for _, link := range m.Links {
+		if link.Reachable {
+			up++
+		}
+	}
+	return up
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientMesh(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	t.Run("healthy quorum", func(t *testing.T) {
+		mesh := c.Mesh(context.Background())
+		if len(mesh.Instances) != 3 || mesh.Instances[0] != "instance-1" {
+			t.Errorf("expected instances in configuration order, got `%v`", mesh.Instances)
+		}
+		if len(mesh.Links) != 6 {
+			t.Fatalf("expected `%v` links, got `%v`", 6, len(mesh.Links))
+		}
+		if mesh.Up() != 6 {
+			t.Errorf("expected `%v` links up, got `%v`", 6, mesh.Up())
+		}
+		link, ok := mesh.Link("instance-1", "instance-3")
+		if !ok {
+			t.Fatalf("expected link from instance-1 to instance-3")
+		}
+		if !link.Probed || !link.Reachable || link.RTT <= 0 || link.Err != nil {
+			t.Errorf("expected reachable link, got `%+v`", link)
+		}
+		if _, ok := mesh.Link("instance-1", "instance-1"); ok {
+			t.Errorf("expected no link from an instance to itself")
+		}
+	})
+
+	t.Run("stopped instance", func(t *testing.T) {
+		q.stop("instance-3")
+		mesh := c.Mesh(context.Background())
+		if len(mesh.Links) != 6 {
+			t.Fatalf("expected `%v` links, got `%v`", 6, len(mesh.Links))
+		}
+		if mesh.Up() != 2 {
+			t.Errorf("expected `%v` links up, got `%v`", 2, mesh.Up())
+		}
+
+		link, _ := mesh.Link("instance-1", "instance-3")
+		if !link.Probed || link.Reachable || link.Err == nil {
+			t.Errorf("expected unreachable link, got `%+v`", link)
+		}
+		link, _ = mesh.Link("instance-3", "instance-1")
+		if link.Probed || link.Reachable || link.Err == nil {
+			t.Errorf("expected link that was not probed, got `%+v`", link)
+		}
+	})
+}
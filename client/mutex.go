@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultUnlockTimeout bounds Unlock for clients without a timeout
+const defaultUnlockTimeout = 10 * time.Second
+
+// Mutex is a distributed mutual exclusion lock held on behalf of a holder. It implements sync.Locker. The quorum does
+// not tell apart goroutines that share a holder, so the mutex also excludes them from each other locally.
+type Mutex struct {
+	client *Client
+	holder string
+	// local is held from Lock to Unlock
+	local chan struct{}
+
+	mu    sync.Mutex
+	renew time.Duration
+	lease *Lease
+}
+
+// NewMutex returns a mutex that acquires the lock on behalf of holder
+func (c *Client) NewMutex(holder string) *Mutex {
+	return &Mutex{
+		client: c,
+		holder: holder,
+		local:  make(chan struct{}, 1),
+	}
+}
+
+// SetRenewal enables checking in the given interval that the lock is still held, see Lease. Zero disables renewal.
+func (m *Mutex) SetRenewal(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renew = interval
+}
+
+// LockContext acquires the lock. It blocks until the lock is acquired or the context is done.
+func (m *Mutex) LockContext(ctx context.Context) error {
+	select {
+	case m.local <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	_, err := m.client.Acquire(ctx, m.holder)
+	if err != nil {
+		<-m.local
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.renew > 0 {
+		m.lease = m.client.Renew(m.holder, m.renew, nil)
+	}
+	return nil
+}
+
+// UnlockContext releases the lock. It returns ErrNotReleased if the lock is held by someone else. Other goroutines can
+// lock the mutex again once it returns, even if it failed.
+func (m *Mutex) UnlockContext(ctx context.Context) error {
+	_, err := m.unlock(ctx)
+	m.unlockLocal()
+	return err
+}
+
+// unlock releases the lock, if it is still held by the holder of the mutex
+func (m *Mutex) unlock(ctx context.Context) (*Result, error) {
+	m.mu.Lock()
+	if m.lease != nil {
+		m.lease.Stop()
+		m.lease = nil
+	}
+	m.mu.Unlock()
+
+	return m.client.Release(ctx, m.holder)
+}
+
+// unlockLocal lets the next goroutine lock the mutex
+func (m *Mutex) unlockLocal() {
+	select {
+	case <-m.local:
+	default:
+	}
+}
+
+// Lost returns a channel that is closed when a renewal finds that the lock got lost. It returns nil if the lock is not
+// being renewed.
+func (m *Mutex) Lost() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lease == nil {
+		return nil
+	}
+	return m.lease.Lost()
+}
+
+// Lock acquires the lock. It blocks until the lock is acquired.
+func (m *Mutex) Lock() {
+	_ = m.LockContext(context.Background())
+}
+
+// Unlock releases the lock. It retries in the client's poll interval until the lock is released or held by someone
+// else, for at most the client's timeout. If it gives up, it logs why and the lock may still be held.
+func (m *Mutex) Unlock() {
+	defer m.unlockLocal()
+
+	m.client.mu.Lock()
+	interval := m.client.pollInterval
+	timeout := m.client.timeout
+	m.client.mu.Unlock()
+	if timeout <= 0 {
+		timeout = defaultUnlockTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		result, err := m.unlock(ctx)
+		if err == nil || (err == ErrNotReleased && result.Holder != "" && result.Holder != m.holder) {
+			return
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			log.Printf("skinny: giving up to unlock on behalf of %v after %v: %v", m.holder, timeout, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMutex(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	t.Run("locker", func(t *testing.T) {
+		var locker sync.Locker = c.NewMutex("beaver")
+		locker.Lock()
+		if holder := q.holder("instance-2"); holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", holder)
+		}
+		locker.Unlock()
+		if holder := q.holder("instance-2"); holder != "" {
+			t.Errorf("expected `%v`, got `%v`", "", holder)
+		}
+	})
+
+	t.Run("shared by goroutines", func(t *testing.T) {
+		m := c.NewMutex("beaver")
+		var holding int32
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 5; j++ {
+					m.Lock()
+					if n := atomic.AddInt32(&holding, 1); n != 1 {
+						t.Errorf("expected `%v` goroutine to hold the lock, got `%v`", 1, n)
+					}
+					time.Sleep(5 * time.Millisecond)
+					atomic.AddInt32(&holding, -1)
+					m.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if holder := q.holder("instance-2"); holder != "" {
+			t.Errorf("expected `%v`, got `%v`", "", holder)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		m := c.NewMutex("beaver")
+		if err := m.LockContext(context.Background()); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := c.NewMutex("hamster").LockContext(ctx); err != ErrLockHeld {
+			t.Errorf("expected `%v`, got `%v`", ErrLockHeld, err)
+		}
+		if err := m.UnlockContext(context.Background()); err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+	})
+
+	t.Run("renewal", func(t *testing.T) {
+		m := c.NewMutex("beaver")
+		if m.Lost() != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, m.Lost())
+		}
+		m.SetRenewal(10 * time.Millisecond)
+		m.Lock()
+
+		// someone else takes over the lock
+		if _, err := c.Release(context.Background(), ""); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if _, err := c.TryAcquire(context.Background(), "hamster"); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		select {
+		case <-m.Lost():
+		case <-time.After(5 * time.Second):
+			t.Errorf("expected lock to be lost")
+		}
+		if holder := m.lease.Holder(); holder != "hamster" {
+			t.Errorf("expected `%v`, got `%v`", "hamster", holder)
+		}
+		m.Unlock()
+		if holder := q.holder("instance-2"); holder != "hamster" {
+			t.Errorf("expected `%v`, got `%v`", "hamster", holder)
+		}
+		if _, err := c.Release(context.Background(), "hamster"); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+	})
+
+	t.Run("taken over before unlock", func(t *testing.T) {
+		m := c.NewMutex("beaver")
+		if err := m.LockContext(context.Background()); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+
+		// someone else takes over the lock
+		if _, err := c.Release(context.Background(), ""); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if _, err := c.TryAcquire(context.Background(), "hamster"); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+
+		if err := m.UnlockContext(context.Background()); err != ErrNotReleased {
+			t.Errorf("expected `%v`, got `%v`", ErrNotReleased, err)
+		}
+		m.Unlock()
+		for name := range q.instances {
+			if holder := q.holder(name); holder != "hamster" {
+				t.Errorf("%v: expected `%v`, got `%v`", name, "hamster", holder)
+			}
+		}
+	})
+}
+
+func TestMutexUnlockGivesUp(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	m := c.NewMutex("beaver")
+	m.Lock()
+	for name := range q.instances {
+		q.stop(name)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	unlocked := make(chan struct{})
+	go func() {
+		m.Unlock()
+		close(unlocked)
+	}()
+	select {
+	case <-unlocked:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected Unlock to give up")
+	}
+	if !strings.Contains(buf.String(), "giving up to unlock on behalf of beaver") {
+		t.Errorf("expected Unlock to log that it gave up, got `%v`", buf.String())
+	}
+}
+
+func TestLease(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	if _, err := c.TryAcquire(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	renewals := make(chan error, 100)
+	lease := c.Renew("beaver", 10*time.Millisecond, func(result *Result, err error) {
+		renewals <- err
+	})
+	for i := 0; i < 3; i++ {
+		if err := <-renewals; err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+	}
+	lease.Stop()
+	lease.Stop()
+
+	select {
+	case <-lease.Lost():
+		t.Errorf("expected lock not to be lost")
+	default:
+	}
+	if holder := lease.Holder(); holder != "beaver" {
+		t.Errorf("expected `%v`, got `%v`", "beaver", holder)
+	}
+}
+
+func TestLeaseReleased(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	if _, err := c.TryAcquire(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	renewals := make(chan error, 100)
+	lease := c.Renew("beaver", 10*time.Millisecond, func(result *Result, err error) {
+		renewals <- err
+	})
+	defer lease.Stop()
+
+	// an operator releases the lock
+	if _, err := c.Release(context.Background(), ""); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	select {
+	case <-lease.Lost():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected lock to be lost")
+	}
+	var err error
+	for err = range renewals {
+		if err != nil {
+			break
+		}
+	}
+	if err != ErrLockLost {
+		t.Errorf("expected `%v`, got `%v`", ErrLockLost, err)
+	}
+	if holder := lease.Holder(); holder != "" {
+		t.Errorf("expected `%v`, got `%v`", "", holder)
+	}
+	// the lease must not acquire the released lock again
+	for name := range q.instances {
+		if holder := q.holder(name); holder != "" {
+			t.Errorf("%v: expected `%v`, got `%v`", name, "", holder)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc"
+)
+
+// InstanceStatus is the status of a single instance of the quorum
+type InstanceStatus struct {
+	Instance config.Instance
+	// Status is nil if the instance could not be reached
+	Status *control.StatusResponse
+	Err    error
+}
+
+// Status fetches the status of every instance of the quorum concurrently. Statuses are returned in the order of the
+// quorum configuration.
+func (c *Client) Status(ctx context.Context) []InstanceStatus {
+	c.mu.Lock()
+	statuses := make([]InstanceStatus, len(c.instances))
+	for i, in := range c.instances {
+		statuses[i].Instance = in
+	}
+	c.mu.Unlock()
+
+	wg := sync.WaitGroup{}
+	for i := range statuses {
+		wg.Add(1)
+		go func(status *InstanceStatus) {
+			defer wg.Done()
+			status.Err = c.callInstance(ctx, status.Instance, func(ctx context.Context, conn *grpc.ClientConn) error {
+				var err error
+				status.Status, err = control.NewControlClient(conn).Status(ctx, &control.StatusRequest{})
+				return err
+			})
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	return statuses
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc"
+)
+
+// Message is a consensus request an instance sent to a peer while proposing, together with the peer's answer
+type Message struct {
+	// Phase is either promise or commit
+	Phase string
+	From  string
+	To    string
+	ID    uint64
+	// Holder is the holder to commit, empty for promise requests
+	Holder string
+	// AttachedID and AttachedHolder are the previously committed values a peer attached to its promise
+	AttachedID     uint64
+	AttachedHolder string
+	// Outcome is one of yea, nay, failed, or canceled
+	Outcome string
+	Err     error
+	Sent    time.Time
+	RTT     time.Duration
+}
+
+// Round is a proposal of an instance: the promise requests for an ID and the commit requests that followed
+type Round struct {
+	Proposer string
+	ID       uint64
+	// Messages are ordered by the time they were sent
+	Messages []Message
+}
+
+// Trace is the recent consensus messages of a quorum, grouped into rounds
+type Trace struct {
+	// Instances are the names of all instances in the order of the quorum configuration
+	Instances []string
+	// Rounds are ordered by the time their first message was sent
+	Rounds []Round
+	// Errs are the errors of instances that could not be asked for their messages
+	Errs map[string]error
+}
+
+// Trace asks every instance of the quorum concurrently for the messages it recently sent while proposing. Instances
+// remember a limited number of messages only, and send times come from the clocks of the proposers.
+func (c *Client) Trace(ctx context.Context) *Trace {
+	c.mu.Lock()
+	instances := c.instances
+	c.mu.Unlock()
+
+	trace := Trace{
+		Instances: []string{},
+		Rounds:    []Round{},
+		Errs:      make(map[string]error),
+	}
+	responses := make([]*control.TraceResponse, len(instances))
+	errs := make([]error, len(instances))
+	wg := sync.WaitGroup{}
+	for i, in := range instances {
+		trace.Instances = append(trace.Instances, in.Name)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.callInstance(ctx, instances[i], func(ctx context.Context, conn *grpc.ClientConn) error {
+				var err error
+				responses[i], err = control.NewControlClient(conn).Trace(ctx, &control.TraceRequest{})
+				return err
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	messages := []Message{}
+	for i, resp := range responses {
+		if errs[i] != nil {
+			trace.Errs[instances[i].Name] = errs[i]
+			continue
+		}
+		for _, m := range resp.Messages {
+			messages = append(messages, newMessage(m))
+		}
+	}
+	trace.Rounds = rounds(messages)
+	return &trace
+}
+
+// newMessage converts a message of a trace response
+func newMessage(m *control.Message) Message {
+	msg := Message{
+		Phase:          m.Phase,
+		From:           m.From,
+		To:             m.To,
+		ID:             m.ID,
+		Holder:         m.Holder,
+		AttachedID:     m.AttachedID,
+		AttachedHolder: m.AttachedHolder,
+		Outcome:        m.Outcome,
+	}
+	if m.Error != "" {
+		msg.Err = errors.New(m.Error)
+	}
+	msg.Sent, _ = time.Parse(time.RFC3339Nano, m.Sent)
+	msg.RTT, _ = time.ParseDuration(m.RTT)
+	return msg
+}
+
+// rounds groups messages by proposer and ID
+func rounds(messages []Message) []Round {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Sent.Before(messages[j].Sent)
+	})
+
+	type key struct {
+		proposer string
+		id       uint64
+	}
+	index := make(map[key]int)
+	rounds := []Round{}
+	for _, m := range messages {
+		k := key{proposer: m.From, id: m.ID}
+		i, ok := index[k]
+		if !ok {
+			i = len(rounds)
+			index[k] = i
+			rounds = append(rounds, Round{Proposer: m.From, ID: m.ID})
+		}
+		rounds[i].Messages = append(rounds[i].Messages, m)
+	}
+	return rounds
+}
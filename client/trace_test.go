@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientTrace(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	if _, err := c.TryAcquire(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if _, err := c.Release(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	t.Run("rounds", func(t *testing.T) {
+		trace := c.Trace(context.Background())
+		if len(trace.Instances) != 3 || len(trace.Errs) != 0 {
+			t.Fatalf("expected all instances to answer, got `%v`", trace.Errs)
+		}
+		if len(trace.Rounds) != 2 {
+			t.Fatalf("expected `%v` rounds, got `%v`", 2, len(trace.Rounds))
+		}
+		acquire, release := trace.Rounds[0], trace.Rounds[1]
+		if acquire.ID >= release.ID {
+			t.Errorf("expected rounds in order, got IDs `%v` and `%v`", acquire.ID, release.ID)
+		}
+		phases := map[string]int{}
+		for _, m := range acquire.Messages {
+			if m.From != acquire.Proposer || m.ID != acquire.ID {
+				t.Errorf("expected messages of the round only, got `%+v`", m)
+			}
+			if m.Sent.IsZero() {
+				t.Errorf("expected send time, got `%+v`", m)
+			}
+			phases[m.Phase]++
+			if m.Phase == "commit" && m.Holder != "beaver" {
+				t.Errorf("expected commit of holder `%v`, got `%+v`", "beaver", m)
+			}
+		}
+		if phases["promise"] != 2 || phases["commit"] != 2 {
+			t.Errorf("expected two promise and two commit requests, got `%v`", phases)
+		}
+		if acquire.Messages[0].Phase != "promise" {
+			t.Errorf("expected promise requests first, got `%+v`", acquire.Messages[0])
+		}
+	})
+
+	t.Run("stopped instance", func(t *testing.T) {
+		q.stop("instance-3")
+		trace := c.Trace(context.Background())
+		if trace.Errs["instance-3"] == nil || len(trace.Errs) != 1 {
+			t.Errorf("expected an error for instance-3 only, got `%v`", trace.Errs)
+		}
+		if len(trace.Rounds) != 2 {
+			t.Errorf("expected `%v` rounds, got `%v`", 2, len(trace.Rounds))
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrDivergentState is returned when instances committed different holders for the same ID
+	ErrDivergentState = errors.New("divergent state")
+
+	// ErrLagging is returned when an instance committed a lower ID than others
+	ErrLagging = errors.New("lagging")
+
+	// ErrPromisedBelowID is returned when an instance committed an ID higher than the ID it promised
+	ErrPromisedBelowID = errors.New("promised below committed ID")
+
+	// ErrUnreachable is returned when an instance could not be reached
+	ErrUnreachable = errors.New("unreachable")
+)
+
+// Report is the result of verifying the state of a quorum
+type Report struct {
+	// Violations are breaches of safety. A quorum with violations can not be trusted.
+	Violations []error
+	// Warnings are conditions that are expected to occur temporarily, e.g. lagging instances.
+	Warnings []error
+	// Majority is true if a majority of the quorum agrees on ID and holder
+	Majority bool
+	// ID and Holder are the state the majority agrees on
+	ID     uint64
+	Holder string
+	// Agreeing is the number of instances that agree with the majority, or the largest group if there is none
+	Agreeing int
+	// Size is the size of the quorum
+	Size int
+}
+
+// Verify checks the statuses of all instances of a quorum for consistency with each other
+func Verify(statuses []InstanceStatus) *Report {
+	report := Report{
+		Violations: []error{},
+		Warnings:   []error{},
+		Size:       len(statuses),
+	}
+
+	type state struct {
+		id     uint64
+		holder string
+	}
+	groups := make(map[state][]string)
+	holders := make(map[uint64]map[string][]string)
+	highest := uint64(0)
+	for _, s := range statuses {
+		name := s.Instance.Name
+		if s.Status == nil {
+			report.Warnings = append(report.Warnings, fmt.Errorf("%v: %w: %v", name, ErrUnreachable, s.Err))
+			continue
+		}
+		id, holder := s.Status.ID, s.Status.Holder
+
+		st := state{id: id, holder: holder}
+		groups[st] = append(groups[st], name)
+		if holders[id] == nil {
+			holders[id] = make(map[string][]string)
+		}
+		holders[id][holder] = append(holders[id][holder], name)
+		if id > highest {
+			highest = id
+		}
+
+		if s.Status.Promised < id {
+			report.Warnings = append(report.Warnings, fmt.Errorf("%v: %w: promised %v, committed %v",
+				name, ErrPromisedBelowID, s.Status.Promised, id))
+		}
+	}
+
+	// the same ID must always come with the same holder
+	for _, id := range sortedIDs(holders) {
+		if len(holders[id]) < 2 {
+			continue
+		}
+		names := []string{}
+		for _, holder := range sortedNames(holders[id]) {
+			names = append(names, fmt.Sprintf("%v holder `%v`", holders[id][holder], holder))
+		}
+		report.Violations = append(report.Violations, fmt.Errorf("ID %v: %w: %v", id, ErrDivergentState, names))
+	}
+
+	for _, s := range statuses {
+		if s.Status != nil && s.Status.ID < highest {
+			report.Warnings = append(report.Warnings, fmt.Errorf("%v: %w: committed ID %v, others committed %v",
+				s.Instance.Name, ErrLagging, s.Status.ID, highest))
+		}
+	}
+
+	// find the largest group of agreeing instances, preferring higher IDs
+	for st, names := range groups {
+		better := len(names) > report.Agreeing ||
+			(len(names) == report.Agreeing && st.id > report.ID) ||
+			(len(names) == report.Agreeing && st.id == report.ID && st.holder < report.Holder)
+		if better {
+			report.Agreeing = len(names)
+			report.ID = st.id
+			report.Holder = st.holder
+		}
+	}
+	report.Majority = report.Agreeing > report.Size/2
+
+	return &report
+}
+
+// OK returns true if there are no violations
+func (r *Report) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// sortedIDs returns the keys of a map in ascending order
+func sortedIDs(m map[uint64]map[string][]string) []uint64 {
+	ids := []uint64{}
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// sortedNames returns the keys of a map in lexical order
+func sortedNames(m map[string][]string) []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/control"
+)
+
+// statuses returns the statuses of a quorum, one per given status. A nil status marks an unreachable instance.
+func statuses(responses ...*control.StatusResponse) []InstanceStatus {
+	statuses := []InstanceStatus{}
+	for i, resp := range responses {
+		status := InstanceStatus{
+			Instance: config.Instance{Name: fmt.Sprintf("instance-%v", i+1)},
+			Status:   resp,
+		}
+		if resp == nil {
+			status.Err = errors.New("connection refused")
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("consistent", func(t *testing.T) {
+		report := Verify(statuses(
+			&control.StatusResponse{Promised: 3, ID: 3, Holder: "beaver"},
+			&control.StatusResponse{Promised: 3, ID: 3, Holder: "beaver"},
+			&control.StatusResponse{Promised: 3, ID: 3, Holder: "beaver"},
+		))
+		if !report.OK() || len(report.Warnings) != 0 {
+			t.Errorf("expected no problems, got `%v` and `%v`", report.Violations, report.Warnings)
+		}
+		if !report.Majority || report.Agreeing != 3 || report.ID != 3 || report.Holder != "beaver" {
+			t.Errorf("expected majority of 3 on ID 3 and holder beaver, got `%+v`", report)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		statuses   []InstanceStatus
+		violation  error
+		warning    error
+		majority   bool
+		agreeing   int
+		expectedID uint64
+	}{
+		"divergent state": {
+			statuses: statuses(
+				&control.StatusResponse{Promised: 3, ID: 3, Holder: "beaver"},
+				&control.StatusResponse{Promised: 3, ID: 3, Holder: "hamster"},
+				&control.StatusResponse{Promised: 3, ID: 3, Holder: "beaver"},
+			),
+			violation:  ErrDivergentState,
+			majority:   true,
+			agreeing:   2,
+			expectedID: 3,
+		},
+		"lagging": {
+			statuses: statuses(
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+				&control.StatusResponse{Promised: 5, ID: 2, Holder: ""},
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+			),
+			warning:    ErrLagging,
+			majority:   true,
+			agreeing:   2,
+			expectedID: 5,
+		},
+		"promised below ID": {
+			statuses: statuses(
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+				&control.StatusResponse{Promised: 4, ID: 5, Holder: "beaver"},
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+			),
+			warning:    ErrPromisedBelowID,
+			majority:   true,
+			agreeing:   3,
+			expectedID: 5,
+		},
+		"unreachable": {
+			statuses: statuses(
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+				nil,
+				nil,
+			),
+			warning:    ErrUnreachable,
+			majority:   false,
+			agreeing:   1,
+			expectedID: 5,
+		},
+		"no majority": {
+			statuses: statuses(
+				&control.StatusResponse{Promised: 5, ID: 5, Holder: "beaver"},
+				&control.StatusResponse{Promised: 5, ID: 4, Holder: ""},
+				&control.StatusResponse{Promised: 5, ID: 3, Holder: "hamster"},
+			),
+			warning:    ErrLagging,
+			majority:   false,
+			agreeing:   1,
+			expectedID: 5,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			report := Verify(tc.statuses)
+			if tc.violation != nil && !containsError(report.Violations, tc.violation) {
+				t.Errorf("expected `%v`, got `%v`", tc.violation, report.Violations)
+			}
+			if tc.violation == nil && !report.OK() {
+				t.Errorf("expected no violations, got `%v`", report.Violations)
+			}
+			if tc.warning != nil && !containsError(report.Warnings, tc.warning) {
+				t.Errorf("expected `%v`, got `%v`", tc.warning, report.Warnings)
+			}
+			if report.Majority != tc.majority {
+				t.Errorf("expected `%v`, got `%v`", tc.majority, report.Majority)
+			}
+			if report.Agreeing != tc.agreeing {
+				t.Errorf("expected `%v`, got `%v`", tc.agreeing, report.Agreeing)
+			}
+			if report.ID != tc.expectedID {
+				t.Errorf("expected `%v`, got `%v`", tc.expectedID, report.ID)
+			}
+		})
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	q := newTestQuorum(t, 3)
+	defer q.destroy()
+	c := q.client()
+	defer c.Close()
+
+	if _, err := c.TryAcquire(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	q.stop("instance-3")
+
+	statuses := c.Status(context.Background())
+	if len(statuses) != 3 {
+		t.Fatalf("expected `%v`, got `%v`", 3, len(statuses))
+	}
+	for i, status := range statuses[:2] {
+		if status.Err != nil || status.Status.Holder != "beaver" {
+			t.Errorf("instance-%v: expected holder `%v`, got `%v` (%v)", i+1, "beaver", status.Status, status.Err)
+		}
+	}
+	if statuses[2].Err == nil || statuses[2].Status != nil {
+		t.Errorf("expected error, got `%v`", statuses[2].Status)
+	}
+
+	report := Verify(statuses)
+	if !report.OK() || !report.Majority || report.Holder != "beaver" {
+		t.Errorf("expected consistent majority, got `%+v`", report)
+	}
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
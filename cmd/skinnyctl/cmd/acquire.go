@@ -2,17 +2,28 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
-	"github.com/danrl/skinny/proto/lock"
+	"github.com/danrl/skinny/client"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
 )
 
 func init() {
 	rootCmd.AddCommand(acquireCmd)
-	acquireCmd.PersistentFlags().StringVar(&flagInstance, "instance", "", "name of instance to connect to")
+	acquireCmd.PersistentFlags().StringVar(&flagInstance, "instance", "", "name of instance to try first")
+}
+
+// acquireResult is the machine-readable result of the acquire command
+type acquireResult struct {
+	Instance string   `json:"instance" yaml:"instance"`
+	Address  string   `json:"address" yaml:"address"`
+	Holder   string   `json:"holder" yaml:"holder"`
+	Acquired bool     `json:"acquired" yaml:"acquired"`
+	HeldBy   string   `json:"heldBy" yaml:"heldBy"`
+	Failures []string `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 var acquireCmd = &cobra.Command{
@@ -20,35 +31,41 @@ var acquireCmd = &cobra.Command{
 	Short: "Acquire the lock on behalf of holder",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// select default instance if no one was specified
-		if flagInstance == "" {
-			flagInstance = cfgDefaultInstance
+		c := newClient()
+		defer c.Close()
+		result := acquireResult{
+			Holder: args[0],
 		}
 
-		// connect to instance
-		address := cfgInstances[flagInstance]
-		fmt.Printf("📡 connecting to %v (%v)\n", flagInstance, address)
-		conn, err := grpc.Dial(address, grpc.WithInsecure())
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "dial: %v\n", err)
-			os.Exit(1)
-		}
-		defer conn.Close()
-		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
-		defer cancel()
-
-		// try to acquire lock
-		fmt.Println("🔒 acquiring lock")
-		client := lock.NewLockClient(conn)
-		resp, err := client.Acquire(ctx, &lock.AcquireRequest{Holder: args[0]})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
-		if resp.Acquired {
-			fmt.Println("✅ success")
-		} else {
-			fmt.Println("🚫 failed")
+		// try to acquire lock, failing over to other instances if necessary
+		infof("🔒 acquiring lock\n")
+		served, err := c.TryAcquire(context.Background(), result.Holder)
+		result.Failures = failures(served)
+		result.Instance = served.Instance.Name
+		result.Address = served.Instance.Address
+		result.HeldBy = served.Holder
+		result.Acquired = err == nil
+		switch {
+		case err == nil:
+			printResult(result, func() {
+				fmt.Printf("✅ success (served by %v)\n", result.Instance)
+			})
+		case errors.Is(err, client.ErrLockHeld):
+			printResult(result, func() {
+				fmt.Printf("🚫 failed (held by %v)\n", result.HeldBy)
+			})
+			os.Exit(exitLockHeld)
+		case errors.Is(err, client.ErrNotAcquired):
+			printResult(result, func() {
+				fmt.Println("🚫 failed")
+			})
+			os.Exit(exitFailure)
+		default:
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
 		}
 	},
 }
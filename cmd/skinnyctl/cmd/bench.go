@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danrl/skinny/checker"
+	"github.com/danrl/skinny/client"
+	"github.com/spf13/cobra"
+)
+
+// benchmark outcomes
+const (
+	outcomeOK        = "ok"
+	outcomeContended = "contended"
+	outcomeError     = "error"
+)
+
+var (
+	flagBenchClients   int
+	flagBenchDuration  time.Duration
+	flagBenchInstances []string
+	flagBenchCSV       string
+	flagBenchHistory   string
+)
+
+func init() {
+	benchCmd.Flags().IntVar(&flagBenchClients, "clients", 4, "number of concurrent clients")
+	benchCmd.Flags().DurationVar(&flagBenchDuration, "duration", 10*time.Second, "duration of the benchmark")
+	benchCmd.Flags().StringSliceVar(&flagBenchInstances, "instances", nil,
+		"instances to send requests to, assigned to clients in turn (default all)")
+	benchCmd.Flags().StringVar(&flagBenchCSV, "csv", "", "file to write every request to, for plotting")
+	benchCmd.Flags().StringVar(&flagBenchHistory, "history", "",
+		"file to write the history of every request to, for check-history")
+	rootCmd.AddCommand(benchCmd)
+}
+
+// sample is a single request made during a benchmark
+type sample struct {
+	client    int
+	operation string
+	instance  string
+	start     time.Time
+	latency   time.Duration
+	outcome   string
+}
+
+// operationStats is the machine-readable summary of all requests of one operation
+type operationStats struct {
+	Operation string  `json:"operation" yaml:"operation"`
+	Count     int     `json:"count" yaml:"count"`
+	OK        float64 `json:"ok" yaml:"ok"`
+	Contended float64 `json:"contended" yaml:"contended"`
+	Errors    float64 `json:"errors" yaml:"errors"`
+	P50       string  `json:"p50" yaml:"p50"`
+	P90       string  `json:"p90" yaml:"p90"`
+	P99       string  `json:"p99" yaml:"p99"`
+	Max       string  `json:"max" yaml:"max"`
+}
+
+// benchResult is the machine-readable result of the bench command
+type benchResult struct {
+	Clients      int              `json:"clients" yaml:"clients"`
+	Instances    []string         `json:"instances" yaml:"instances"`
+	Duration     string           `json:"duration" yaml:"duration"`
+	Acquisitions float64          `json:"acquisitionsPerSecond" yaml:"acquisitionsPerSecond"`
+	Requests     float64          `json:"requestsPerSecond" yaml:"requestsPerSecond"`
+	Operations   []operationStats `json:"operations" yaml:"operations"`
+	CSV          string           `json:"csv,omitempty" yaml:"csv,omitempty"`
+	History      string           `json:"history,omitempty" yaml:"history,omitempty"`
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure throughput and latency of a quorum of Skinny instances",
+	Long: `Measure throughput and latency of a quorum of Skinny instances
+
+Every client repeatedly tries to acquire the lock on behalf of its own holder and releases it right after. Clients
+contend for the lock, so some acquisitions fail because another client holds the lock. Do not run a benchmark against
+a quorum whose lock is in use.
+
+With --history, every request is recorded from the point of view of the clients. Use check-history to verify that the
+lock was never granted to two clients at the same time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagBenchClients < 1 {
+			fail("invalid number of clients: %v", flagBenchClients)
+		}
+		instances := flagBenchInstances
+		if len(instances) == 0 {
+			for _, in := range cfgQuorum.Instances {
+				instances = append(instances, in.Name)
+			}
+		}
+		for _, name := range instances {
+			if _, ok := cfgInstances[name]; !ok {
+				fail("unknown instance: %v", name)
+			}
+		}
+
+		var history *os.File
+		var recorder *checker.Recorder
+		if flagBenchHistory != "" {
+			var err error
+			history, err = os.Create(flagBenchHistory)
+			if err != nil {
+				fail("create history: %v", err)
+			}
+			recorder = checker.NewRecorder(history)
+		}
+
+		infof("🏋️  benchmarking %v client(s) against %v for %v\n", flagBenchClients, instances, flagBenchDuration)
+		samples := bench(flagBenchClients, instances, flagBenchDuration, recorder)
+
+		result := benchResult{
+			Clients:    flagBenchClients,
+			Instances:  instances,
+			Duration:   flagBenchDuration.String(),
+			Operations: []operationStats{},
+		}
+		acquisitions := 0
+		for _, s := range samples {
+			if s.operation == "acquire" && s.outcome == outcomeOK {
+				acquisitions++
+			}
+		}
+		result.Acquisitions = float64(acquisitions) / flagBenchDuration.Seconds()
+		result.Requests = float64(len(samples)) / flagBenchDuration.Seconds()
+		for _, operation := range []string{"acquire", "release"} {
+			result.Operations = append(result.Operations, summarize(operation, samples))
+		}
+
+		if flagBenchCSV != "" {
+			if err := writeSamples(flagBenchCSV, samples); err != nil {
+				fail("write csv: %v", err)
+			}
+			result.CSV = flagBenchCSV
+		}
+		if history != nil {
+			err := recorder.Err()
+			if e := history.Close(); err == nil {
+				err = e
+			}
+			if err != nil {
+				fail("write history: %v", err)
+			}
+			result.History = flagBenchHistory
+		}
+
+		printResult(result, func() {
+			tw := tabwriter.NewWriter(os.Stdout, 5, 4, 3, ' ', 0)
+			fmt.Fprintln(tw, "OPERATION\tCOUNT\tOK\tCONTENDED\tERRORS\tP50\tP90\tP99\tMAX")
+			for _, op := range result.Operations {
+				fmt.Fprintf(tw, "%v\t%v\t%.1f%%\t%.1f%%\t%.1f%%\t%v\t%v\t%v\t%v\n",
+					op.Operation, op.Count, 100*op.OK, 100*op.Contended, 100*op.Errors, op.P50, op.P90, op.P99, op.Max)
+			}
+			tw.Flush()
+			fmt.Printf("📈 %.1f acquisitions/s, %.1f requests/s\n", result.Acquisitions, result.Requests)
+			if result.CSV != "" {
+				fmt.Printf("📝 wrote %v\n", result.CSV)
+			}
+			if result.History != "" {
+				fmt.Printf("📝 wrote %v\n", result.History)
+			}
+		})
+	},
+}
+
+// bench runs clients concurrently for the given duration and returns all requests they made. Requests are recorded
+// if a recorder is given.
+func bench(clients int, instances []string, duration time.Duration, recorder *checker.Recorder) []sample {
+	deadline := time.Now().Add(duration)
+	samples := []sample{}
+	mu := sync.Mutex{}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < clients; i++ {
+		c := newClient()
+		if err := c.SetPreferred(instances[i%len(instances)]); err != nil {
+			fail("%v: %v", err, instances[i%len(instances)])
+		}
+		c.SetRecorder(recorder)
+		wg.Add(1)
+		go func(id int, c *client.Client) {
+			defer wg.Done()
+			defer c.Close()
+			holder := fmt.Sprintf("bench-%v", id)
+			local := []sample{}
+			for time.Now().Before(deadline) {
+				start := time.Now()
+				result, err := c.TryAcquire(context.Background(), holder)
+				local = append(local, newSample(id, "acquire", start, result, err))
+				if err != nil {
+					continue
+				}
+				start = time.Now()
+				result, err = c.Release(context.Background(), holder)
+				local = append(local, newSample(id, "release", start, result, err))
+			}
+			mu.Lock()
+			samples = append(samples, local...)
+			mu.Unlock()
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].start.Before(samples[j].start)
+	})
+	return samples
+}
+
+// newSample records the outcome of a request that started at start
+func newSample(client int, operation string, start time.Time, result *client.Result, err error) sample {
+	s := sample{
+		client:    client,
+		operation: operation,
+		instance:  result.Instance.Name,
+		start:     start,
+		latency:   time.Since(start),
+		outcome:   outcomeOK,
+	}
+	switch {
+	case err == nil:
+	case isContention(err):
+		s.outcome = outcomeContended
+	default:
+		s.outcome = outcomeError
+	}
+	return s
+}
+
+// isContention returns true if a request failed because another client holds the lock. A quorum that could not agree
+// is an error.
+func isContention(err error) bool {
+	return errors.Is(err, client.ErrLockHeld)
+}
+
+// summarize returns the outcome ratios and latency percentiles of all requests of one operation
+func summarize(operation string, samples []sample) operationStats {
+	stats := operationStats{
+		Operation: operation,
+	}
+	latencies := []time.Duration{}
+	outcomes := make(map[string]int)
+	for _, s := range samples {
+		if s.operation != operation {
+			continue
+		}
+		latencies = append(latencies, s.latency)
+		outcomes[s.outcome]++
+	}
+	stats.Count = len(latencies)
+	if stats.Count == 0 {
+		return stats
+	}
+	stats.OK = float64(outcomes[outcomeOK]) / float64(stats.Count)
+	stats.Contended = float64(outcomes[outcomeContended]) / float64(stats.Count)
+	stats.Errors = float64(outcomes[outcomeError]) / float64(stats.Count)
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	stats.P50 = percentile(latencies, 50).Round(time.Microsecond).String()
+	stats.P90 = percentile(latencies, 90).Round(time.Microsecond).String()
+	stats.P99 = percentile(latencies, 99).Round(time.Microsecond).String()
+	stats.Max = latencies[len(latencies)-1].Round(time.Microsecond).String()
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted latencies using the nearest-rank method
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// writeSamples writes all requests to a CSV file
+func writeSamples(fname string, samples []sample) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"client", "operation", "instance", "start", "latency_ms", "outcome"})
+	if len(samples) > 0 {
+		begin := samples[0].start
+		for _, s := range samples {
+			_ = w.Write([]string{
+				strconv.Itoa(s.client),
+				s.operation,
+				s.instance,
+				strconv.FormatFloat(s.start.Sub(begin).Seconds(), 'f', 6, 64),
+				strconv.FormatFloat(float64(s.latency)/float64(time.Millisecond), 'f', 3, 64),
+				s.outcome,
+			})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
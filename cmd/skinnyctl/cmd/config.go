@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/danrl/skinny/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagOutputDir  string
+	flagQuorumFile string
+)
+
+func init() {
+	configGenerateCmd.Flags().StringVar(&flagOutputDir, "out", ".", "directory to write instance configurations to")
+	configGenerateCmd.Flags().StringVar(&flagQuorumFile, "quorum", "",
+		"file to write the quorum configuration to (default \"<out>/quorum.yml\")")
+	configCmd.AddCommand(configLintCmd)
+	configCmd.AddCommand(configGenerateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configLintResult is the machine-readable result of the config lint command
+type configLintResult struct {
+	Files    []string `json:"files" yaml:"files"`
+	Problems []string `json:"problems" yaml:"problems"`
+	OK       bool     `json:"ok" yaml:"ok"`
+}
+
+// configGenerateResult is the machine-readable result of the config generate command
+type configGenerateResult struct {
+	Files    []string `json:"files" yaml:"files"`
+	Problems []string `json:"problems" yaml:"problems"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with Skinny configuration files",
+}
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint <instance config>...",
+	Short: "Check instance configurations and the quorum configuration for consistency",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		instances := []*config.InstanceConfig{}
+		for _, fname := range args {
+			cfg, err := config.NewInstanceConfig(fname)
+			if err != nil {
+				fail("load config %v: %v", fname, err)
+			}
+			instances = append(instances, cfg)
+		}
+
+		infof("🔍 checking %v instance configuration(s) against %v\n", len(instances), flagConfigFile)
+		result := configLintResult{
+			Files:    append([]string{flagConfigFile}, args...),
+			Problems: []string{},
+		}
+		for _, problem := range config.Lint(cfgQuorum, instances...) {
+			result.Problems = append(result.Problems, problem.Error())
+		}
+		result.OK = len(result.Problems) == 0
+		printResult(result, func() {
+			for _, problem := range result.Problems {
+				fmt.Printf("⚠️  %v\n", problem)
+			}
+			if !result.OK {
+				fmt.Printf("🚫 found %v problem(s)\n", len(result.Problems))
+				return
+			}
+			fmt.Println("✅ no problems found")
+		})
+		if !result.OK {
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+var configGenerateCmd = &cobra.Command{
+	Use:   "generate <cluster description>",
+	Short: "Generate instance configurations and the quorum configuration from a cluster description",
+	Args:  cobra.ExactArgs(1),
+	// there is no quorum configuration to load yet
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		checkOutput()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cluster, err := config.NewClusterConfig(args[0])
+		if err != nil {
+			fail("load config %v: %v", args[0], err)
+		}
+		instances, quorum := cluster.Generate()
+		result := configGenerateResult{
+			Files:    []string{},
+			Problems: []string{},
+		}
+
+		for _, cfg := range instances {
+			fname := filepath.Join(flagOutputDir, cfg.Name+".yml")
+			if err := cfg.Save(fname); err != nil {
+				fail("write config: %v", err)
+			}
+			infof("📝 wrote %v (increment %v)\n", fname, cfg.Increment)
+			result.Files = append(result.Files, fname)
+		}
+		if flagQuorumFile == "" {
+			flagQuorumFile = filepath.Join(flagOutputDir, "quorum.yml")
+		}
+		if err := quorum.Save(flagQuorumFile); err != nil {
+			fail("write config: %v", err)
+		}
+		infof("📝 wrote %v\n", flagQuorumFile)
+		result.Files = append(result.Files, flagQuorumFile)
+
+		// generated configurations are consistent by design, but the cluster may still be of an even size
+		for _, problem := range config.Lint(quorum, instances...) {
+			result.Problems = append(result.Problems, problem.Error())
+		}
+		printResult(result, func() {
+			for _, problem := range result.Problems {
+				fmt.Printf("⚠️  %v\n", problem)
+			}
+			fmt.Println("✅ success")
+		})
+	},
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+func init() {
+	rootCmd.AddCommand(drainCmd)
+}
+
+// drainResult is the machine-readable result of the drain command
+type drainResult struct {
+	Instance string `json:"instance" yaml:"instance"`
+	Address  string `json:"address" yaml:"address"`
+	Drained  bool   `json:"drained" yaml:"drained"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var drainCmd = &cobra.Command{
+	Use:   "drain <instance>",
+	Short: "Stop an instance from serving new lock requests",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		address, ok := cfgInstances[name]
+		if !ok {
+			fail("unknown instance: %v", name)
+		}
+		result := drainResult{
+			Instance: name,
+			Address:  address,
+		}
+
+		// connect to instance
+		infof("📡 connecting to %v (%v)\n", name, address)
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			fail("dial: %v", err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
+		defer cancel()
+
+		// wait for the instance to finish in-flight lock requests
+		infof("🚰 draining instance\n")
+		client := control.NewControlClient(conn)
+		resp, err := client.Drain(ctx, &control.DrainRequest{})
+		if err != nil {
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
+		}
+		result.Drained = resp.Drained
+		printResult(result, func() {
+			if resp.Drained {
+				fmt.Println("✅ success")
+			} else {
+				fmt.Println("🚫 failed")
+			}
+		})
+		if !resp.Drained {
+			os.Exit(exitFailure)
+		}
+	},
+}
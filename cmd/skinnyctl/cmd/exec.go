@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/danrl/skinny/client"
+	"github.com/spf13/cobra"
+)
+
+// exit codes of commands that could not be run, following shell conventions
+const (
+	exitCannotExecute = 126
+	exitNotFound      = 127
+)
+
+var (
+	flagHolder  string
+	flagRefresh time.Duration
+)
+
+func init() {
+	execCmd.Flags().StringVar(&flagHolder, "holder", "", "name of the lock holder (required)")
+	execCmd.Flags().StringVar(&flagInstance, "instance", "", "name of instance to try first")
+	execCmd.Flags().DurationVar(&flagRefresh, "refresh", 0,
+		"interval in which to confirm that the lock is still held while the command runs (0 disables)")
+	rootCmd.AddCommand(execCmd)
+}
+
+// execResult is the machine-readable result of the exec command
+type execResult struct {
+	Instance string   `json:"instance" yaml:"instance"`
+	Address  string   `json:"address" yaml:"address"`
+	Holder   string   `json:"holder" yaml:"holder"`
+	Acquired bool     `json:"acquired" yaml:"acquired"`
+	HeldBy   string   `json:"heldBy" yaml:"heldBy"`
+	ExitCode int      `json:"exitCode" yaml:"exitCode"`
+	Released bool     `json:"released" yaml:"released"`
+	Failures []string `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec --holder <holder> -- <command> [args...]",
+	Short: "Run a command while holding the lock",
+	Long: `Run a command while holding the lock
+
+The lock is acquired on behalf of holder before the command is started and released once the command exited,
+regardless of its outcome. Signals received by skinnyctl are forwarded to the command. The exit code is the one of
+the command, or 2 if the lock is held by someone else, or 1 if the lock could not be acquired for other reasons.
+
+Skinny locks do not expire. With --refresh, the instances are asked periodically who holds the lock while the command
+runs, which reports if the lock was lost, e.g. because it has been released by someone else. The lock is never
+acquired again. A lost lock is not released.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// the standard output belongs to the command
+		stdout = os.Stderr
+
+		if flagHolder == "" {
+			fail("missing holder")
+		}
+		c := newClient()
+		defer c.Close()
+		result := execResult{
+			Holder:   flagHolder,
+			ExitCode: exitFailure,
+		}
+
+		// acquire lock, failing over to other instances if necessary
+		infof("🔒 acquiring lock\n")
+		served, err := c.TryAcquire(context.Background(), result.Holder)
+		result.Failures = failures(served)
+		result.Instance = served.Instance.Name
+		result.Address = served.Instance.Address
+		result.HeldBy = served.Holder
+		result.Acquired = err == nil
+		switch {
+		case errors.Is(err, client.ErrLockHeld):
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "🚫 failed (held by %v)\n", result.HeldBy)
+			})
+			os.Exit(exitLockHeld)
+		case err != nil:
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
+		}
+
+		// watch the lock while the command runs
+		var lease *client.Lease
+		if flagRefresh > 0 {
+			lease = c.Renew(result.Holder, flagRefresh, func(served *client.Result, err error) {
+				switch {
+				case errors.Is(err, client.ErrLockHeld):
+					fmt.Fprintf(os.Stderr, "⚠️  lock lost (held by %v)\n", served.Holder)
+				case errors.Is(err, client.ErrLockLost):
+					fmt.Fprintf(os.Stderr, "⚠️  lock lost (released by someone else)\n")
+				case err != nil:
+					fmt.Fprintf(os.Stderr, "⚠️  refresh: %v\n", err)
+				}
+			})
+		}
+
+		// run command, forwarding signals to it
+		infof("🏃 running %v\n", args[0])
+		child := exec.Command(args[0], args[1:]...)
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+		sc := make(chan os.Signal, 1)
+		signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT,
+			syscall.SIGUSR1, syscall.SIGUSR2)
+		result.ExitCode, err = run(child, sc)
+		signal.Stop(sc)
+		if err != nil {
+			result.Error = err.Error()
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		if lease != nil {
+			lease.Stop()
+		}
+
+		// release lock, unless it got lost in the meantime
+		switch {
+		case lease != nil && lease.Holder() == "":
+			result.HeldBy = ""
+			fmt.Fprintf(os.Stderr, "error: lock released by someone else, not releasing\n")
+		case lease != nil && lease.Holder() != result.Holder:
+			result.HeldBy = lease.Holder()
+			fmt.Fprintf(os.Stderr, "error: lock held by %v, not releasing\n", result.HeldBy)
+		default:
+			infof("🔓 releasing lock\n")
+			served, err := c.Release(context.Background(), result.Holder)
+			result.Failures = append(result.Failures, failures(served)...)
+			switch {
+			case errors.Is(err, client.ErrNotReleased) && served.Holder != "" && served.Holder != result.Holder:
+				result.HeldBy = served.Holder
+				fmt.Fprintf(os.Stderr, "error: lock held by %v, not released\n", result.HeldBy)
+			case err != nil:
+				result.Error = err.Error()
+				fmt.Fprintf(os.Stderr, "error: release: %v\n", err)
+			default:
+				result.Released = true
+			}
+		}
+
+		printResult(result, func() {
+			fmt.Fprintf(os.Stderr, "🏁 exit code %v\n", result.ExitCode)
+		})
+		os.Exit(result.ExitCode)
+	},
+}
+
+// run starts the child process and waits for it to exit. Signals received on sc are forwarded to the child. The exit
+// code of the child is returned.
+func run(child *exec.Cmd, sc <-chan os.Signal) (int, error) {
+	if err := child.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return exitNotFound, err
+		}
+		return exitCannotExecute, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- child.Wait()
+	}()
+
+	for {
+		select {
+		case sig := <-sc:
+			_ = child.Process.Signal(sig)
+		case err := <-exited:
+			return exitCode(child, err)
+		}
+	}
+}
+
+// exitCode returns the exit code of an exited child process. Children killed by a signal exit with 128 plus the
+// signal number, like they would in a shell.
+func exitCode(child *exec.Cmd, err error) (int, error) {
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return exitFailure, err
+	}
+	if ws, ok := child.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return child.ProcessState.ExitCode(), nil
+}
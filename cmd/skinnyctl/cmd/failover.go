@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/danrl/skinny/client"
+)
+
+var (
+	flagFailover string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagFailover, "failover", "ordered",
+		"order in which instances are tried by lock commands (ordered, random, or leader-first)")
+}
+
+// checkFailover exits if the requested failover strategy is unknown
+func checkFailover() {
+	if _, err := client.ParseStrategy(flagFailover); err != nil {
+		fail("%v: %v", err, flagFailover)
+	}
+}
+
+// newClient returns a client for the quorum that tries the instance given by --instance first, if any
+func newClient() *client.Client {
+	c, err := client.New(cfgQuorum)
+	if err != nil {
+		fail("client: %v", err)
+	}
+	strategy, _ := client.ParseStrategy(flagFailover)
+	c.SetStrategy(strategy)
+	if err := c.SetPreferred(flagInstance); err != nil {
+		fail("%v: %v", err, flagInstance)
+	}
+	return c
+}
+
+// failures reports the instances that have been tried before the one serving a request
+func failures(result *client.Result) []string {
+	failures := []string{}
+	if result == nil {
+		return failures
+	}
+	for _, err := range result.Failures {
+		infof("⚠️  %v\n", err)
+		failures = append(failures, err.Error())
+	}
+	return failures
+}
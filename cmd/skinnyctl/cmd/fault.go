@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	flagFaultPeers     []string
+	flagFaultLatency   time.Duration
+	flagFaultDrop      uint
+	flagFaultPartition bool
+	flagFaultHeal      bool
+	flagFaultClear     bool
+)
+
+func init() {
+	faultCmd.Flags().StringSliceVar(&flagFaultPeers, "peer", nil, "peer to disturb, repeat for more peers (default all peers)")
+	faultCmd.Flags().DurationVar(&flagFaultLatency, "latency", 0, "latency to add to every request")
+	faultCmd.Flags().UintVar(&flagFaultDrop, "drop", 0, "percentage of promises and commits to drop")
+	faultCmd.Flags().BoolVar(&flagFaultPartition, "partition", false, "fail every request")
+	faultCmd.Flags().BoolVar(&flagFaultHeal, "heal", false, "remove the faults of the given peers")
+	faultCmd.Flags().BoolVar(&flagFaultClear, "clear", false, "remove all faults")
+	rootCmd.AddCommand(faultCmd)
+}
+
+// faultStatus is the machine-readable state of a fault injected into an instance
+type faultStatus struct {
+	Peer      string `json:"peer,omitempty" yaml:"peer,omitempty"`
+	Latency   string `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Drop      uint32 `json:"drop,omitempty" yaml:"drop,omitempty"`
+	Partition bool   `json:"partition,omitempty" yaml:"partition,omitempty"`
+}
+
+// newFaultStatus converts faults as found in responses
+func newFaultStatus(faults []*control.Fault) []faultStatus {
+	status := []faultStatus{}
+	for _, f := range faults {
+		status = append(status, faultStatus{
+			Peer:      f.Peer,
+			Latency:   f.Latency,
+			Drop:      f.Drop,
+			Partition: f.Partition,
+		})
+	}
+	return status
+}
+
+// String describes the fault, e.g. "partitioned from peer-1"
+func (f faultStatus) String() string {
+	peer := f.Peer
+	if peer == "" {
+		peer = "all peers"
+	}
+	effects := []string{}
+	if f.Partition {
+		effects = append(effects, "partitioned from "+peer)
+	}
+	if f.Latency != "" {
+		effects = append(effects, fmt.Sprintf("%v latency to and from %v", f.Latency, peer))
+	}
+	if f.Drop > 0 {
+		effects = append(effects, fmt.Sprintf("%v%% of promises and commits to and from %v dropped", f.Drop, peer))
+	}
+	return strings.Join(effects, ", ")
+}
+
+// faultResult is the machine-readable result of the fault command
+type faultResult struct {
+	Instance string        `json:"instance" yaml:"instance"`
+	Address  string        `json:"address" yaml:"address"`
+	Faults   []faultStatus `json:"faults" yaml:"faults"`
+	Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var faultCmd = &cobra.Command{
+	Use:   "fault <instance>",
+	Short: "Inject faults into the consensus requests of an instance",
+	Long: `Inject faults into the consensus requests of an instance
+
+Faults disturb promises and commits between an instance and its peers, in both directions. They add latency, drop a
+percentage of requests, or partition the instance from its peers. A new fault replaces the previous fault for the same
+peer, and a fault for a single peer takes precedence over a fault for all peers. Without any fault flags, the faults
+currently injected are shown.
+
+Examples:
+  skinnyctl fault oregon --latency 200ms
+  skinnyctl fault oregon --drop 30 --peer virginia
+  skinnyctl fault oregon --partition --peer virginia --peer ireland
+  skinnyctl fault oregon --heal --peer virginia
+  skinnyctl fault oregon --clear`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		address, ok := cfgInstances[name]
+		if !ok {
+			fail("unknown instance: %v", name)
+		}
+		if flagFaultDrop > 100 {
+			fail("invalid drop percentage: %v", flagFaultDrop)
+		}
+		result := faultResult{
+			Instance: name,
+			Address:  address,
+			Faults:   []faultStatus{},
+		}
+
+		// one fault per peer, an empty peer stands for all peers
+		req := control.InjectFaultRequest{
+			Clear: flagFaultClear,
+		}
+		disturb := cmd.Flags().Changed("latency") || cmd.Flags().Changed("drop") || cmd.Flags().Changed("partition")
+		if disturb || flagFaultHeal {
+			peers := flagFaultPeers
+			if len(peers) == 0 {
+				peers = []string{""}
+			}
+			for _, peer := range peers {
+				f := control.Fault{Peer: peer}
+				if !flagFaultHeal {
+					if flagFaultLatency > 0 {
+						f.Latency = flagFaultLatency.String()
+					}
+					f.Drop = uint32(flagFaultDrop)
+					f.Partition = flagFaultPartition
+				}
+				req.Faults = append(req.Faults, &f)
+			}
+		}
+
+		// connect to instance
+		infof("📡 connecting to %v (%v)\n", name, address)
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			fail("dial: %v", err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
+		defer cancel()
+
+		if len(req.Faults) > 0 || req.Clear {
+			infof("💥 injecting faults\n")
+		}
+		client := control.NewControlClient(conn)
+		resp, err := client.InjectFault(ctx, &req)
+		if err != nil {
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
+		}
+		result.Faults = newFaultStatus(resp.Faults)
+		printResult(result, func() {
+			if len(result.Faults) == 0 {
+				fmt.Println("💚 no faults injected")
+			}
+			for _, f := range result.Faults {
+				fmt.Printf("💥 %v\n", f)
+			}
+		})
+	},
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danrl/skinny/checker"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(checkHistoryCmd)
+}
+
+// historyOperation is the machine-readable form of an operation of a history
+type historyOperation struct {
+	ID        uint64 `json:"id" yaml:"id"`
+	Operation string `json:"operation" yaml:"operation"`
+	Holder    string `json:"holder,omitempty" yaml:"holder,omitempty"`
+	Invoked   string `json:"invoked" yaml:"invoked"`
+	Completed string `json:"completed,omitempty" yaml:"completed,omitempty"`
+	Outcome   string `json:"outcome" yaml:"outcome"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// checkHistoryResult is the machine-readable result of the check-history command
+type checkHistoryResult struct {
+	File         string             `json:"file" yaml:"file"`
+	Operations   int                `json:"operations" yaml:"operations"`
+	Linearizable bool               `json:"linearizable" yaml:"linearizable"`
+	Violation    []historyOperation `json:"violation" yaml:"violation"`
+}
+
+var checkHistoryCmd = &cobra.Command{
+	Use:   "check-history <history>",
+	Short: "Check a recorded history of lock operations for linearizability",
+	Long: `Check a recorded history of lock operations for linearizability
+
+A history lists when clients invoked acquires and releases and how they completed, as recorded by bench --history or
+by the client library. The history is linearizable if every operation can be placed at a single point in time between
+its invocation and its completion such that the lock has at most one holder at a time. Failed and timed out operations
+may or may not have taken effect.
+
+If the history is not linearizable, a minimal violating sub-history is shown: the acquires that conflict and every
+release that could have happened in between. Times are relative to the first event of the history.`,
+	Args: cobra.ExactArgs(1),
+	// a history is checked without talking to the quorum
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		checkOutput()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fail("open history: %v", err)
+		}
+		events, err := checker.ReadHistory(f)
+		f.Close()
+		if err != nil {
+			fail("read history %v: %v", args[0], err)
+		}
+
+		infof("🔍 checking %v events from %v\n", len(events), args[0])
+		report, err := checker.Check(events)
+		if err != nil {
+			fail("check history: %v", err)
+		}
+		result := checkHistoryResult{
+			File:         args[0],
+			Operations:   report.Operations,
+			Linearizable: report.Linearizable,
+			Violation:    []historyOperation{},
+		}
+		var begin time.Time
+		if len(events) > 0 {
+			begin = events[0].Time
+		}
+		for _, o := range report.Violation {
+			op := historyOperation{
+				ID:        o.ID,
+				Operation: o.Op,
+				Holder:    o.Holder,
+				Invoked:   o.Invoked.Sub(begin).String(),
+				Outcome:   o.Outcome,
+				Error:     o.Error,
+			}
+			if o.Outcome == "" {
+				op.Outcome = "pending"
+			} else {
+				op.Completed = o.Completed.Sub(begin).String()
+			}
+			result.Violation = append(result.Violation, op)
+		}
+
+		printResult(result, func() {
+			if result.Linearizable {
+				fmt.Printf("✅ %v operations are linearizable\n", result.Operations)
+				return
+			}
+			fmt.Printf("🚨 %v operations are not linearizable, minimal violating sub-history:\n", result.Operations)
+			tw := tabwriter.NewWriter(os.Stdout, 5, 4, 3, ' ', 0)
+			fmt.Fprintln(tw, "ID\tOPERATION\tHOLDER\tINVOKED\tCOMPLETED\tOUTCOME")
+			for _, op := range result.Violation {
+				fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n",
+					op.ID, op.Operation, op.Holder, op.Invoked, op.Completed, op.Outcome)
+			}
+			tw.Flush()
+		})
+		if !result.Linearizable {
+			os.Exit(exitViolation)
+		}
+	},
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danrl/skinny/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagMeshCSV string
+)
+
+func init() {
+	meshCmd.Flags().StringVar(&flagMeshCSV, "csv", "", "file to write every link to, for plotting")
+	rootCmd.AddCommand(meshCmd)
+}
+
+// linkResult is the machine-readable state of the link from one instance to another
+type linkResult struct {
+	From      string `json:"from" yaml:"from"`
+	To        string `json:"to" yaml:"to"`
+	Probed    bool   `json:"probed" yaml:"probed"`
+	Reachable bool   `json:"reachable" yaml:"reachable"`
+	RTT       string `json:"rtt,omitempty" yaml:"rtt,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// meshResult is the machine-readable result of the mesh command
+type meshResult struct {
+	Instances []string     `json:"instances" yaml:"instances"`
+	Links     []linkResult `json:"links" yaml:"links"`
+	Up        int          `json:"up" yaml:"up"`
+	CSV       string       `json:"csv,omitempty" yaml:"csv,omitempty"`
+}
+
+var meshCmd = &cobra.Command{
+	Use:   "mesh",
+	Short: "Measure connectivity between every pair of Skinny instances",
+	Long: `Measure connectivity between every pair of Skinny instances
+
+Every instance is asked to ping its peers over the connections it uses for consensus. The result is a matrix with one
+row per instance and one column per peer it pinged. Broken links are marked with a cross, links from instances that
+could not be asked with a question mark.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := newClient()
+		defer c.Close()
+
+		infof("🕸️  probing links between %v instances\n", len(cfgQuorum.Instances))
+		mesh := c.Mesh(context.Background())
+
+		result := meshResult{
+			Instances: mesh.Instances,
+			Links:     []linkResult{},
+			Up:        mesh.Up(),
+		}
+		for _, link := range mesh.Links {
+			l := linkResult{
+				From:      link.From,
+				To:        link.To,
+				Probed:    link.Probed,
+				Reachable: link.Reachable,
+			}
+			if link.Reachable {
+				l.RTT = link.RTT.Round(time.Microsecond).String()
+			}
+			if link.Err != nil {
+				l.Error = link.Err.Error()
+			}
+			result.Links = append(result.Links, l)
+		}
+
+		if flagMeshCSV != "" {
+			if err := writeLinks(flagMeshCSV, mesh); err != nil {
+				fail("write csv: %v", err)
+			}
+			result.CSV = flagMeshCSV
+		}
+
+		printResult(result, func() {
+			printMesh(mesh)
+			fmt.Printf("🔗 %v of %v links up\n", result.Up, len(result.Links))
+			if result.CSV != "" {
+				fmt.Printf("📝 wrote %v\n", result.CSV)
+			}
+		})
+
+		if result.Up < len(result.Links) {
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+// printMesh prints the links between all instances as a matrix, rows are the instances links start from
+func printMesh(mesh *client.Mesh) {
+	tw := tabwriter.NewWriter(os.Stdout, 5, 4, 3, ' ', 0)
+	fmt.Fprint(tw, "FROM \\ TO")
+	for _, to := range mesh.Instances {
+		fmt.Fprintf(tw, "\t%v", to)
+	}
+	fmt.Fprintln(tw)
+	for _, from := range mesh.Instances {
+		fmt.Fprint(tw, from)
+		for _, to := range mesh.Instances {
+			link, ok := mesh.Link(from, to)
+			switch {
+			case from == to || !ok:
+				fmt.Fprint(tw, "\t-")
+			case !link.Probed:
+				fmt.Fprint(tw, "\t?")
+			case !link.Reachable:
+				fmt.Fprint(tw, "\t✗")
+			default:
+				fmt.Fprintf(tw, "\t%v", link.RTT.Round(time.Microsecond))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	// errors would not fit into the matrix
+	for _, link := range mesh.Links {
+		if link.Probed && !link.Reachable {
+			fmt.Printf("⚠️  %v → %v: %v\n", link.From, link.To, link.Err)
+		}
+	}
+	for _, from := range mesh.Instances {
+		for _, link := range mesh.Links {
+			if link.From == from && !link.Probed {
+				fmt.Printf("⚠️  %v: %v\n", from, link.Err)
+				break
+			}
+		}
+	}
+}
+
+// writeLinks writes all links to a CSV file
+func writeLinks(fname string, mesh *client.Mesh) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"from", "to", "probed", "reachable", "rtt_ms"})
+	for _, link := range mesh.Links {
+		rtt := ""
+		if link.Reachable {
+			rtt = strconv.FormatFloat(float64(link.RTT)/float64(time.Millisecond), 'f', 3, 64)
+		}
+		_ = w.Write([]string{
+			link.From,
+			link.To,
+			strconv.FormatBool(link.Probed),
+			strconv.FormatBool(link.Reachable),
+			rtt,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// exit codes
+const (
+	// exitFailure signals that a request failed, e.g. due to connection or quorum errors
+	exitFailure = 1
+	// exitLockHeld signals that the lock is held by someone else
+	exitLockHeld = 2
+	// exitViolation signals that the state of the quorum violates safety
+	exitViolation = 3
+)
+
+var (
+	flagOutput string
+
+	// stdout receives results and progress information. Commands that pass their own standard output on to a child
+	// process redirect it to os.Stderr.
+	stdout io.Writer = os.Stdout
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", outputTable, "output format (table, json, or yaml)")
+}
+
+// checkOutput exits if the requested output format is unknown
+func checkOutput() {
+	switch flagOutput {
+	case outputTable, outputJSON, outputYAML:
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown output format: %v\n", flagOutput)
+	os.Exit(exitFailure)
+}
+
+// infof prints progress information meant for humans. It is only printed with table output, so that machine-readable
+// output stays parsable.
+func infof(format string, a ...interface{}) {
+	if flagOutput == outputTable {
+		fmt.Fprintf(stdout, format, a...)
+	}
+}
+
+// printResult prints the result of a command in the requested output format. Table output is left to table. JSON
+// results are printed one per line, YAML results one per document.
+func printResult(result interface{}, table func()) {
+	switch flagOutput {
+	case outputJSON:
+		data, err := json.Marshal(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "encode result: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		fmt.Fprintln(stdout, string(data))
+	case outputYAML:
+		data, err := yaml.Marshal(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "encode result: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		fmt.Fprintf(stdout, "---\n%s", data)
+	default:
+		table()
+	}
+}
+
+// errorResult is the result of a command that failed before it could produce anything else
+type errorResult struct {
+	Error string `json:"error" yaml:"error"`
+}
+
+// fail reports an error in the requested output format and exits with exitFailure
+func fail(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	printResult(errorResult{Error: msg}, func() {
+		fmt.Fprintln(os.Stderr, msg)
+	})
+	os.Exit(exitFailure)
+}
@@ -2,52 +2,77 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
-	"github.com/danrl/skinny/proto/lock"
+	"github.com/danrl/skinny/client"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
 )
 
 func init() {
 	rootCmd.AddCommand(releaseCmd)
-	releaseCmd.PersistentFlags().StringVar(&flagInstance, "instance", "", "name of instance to connect to")
+	releaseCmd.PersistentFlags().StringVar(&flagInstance, "instance", "", "name of instance to try first")
+	releaseCmd.Flags().StringVar(&flagHolder, "holder", "",
+		"only release the lock if it is held by this holder (default: release it whoever holds it)")
+}
+
+// releaseResult is the machine-readable result of the release command
+type releaseResult struct {
+	Instance string   `json:"instance" yaml:"instance"`
+	Address  string   `json:"address" yaml:"address"`
+	Holder   string   `json:"holder" yaml:"holder"`
+	Released bool     `json:"released" yaml:"released"`
+	HeldBy   string   `json:"heldBy" yaml:"heldBy"`
+	Failures []string `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 var releaseCmd = &cobra.Command{
 	Use:   "release",
 	Short: "Release the lock",
-	Run: func(cmd *cobra.Command, args []string) {
-		// select default instance if no one was specified
-		if flagInstance == "" {
-			flagInstance = cfgDefaultInstance
-		}
+	Long: `Release the lock
 
-		// connect to instance
-		address := cfgInstances[flagInstance]
-		fmt.Printf("📡 connecting to %v (%v)\n", flagInstance, address)
-		conn, err := grpc.Dial(address, grpc.WithInsecure())
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "dial: %v\n", err)
-			os.Exit(1)
+Without --holder, the lock is released whoever holds it. With --holder, it is only released if it is held by that
+holder, so that a lock that has changed hands in the meantime is not released by mistake. The exit code is 2 if the
+lock is held by someone else.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := newClient()
+		defer c.Close()
+		result := releaseResult{
+			Holder: flagHolder,
 		}
-		defer conn.Close()
-		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
-		defer cancel()
 
-		// try to acquire lock
-		fmt.Println("🔓 releasing lock")
-		client := lock.NewLockClient(conn)
-		resp, err := client.Release(ctx, &lock.ReleaseRequest{})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
-		if resp.Released {
-			fmt.Println("✅ success")
-		} else {
-			fmt.Println("🚫 failed")
+		// try to release lock, failing over to other instances if necessary
+		infof("🔓 releasing lock\n")
+		served, err := c.Release(context.Background(), result.Holder)
+		result.Failures = failures(served)
+		result.Instance = served.Instance.Name
+		result.Address = served.Instance.Address
+		result.HeldBy = served.Holder
+		result.Released = err == nil
+		switch {
+		case err == nil:
+			printResult(result, func() {
+				fmt.Printf("✅ success (served by %v)\n", result.Instance)
+			})
+		case errors.Is(err, client.ErrNotReleased) && result.Holder != "" && result.HeldBy != "" &&
+			result.HeldBy != result.Holder:
+			printResult(result, func() {
+				fmt.Printf("🚫 failed (held by %v)\n", result.HeldBy)
+			})
+			os.Exit(exitLockHeld)
+		case errors.Is(err, client.ErrNotReleased):
+			printResult(result, func() {
+				fmt.Println("🚫 failed")
+			})
+			os.Exit(exitFailure)
+		default:
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
 		}
 	},
 }
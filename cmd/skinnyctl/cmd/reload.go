@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+func init() {
+	rootCmd.AddCommand(reloadCmd)
+}
+
+// reloadResult is the machine-readable result of the reload command
+type reloadResult struct {
+	Instance string   `json:"instance" yaml:"instance"`
+	Address  string   `json:"address" yaml:"address"`
+	Reloaded bool     `json:"reloaded" yaml:"reloaded"`
+	Changes  []string `json:"changes" yaml:"changes"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var reloadCmd = &cobra.Command{
+	Use:   "reload <instance>",
+	Short: "Reload the configuration of an instance",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		address, ok := cfgInstances[name]
+		if !ok {
+			fail("unknown instance: %v", name)
+		}
+		result := reloadResult{
+			Instance: name,
+			Address:  address,
+			Changes:  []string{},
+		}
+
+		// connect to instance
+		infof("📡 connecting to %v (%v)\n", name, address)
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			fail("dial: %v", err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
+		defer cancel()
+
+		// ask the instance to apply its changed configuration
+		infof("🔄 reloading configuration\n")
+		client := control.NewControlClient(conn)
+		resp, err := client.Reload(ctx, &control.ReloadRequest{})
+		if err != nil {
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
+		}
+		result.Reloaded = true
+		result.Changes = append(result.Changes, resp.Changes...)
+		printResult(result, func() {
+			for _, change := range resp.Changes {
+				fmt.Printf("✏️  %v\n", change)
+			}
+			fmt.Println("✅ success")
+		})
+	},
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/danrl/skinny/skinny"
+	"github.com/spf13/cobra"
+)
+
+var flagReplayLog bool
+
+func init() {
+	replayCmd.Flags().BoolVar(&flagReplayLog, "log", false, "show the log of the replayed instances")
+	rootCmd.AddCommand(replayCmd)
+}
+
+// divergenceResult is the machine-readable form of a record the replay did not reproduce
+type divergenceResult struct {
+	Record   int    `json:"record" yaml:"record"`
+	Type     string `json:"type" yaml:"type"`
+	Recorded string `json:"recorded" yaml:"recorded"`
+	Replayed string `json:"replayed" yaml:"replayed"`
+}
+
+// replayReport is the machine-readable form of the replay of a recorded instance
+type replayReport struct {
+	File         string             `json:"file" yaml:"file"`
+	Instance     string             `json:"instance" yaml:"instance"`
+	Records      int                `json:"records" yaml:"records"`
+	LockRequests int                `json:"lockRequests" yaml:"lockRequests"`
+	Requests     int                `json:"requests" yaml:"requests"`
+	Divergences  []divergenceResult `json:"divergences" yaml:"divergences"`
+}
+
+// replayResult is the machine-readable result of the replay command
+type replayResult struct {
+	Instances  []replayReport `json:"instances" yaml:"instances"`
+	Reproduced bool           `json:"reproduced" yaml:"reproduced"`
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <recording>...",
+	Short: "Replay recorded consensus traffic into fresh instances",
+	Long: `Replay recorded consensus traffic into fresh instances
+
+skinnyd --record writes every Promise and Commit request an instance answers, every lock request it serves, and every
+answer of its peers to a file. A replay feeds the recording into a fresh in-memory instance: It answers the recorded
+requests of peers, serves the recorded lock requests, and hands over the recorded answers of peers in the order they
+arrived. Nothing is sent over the network and no time passes, so a replay runs through the same state transitions
+every time. A recording holds one instance per start of skinnyd, each of them is replayed into an instance of its own.
+
+Wherever the replayed instance answers differently, ends up in a different state, or sends requests the recording has
+no answers for, the replay diverged from the recording. This happens if the recording was tampered with or if the
+protocol changed since. The command exits with a non-zero exit code if the replay diverged.
+
+Examples:
+  skinnyctl replay oregon.rec
+  skinnyctl replay oregon.rec --log
+  skinnyctl replay *.rec -o json`,
+	Args: cobra.MinimumNArgs(1),
+	// a recording is replayed without talking to the quorum
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		checkOutput()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		result := replayResult{
+			Instances:  []replayReport{},
+			Reproduced: true,
+		}
+		// the log is meant for humans, so it only shows with table output
+		var log io.Writer
+		if flagReplayLog && flagOutput == outputTable {
+			log = stdout
+		}
+
+		for _, fname := range args {
+			f, err := os.Open(fname)
+			if err != nil {
+				fail("open recording: %v", err)
+			}
+			records, err := skinny.ReadRecording(f)
+			f.Close()
+			if err != nil {
+				fail("read recording %v: %v", fname, err)
+			}
+
+			infof("⏪ replaying %v records from %v\n", len(records), fname)
+			reports, err := skinny.Replay(records, log)
+			if err != nil {
+				fail("replay %v: %v", fname, err)
+			}
+			for _, r := range reports {
+				report := replayReport{
+					File:         fname,
+					Instance:     r.Instance,
+					Records:      r.Records,
+					LockRequests: r.LockRequests,
+					Requests:     r.Requests,
+					Divergences:  []divergenceResult{},
+				}
+				for _, d := range r.Divergences {
+					report.Divergences = append(report.Divergences, divergenceResult{
+						Record:   d.Record,
+						Type:     d.Type,
+						Recorded: d.Recorded,
+						Replayed: d.Replayed,
+					})
+				}
+				if len(report.Divergences) > 0 {
+					result.Reproduced = false
+				}
+				result.Instances = append(result.Instances, report)
+			}
+		}
+
+		printResult(result, func() {
+			for _, r := range result.Instances {
+				summary := fmt.Sprintf("%v (%v): %v records, %v lock requests, %v requests of peers",
+					r.Instance, r.File, r.Records, r.LockRequests, r.Requests)
+				if len(r.Divergences) == 0 {
+					fmt.Printf("✅ %v reproduced\n", summary)
+					continue
+				}
+				fmt.Printf("🚨 %v, %v divergences:\n", summary, len(r.Divergences))
+				tw := tabwriter.NewWriter(os.Stdout, 5, 4, 3, ' ', 0)
+				fmt.Fprintln(tw, "RECORD\tTYPE\tRECORDED\tREPLAYED")
+				for _, d := range r.Divergences {
+					fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", d.Record, d.Type, d.Recorded, d.Replayed)
+				}
+				tw.Flush()
+			}
+		})
+		if !result.Reproduced {
+			os.Exit(exitFailure)
+		}
+	},
+}
@@ -28,11 +28,13 @@ var rootCmd = &cobra.Command{
 	Use: "skinnyctl",
 	//	Short: "Skinnyctl is a control tool for Skinny instances",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		checkOutput()
+		checkFailover()
+
 		// load the qorum configuration from file
 		cfgQuorum, err = config.NewQuorumConfig(flagConfigFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "load config: %v\n", err)
-			os.Exit(1)
+			fail("load config: %v", err)
 		}
 
 		// create a hashmap for easier access to instance addresses by name
@@ -50,6 +52,6 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 }
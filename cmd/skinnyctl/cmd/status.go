@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -11,18 +12,147 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/danrl/skinny/config"
 	"github.com/danrl/skinny/proto/control"
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 )
 
+// ANSI sequences to highlight changes. Both have the same length, so that tabwriter aligns columns correctly.
+const (
+	ansiHighlight = "\033[1;33m"
+	ansiPlain     = "\033[0;39m"
+	ansiReset     = "\033[0m"
+)
+
+// highlightFor is how long a changed value stays highlighted
+const highlightFor = 2 * time.Second
+
+// symbols of vote outcomes in the wide view
+var voteSymbols = map[string]string{
+	"yea":    "✓",
+	"nay":    "✗",
+	"failed": "!",
+}
+
 var (
 	flagWatch bool
+	flagWide  bool
 )
 
+// voteStatus is the machine-readable vote of a peer
+type voteStatus struct {
+	Phase   string `json:"phase" yaml:"phase"`
+	ID      uint64 `json:"id" yaml:"id"`
+	Outcome string `json:"outcome" yaml:"outcome"`
+}
+
+// peerStatus is the machine-readable status of an instance's peer
+type peerStatus struct {
+	Name        string       `json:"name" yaml:"name"`
+	Reachable   bool         `json:"reachable" yaml:"reachable"`
+	Connection  string       `json:"connection" yaml:"connection"`
+	LastSuccess *time.Time   `json:"lastSuccess,omitempty" yaml:"lastSuccess,omitempty"`
+	RTT         string       `json:"rtt" yaml:"rtt"`
+	Votes       []voteStatus `json:"votes" yaml:"votes"`
+}
+
+// instanceStatus is the machine-readable status of a single instance
+type instanceStatus struct {
+	Name      string        `json:"name" yaml:"name"`
+	Address   string        `json:"address" yaml:"address"`
+	Reachable bool          `json:"reachable" yaml:"reachable"`
+	Increment uint64        `json:"increment" yaml:"increment"`
+	Promised  uint64        `json:"promised" yaml:"promised"`
+	ID        uint64        `json:"id" yaml:"id"`
+	Holder    string        `json:"holder" yaml:"holder"`
+	Draining  bool          `json:"draining" yaml:"draining"`
+	Role      string        `json:"role" yaml:"role"`
+	Uptime    string        `json:"uptime" yaml:"uptime"`
+	Version   string        `json:"version" yaml:"version"`
+	Storage   string        `json:"storage" yaml:"storage"`
+	Peers     []peerStatus  `json:"peers" yaml:"peers"`
+	Faults    []faultStatus `json:"faults" yaml:"faults"`
+	LastSeen  *time.Time    `json:"lastSeen,omitempty" yaml:"lastSeen,omitempty"`
+	Error     string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// newInstanceStatus converts a status response into an instance status. A nil response marks an unreachable instance.
+func newInstanceStatus(in config.Instance, resp *control.StatusResponse, err error, seen time.Time) instanceStatus {
+	status := instanceStatus{
+		Name:    in.Name,
+		Address: in.Address,
+		Peers:   []peerStatus{},
+		Faults:  []faultStatus{},
+	}
+	if resp == nil {
+		if err != nil {
+			status.Error = err.Error()
+		}
+		return status
+	}
+	status.Reachable = true
+	status.Increment = resp.Increment
+	status.Promised = resp.Promised
+	status.ID = resp.ID
+	status.Holder = resp.Holder
+	status.Draining = resp.Draining
+	status.Role = resp.Role
+	status.Uptime = resp.Uptime
+	status.Version = resp.Version
+	status.Storage = resp.Storage
+	status.Faults = newFaultStatus(resp.Faults)
+	for _, peer := range resp.Peers {
+		p := peerStatus{
+			Name:       peer.Name,
+			Reachable:  peer.Reachable,
+			Connection: peer.Connection,
+			RTT:        peer.RTT,
+			Votes:      []voteStatus{},
+		}
+		if t, err := time.Parse(time.RFC3339Nano, peer.LastSuccess); err == nil {
+			p.LastSuccess = &t
+		}
+		for _, v := range peer.Votes {
+			p.Votes = append(p.Votes, voteStatus{Phase: v.Phase, ID: v.ID, Outcome: v.Outcome})
+		}
+		status.Peers = append(status.Peers, p)
+	}
+	if !seen.IsZero() {
+		status.LastSeen = &seen
+	}
+	return status
+}
+
+// reachablePeers returns how many peers of an instance are reachable, e.g. "2/4"
+func (s instanceStatus) reachablePeers() string {
+	reachable := 0
+	for _, peer := range s.Peers {
+		if peer.Reachable {
+			reachable++
+		}
+	}
+	return fmt.Sprintf("%v/%v", reachable, len(s.Peers))
+}
+
+// votes returns the outcomes of a peer's recent votes, oldest first
+func (p peerStatus) votes() string {
+	votes := ""
+	for _, v := range p.Votes {
+		votes += voteSymbols[v.Outcome]
+	}
+	return votes
+}
+
+// statusResult is the machine-readable result of the status command
+type statusResult struct {
+	Instances []instanceStatus `json:"instances" yaml:"instances"`
+}
+
 func init() {
 	statusCmd.PersistentFlags().BoolVar(&flagWatch, "watch", false, "watch status report")
+	statusCmd.PersistentFlags().BoolVar(&flagWide, "wide", false, "show details of instances and their peers")
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -30,102 +160,270 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Fetch status of a quorum of Skinny instances",
 	Run: func(cmd *cobra.Command, args []string) {
-		type report struct {
-			name      string
-			resp      *control.StatusResponse
-			timestamp time.Time
+		if flagWatch {
+			watchStatus()
+			return
 		}
 
-		done := make(chan struct{})
-		if flagWatch {
-			sc := make(chan os.Signal)
-			signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-			go func() {
-				<-sc
-				close(done)
-			}()
-		} else {
-			close(done)
+		c := newClient()
+		defer c.Close()
+		result := statusResult{Instances: []instanceStatus{}}
+		for _, s := range c.Status(context.Background()) {
+			result.Instances = append(result.Instances, newInstanceStatus(s.Instance, s.Status, s.Err, time.Now()))
+		}
+		printResult(result, func() {
+			printStatus(os.Stdout, result, nil, flagWide)
+		})
+		for _, in := range result.Instances {
+			if !in.Reachable {
+				os.Exit(exitFailure)
+			}
+		}
+	},
+}
+
+// printStatus prints the status of all instances as a table. If changed is not nil, the table is meant for a terminal
+// and values that changed recently are highlighted. The wide view adds details of every instance and its peers.
+func printStatus(w io.Writer, result statusResult, changed map[string]map[string]time.Time, wide bool) {
+	cell := func(v interface{}) string {
+		if changed == nil {
+			return fmt.Sprint(v)
+		}
+		return ansiPlain + fmt.Sprint(v) + ansiReset
+	}
+	changedCell := func(name, field string, v interface{}) string {
+		if changed == nil || time.Since(changed[name][field]) > highlightFor {
+			return cell(v)
 		}
+		return ansiHighlight + fmt.Sprint(v) + ansiReset
+	}
 
-		wg := sync.WaitGroup{}
+	tw := tabwriter.NewWriter(w, 5, 4, 3, ' ', 0)
+	row := func(cells ...string) {
+		for i, c := range cells {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, c)
+		}
+		fmt.Fprintln(tw)
+	}
 
-		reports := make(chan *report)
-		for _, in := range cfgQuorum.Instances {
-			wg.Add(1)
-			go func(name, address string) {
-				defer wg.Done()
+	header := []string{cell("NAME"), cell("INCREMENT"), cell("PROMISED"), cell("ID"), cell("HOLDER"), cell("PEERS"),
+		cell("LAST SEEN")}
+	if wide {
+		header = append(header, cell("ROLE"), cell("UPTIME"), cell("VERSION"), cell("STORAGE"))
+	}
+	row(header...)
+	for _, in := range result.Instances {
+		if !in.Reachable {
+			cells := []string{cell(in.Name), cell(""), cell(""), cell(""), cell(""), cell(""), cell("connection error")}
+			if wide {
+				cells = append(cells, cell(""), cell(""), cell(""), cell(""))
+			}
+			row(cells...)
+			continue
+		}
+		lastSeen := ""
+		if in.LastSeen != nil {
+			lastSeen = humanize.Time(*in.LastSeen)
+		}
+		cells := []string{
+			cell(in.Name),
+			cell(in.Increment),
+			changedCell(in.Name, "promised", in.Promised),
+			changedCell(in.Name, "id", in.ID),
+			changedCell(in.Name, "holder", in.Holder),
+			changedCell(in.Name, "peers", in.reachablePeers()),
+			cell(lastSeen),
+		}
+		if wide {
+			cells = append(cells, changedCell(in.Name, "role", in.Role), cell(in.Uptime), cell(in.Version),
+				cell(in.Storage))
+		}
+		row(cells...)
+	}
+	tw.Flush()
 
-				// kick-off drawing by sending an empty report at startup
-				reports <- &report{name: name}
+	// injected faults would not fit into the table, but must not go unnoticed
+	for _, in := range result.Instances {
+		for _, f := range in.Faults {
+			fmt.Fprintf(w, "💥 %v: %v\n", in.Name, f)
+		}
+	}
+	if !wide {
+		return
+	}
+
+	// details of every instance's peers
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 5, 4, 3, ' ', 0)
+	row(cell("INSTANCE"), cell("PEER"), cell("CONNECTION"), cell("LAST SUCCESS"), cell("RTT"), cell("VOTES"))
+	for _, in := range result.Instances {
+		for _, peer := range in.Peers {
+			lastSuccess := "never"
+			if peer.LastSuccess != nil {
+				lastSuccess = humanize.Time(*peer.LastSuccess)
+			}
+			connection := peer.Connection
+			if connection == "" {
+				connection = "unknown"
+			}
+			row(cell(in.Name), cell(peer.Name), changedCell(in.Name, "connection "+peer.Name, connection),
+				cell(lastSuccess), cell(peer.RTT), changedCell(in.Name, "votes "+peer.Name, peer.votes()))
+		}
+	}
+	tw.Flush()
+}
+
+// watchStatus streams the status of every instance and prints it on every change until interrupted
+func watchStatus() {
+	type report struct {
+		name string
+		resp *control.StatusResponse
+		err  error
+		seen time.Time
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	go func() {
+		<-sc
+		cancel()
+	}()
+
+	wg := sync.WaitGroup{}
+	reports := make(chan *report)
+	for _, in := range cfgQuorum.Instances {
+		wg.Add(1)
+		go func(name, address string) {
+			defer wg.Done()
+
+			conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBackoffMaxDelay(5*time.Second))
+			if err != nil {
+				reports <- &report{name: name, err: err}
+				return
+			}
+			defer conn.Close()
+			client := control.NewControlClient(conn)
 
-				// connect to instance
-				conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBackoffMaxDelay(5*time.Second))
-				if err != nil {
+			// stream status updates, reconnecting if the stream breaks
+			for {
+				stream, err := client.WatchStatus(ctx, &control.WatchStatusRequest{})
+				for err == nil {
+					var resp *control.StatusResponse
+					resp, err = stream.Recv()
+					if err == nil {
+						reports <- &report{name: name, resp: resp, seen: time.Now()}
+					}
+				}
+				if ctx.Err() != nil {
 					return
 				}
-				defer conn.Close()
-				client := control.NewControlClient(conn)
-
-				// regularly poll for status updates
-				for {
-					ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
-					resp, err := client.Status(ctx, &control.StatusRequest{})
-					if err != nil {
-						resp = nil
-					}
-					reports <- &report{
-						name:      name,
-						resp:      resp,
-						timestamp: time.Now(),
-					}
-					cancel()
+				reports <- &report{name: name, err: err}
 
-					select {
-					case <-time.After(2 * time.Second):
-						continue
-					case <-done:
-						return
-					}
+				select {
+				case <-time.After(2 * time.Second):
+				case <-ctx.Done():
+					return
 				}
-			}(in.Name, in.Address)
-		}
+			}
+		}(in.Name, in.Address)
+	}
 
-		// close reports channel once we are done
-		go func() {
-			wg.Wait()
-			close(reports)
-		}()
+	// close reports channel once we are done
+	go func() {
+		wg.Wait()
+		close(reports)
+	}()
 
-		db := make(map[string]*report)
-		bw := bufio.NewWriter(os.Stdout)
+	// store reports in "database"
+	db := make(map[string]*report)
+	changed := make(map[string]map[string]time.Time)
+	snapshot := func() statusResult {
+		result := statusResult{Instances: []instanceStatus{}}
+		for _, in := range cfgQuorum.Instances {
+			r, ok := db[in.Name]
+			if !ok {
+				result.Instances = append(result.Instances, newInstanceStatus(in, nil, nil, time.Time{}))
+				continue
+			}
+			result.Instances = append(result.Instances, newInstanceStatus(in, r.resp, r.err, r.seen))
+		}
+		return result
+	}
+	store := func(r *report) {
+		prev, ok := db[r.name]
+		if r.resp == nil && ok && prev.resp != nil {
+			// keep showing when we last saw the instance
+			r.seen = prev.seen
+		}
+		db[r.name] = r
+		if !ok || prev.resp == nil || r.resp == nil {
+			return
+		}
+		if changed[r.name] == nil {
+			changed[r.name] = make(map[string]time.Time)
+		}
+		before := newInstanceStatus(config.Instance{}, prev.resp, nil, time.Time{})
+		after := newInstanceStatus(config.Instance{}, r.resp, nil, time.Time{})
+		if before.Promised != after.Promised {
+			changed[r.name]["promised"] = r.seen
+		}
+		if before.ID != after.ID {
+			changed[r.name]["id"] = r.seen
+		}
+		if before.Holder != after.Holder {
+			changed[r.name]["holder"] = r.seen
+		}
+		if before.reachablePeers() != after.reachablePeers() {
+			changed[r.name]["peers"] = r.seen
+		}
+		if before.Role != after.Role {
+			changed[r.name]["role"] = r.seen
+		}
+		for i, peer := range after.Peers {
+			if i >= len(before.Peers) {
+				break
+			}
+			if before.Peers[i].Connection != peer.Connection {
+				changed[r.name]["connection "+peer.Name] = r.seen
+			}
+			if fmt.Sprint(before.Peers[i].Votes) != fmt.Sprint(peer.Votes) {
+				changed[r.name]["votes "+peer.Name] = r.seen
+			}
+		}
+	}
+
+	// machine-readable output is printed on every update once all instances have reported
+	if flagOutput != outputTable {
 		for r := range reports {
-			// store report in "database"
-			db[r.name] = r
-
-			// reset cursor via ANSI sequence, ignoring errors ¯\_(ツ)_/¯
-			_, _ = bw.WriteString("\033[2J\033[0;0H")
-
-			// print nicely formatted instance status
-			tw := tabwriter.NewWriter(bw, 5, 4, 3, ' ', 0)
-			fmt.Fprintln(tw, "NAME\tINCREMENT\tPROMISED\tID\tHOLDER\tLAST SEEN")
-			for _, in := range cfgQuorum.Instances {
-				status, ok := db[in.Name]
-				if !ok || status.resp == nil {
-					fmt.Fprintf(tw, "%v\t\t\t\t\tconnection error\n", in.Name)
-					continue
-				}
-				fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n",
-					in.Name,
-					status.resp.Increment,
-					status.resp.Promised,
-					status.resp.ID,
-					status.resp.Holder,
-					humanize.Time(status.timestamp))
+			store(r)
+			if len(db) == len(cfgQuorum.Instances) {
+				printResult(snapshot(), nil)
 			}
-			tw.Flush()
-			bw.Flush()
-			bw.Reset(os.Stdout)
 		}
-	},
+		return
+	}
+
+	// redraw on every update, and regularly to fade out highlights
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	bw := bufio.NewWriter(os.Stdout)
+	for {
+		select {
+		case r, ok := <-reports:
+			if !ok {
+				return
+			}
+			store(r)
+		case <-ticker.C:
+		}
+
+		// reset cursor via ANSI sequence, ignoring errors ¯\_(ツ)_/¯
+		_, _ = bw.WriteString("\033[2J\033[0;0H")
+		printStatus(bw, snapshot(), changed, flagWide)
+		bw.Flush()
+	}
 }
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	flagStepEnable   bool
+	flagStepDisable  bool
+	flagStepDeliver  bool
+	flagStepDrop     bool
+	flagStepDelay    time.Duration
+	flagStepMessages []uint
+)
+
+func init() {
+	stepCmd.Flags().BoolVar(&flagStepEnable, "enable", false, "turn step mode on")
+	stepCmd.Flags().BoolVar(&flagStepDisable, "disable", false, "turn step mode off and deliver all held messages")
+	stepCmd.Flags().BoolVar(&flagStepDeliver, "deliver", false, "deliver held messages")
+	stepCmd.Flags().BoolVar(&flagStepDrop, "drop", false, "drop held messages, they time out at the instance")
+	stepCmd.Flags().DurationVar(&flagStepDelay, "delay", 0, "deliver held messages after a delay")
+	stepCmd.Flags().UintSliceVar(&flagStepMessages, "message", nil, "message to act on, repeat for more messages (default all held messages)")
+	rootCmd.AddCommand(stepCmd)
+}
+
+// heldMessage is the machine-readable state of a message held back in step mode
+type heldMessage struct {
+	Seq    uint64 `json:"seq" yaml:"seq"`
+	Phase  string `json:"phase" yaml:"phase"`
+	To     string `json:"to" yaml:"to"`
+	ID     uint64 `json:"id" yaml:"id"`
+	Holder string `json:"holder,omitempty" yaml:"holder,omitempty"`
+	Held   string `json:"held" yaml:"held"`
+}
+
+// newHeldMessages converts held messages as found in responses
+func newHeldMessages(messages []*control.HeldMessage) []heldMessage {
+	held := []heldMessage{}
+	for _, m := range messages {
+		held = append(held, heldMessage{
+			Seq:    m.Seq,
+			Phase:  m.Phase,
+			To:     m.To,
+			ID:     m.ID,
+			Holder: m.Holder,
+			Held:   m.Held,
+		})
+	}
+	return held
+}
+
+// String describes the message, e.g. "#3 promise ID 4 to oregon"
+func (m heldMessage) String() string {
+	if m.Phase == "commit" {
+		return fmt.Sprintf("#%v commit ID %v and holder `%v` to %v", m.Seq, m.ID, m.Holder, m.To)
+	}
+	return fmt.Sprintf("#%v %v ID %v to %v", m.Seq, m.Phase, m.ID, m.To)
+}
+
+// stepResult is the machine-readable result of the step command
+type stepResult struct {
+	Instance string        `json:"instance" yaml:"instance"`
+	Address  string        `json:"address" yaml:"address"`
+	Enabled  bool          `json:"enabled" yaml:"enabled"`
+	Action   string        `json:"action,omitempty" yaml:"action,omitempty"`
+	Stepped  []heldMessage `json:"stepped" yaml:"stepped"`
+	Held     []heldMessage `json:"held" yaml:"held"`
+	Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var stepCmd = &cobra.Command{
+	Use:   "step <instance>",
+	Short: "Step through the consensus rounds of an instance message by message",
+	Long: `Step through the consensus rounds of an instance message by message
+
+In step mode, every Promise and Commit request the instance sends to a peer is held back until it is delivered,
+delayed, or dropped here. Held messages wait as long as it takes, so there is time to predict what happens next. Once
+delivered, a message times out after the instance's timeout as usual. A dropped message is lost, the instance gives up
+on it once it timed out. Without any action flags, the messages currently held back are shown.
+
+The instance cannot answer other requests while it waits, not even status requests. Answers of peers are not held
+back.
+
+Examples:
+  skinnyctl step oregon --enable
+  skinnyctl step oregon
+  skinnyctl step oregon --deliver --message 1
+  skinnyctl step oregon --drop --message 2 --message 3
+  skinnyctl step oregon --delay 2s
+  skinnyctl step oregon --disable`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		address, ok := cfgInstances[name]
+		if !ok {
+			fail("unknown instance: %v", name)
+		}
+		result := stepResult{
+			Instance: name,
+			Address:  address,
+			Stepped:  []heldMessage{},
+			Held:     []heldMessage{},
+		}
+
+		req := control.StepRequest{
+			Enable:  flagStepEnable,
+			Disable: flagStepDisable,
+		}
+		actions := 0
+		if flagStepDeliver {
+			req.Action = "deliver"
+			actions++
+		}
+		if flagStepDrop {
+			req.Action = "drop"
+			actions++
+		}
+		if cmd.Flags().Changed("delay") {
+			req.Action = "delay"
+			req.Delay = flagStepDelay.String()
+			actions++
+		}
+		if actions > 1 {
+			fail("choose one of --deliver, --drop, or --delay")
+		}
+		if len(flagStepMessages) > 0 && actions == 0 {
+			fail("--message requires one of --deliver, --drop, or --delay")
+		}
+		for _, seq := range flagStepMessages {
+			req.Messages = append(req.Messages, uint64(seq))
+		}
+		result.Action = req.Action
+
+		// connect to instance
+		infof("📡 connecting to %v (%v)\n", name, address)
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			fail("dial: %v", err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), cfgQuorum.Timeout)
+		defer cancel()
+
+		client := control.NewControlClient(conn)
+		resp, err := client.Step(ctx, &req)
+		if err != nil {
+			result.Error = err.Error()
+			printResult(result, func() {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			})
+			os.Exit(exitFailure)
+		}
+		result.Enabled = resp.Enabled
+		result.Stepped = newHeldMessages(resp.Stepped)
+		result.Held = newHeldMessages(resp.Held)
+
+		printResult(result, func() {
+			for _, m := range result.Stepped {
+				switch result.Action {
+				case "drop":
+					fmt.Printf("🗑️  dropped %v\n", m)
+				case "delay":
+					fmt.Printf("🐌 delayed %v by %v\n", m, flagStepDelay)
+				default:
+					fmt.Printf("📨 delivered %v\n", m)
+				}
+			}
+			if !result.Enabled {
+				fmt.Println("▶️  step mode off")
+				return
+			}
+			fmt.Println("⏸️  step mode on")
+			if len(result.Held) == 0 {
+				fmt.Println("💤 no messages held")
+				return
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 5, 4, 3, ' ', 0)
+			fmt.Fprintln(tw, "MESSAGE\tPHASE\tTO\tID\tHOLDER\tHELD")
+			for _, m := range result.Held {
+				fmt.Fprintf(tw, "#%v\t%v\t%v\t%v\t%v\t%v\n", m.Seq, m.Phase, m.To, m.ID, m.Holder, m.Held)
+			}
+			tw.Flush()
+		})
+	},
+}
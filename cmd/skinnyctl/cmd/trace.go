@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danrl/skinny/client"
+	"github.com/spf13/cobra"
+)
+
+// diagram formats
+const (
+	formatMermaid  = "mermaid"
+	formatPlantUML = "plantuml"
+)
+
+var (
+	flagTraceFormat string
+	flagTraceRounds int
+	flagTraceFile   string
+)
+
+func init() {
+	traceCmd.Flags().StringVar(&flagTraceFormat, "format", formatMermaid, "diagram format (mermaid or plantuml)")
+	traceCmd.Flags().IntVar(&flagTraceRounds, "rounds", 1, "number of most recent rounds to show, 0 for all")
+	traceCmd.Flags().StringVar(&flagTraceFile, "file", "", "file to write the diagram to instead of stdout")
+	rootCmd.AddCommand(traceCmd)
+}
+
+// messageResult is the machine-readable form of a consensus message
+type messageResult struct {
+	Phase          string `json:"phase" yaml:"phase"`
+	From           string `json:"from" yaml:"from"`
+	To             string `json:"to" yaml:"to"`
+	ID             uint64 `json:"id" yaml:"id"`
+	Holder         string `json:"holder,omitempty" yaml:"holder,omitempty"`
+	AttachedID     uint64 `json:"attachedID,omitempty" yaml:"attachedID,omitempty"`
+	AttachedHolder string `json:"attachedHolder,omitempty" yaml:"attachedHolder,omitempty"`
+	Outcome        string `json:"outcome" yaml:"outcome"`
+	Error          string `json:"error,omitempty" yaml:"error,omitempty"`
+	Sent           string `json:"sent" yaml:"sent"`
+	RTT            string `json:"rtt" yaml:"rtt"`
+}
+
+// roundResult is the machine-readable form of a round
+type roundResult struct {
+	Proposer string          `json:"proposer" yaml:"proposer"`
+	ID       uint64          `json:"id" yaml:"id"`
+	Messages []messageResult `json:"messages" yaml:"messages"`
+}
+
+// traceResult is the machine-readable result of the trace command
+type traceResult struct {
+	Instances []string          `json:"instances" yaml:"instances"`
+	Rounds    []roundResult     `json:"rounds" yaml:"rounds"`
+	Errors    map[string]string `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Diagram   string            `json:"diagram" yaml:"diagram"`
+	File      string            `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+var traceCmd = &cobra.Command{
+	Use:   "trace",
+	Short: "Draw recent consensus rounds as a sequence diagram",
+	Long: `Draw recent consensus rounds as a sequence diagram
+
+Every instance remembers the Promise and Commit requests it recently sent while proposing, and how its peers answered.
+The requests of all instances are collected and drawn as a sequence diagram in Mermaid or PlantUML syntax, one section
+per round. Lost answers, e.g. failed requests or requests canceled once the proposer had a majority, end in a cross.
+
+Requests are ordered by the clocks of the instances that sent them, so rounds of different proposers may appear
+slightly out of order if the clocks are not in sync.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch flagTraceFormat {
+		case formatMermaid, formatPlantUML:
+		default:
+			fail("unknown diagram format: %v", flagTraceFormat)
+		}
+
+		c := newClient()
+		defer c.Close()
+		trace := c.Trace(context.Background())
+		if len(trace.Errs) == len(trace.Instances) {
+			fail("no instance could be asked for its messages")
+		}
+
+		rounds := trace.Rounds
+		if flagTraceRounds > 0 && len(rounds) > flagTraceRounds {
+			rounds = rounds[len(rounds)-flagTraceRounds:]
+		}
+		result := traceResult{
+			Instances: trace.Instances,
+			Rounds:    []roundResult{},
+			Diagram:   diagram(flagTraceFormat, trace.Instances, rounds),
+		}
+		for _, r := range rounds {
+			result.Rounds = append(result.Rounds, newRoundResult(r))
+		}
+		if len(trace.Errs) > 0 {
+			result.Errors = make(map[string]string)
+			for name, err := range trace.Errs {
+				result.Errors[name] = err.Error()
+			}
+		}
+
+		if flagTraceFile != "" {
+			if err := ioutil.WriteFile(flagTraceFile, []byte(result.Diagram), 0644); err != nil {
+				fail("write diagram: %v", err)
+			}
+			result.File = flagTraceFile
+		}
+
+		printResult(result, func() {
+			// the diagram goes to stdout as is, so that it can be piped into a renderer
+			for _, name := range trace.Instances {
+				if err, ok := trace.Errs[name]; ok {
+					fmt.Fprintf(os.Stderr, "⚠️  %v: %v\n", name, err)
+				}
+			}
+			if result.File == "" {
+				fmt.Print(result.Diagram)
+				return
+			}
+			fmt.Printf("📝 wrote %v round(s) to %v\n", len(result.Rounds), result.File)
+		})
+	},
+}
+
+// newRoundResult converts a round into its machine-readable form
+func newRoundResult(r client.Round) roundResult {
+	result := roundResult{
+		Proposer: r.Proposer,
+		ID:       r.ID,
+		Messages: []messageResult{},
+	}
+	for _, m := range r.Messages {
+		msg := messageResult{
+			Phase:          m.Phase,
+			From:           m.From,
+			To:             m.To,
+			ID:             m.ID,
+			Holder:         m.Holder,
+			AttachedID:     m.AttachedID,
+			AttachedHolder: m.AttachedHolder,
+			Outcome:        m.Outcome,
+			Sent:           m.Sent.Format(time.RFC3339Nano),
+			RTT:            m.RTT.String(),
+		}
+		if m.Err != nil {
+			msg.Error = m.Err.Error()
+		}
+		result.Messages = append(result.Messages, msg)
+	}
+	return result
+}
+
+// syntax is how a diagram format writes the parts of a sequence diagram
+type syntax struct {
+	header      string
+	footer      string
+	participant string
+	// section takes the first and the last participant and the title
+	section string
+	// arrows take the sender, the receiver, and the text
+	request string
+	reply   string
+	lost    string
+	escape  func(string) string
+}
+
+var syntaxes = map[string]syntax{
+	formatMermaid: {
+		header:      "sequenceDiagram\n",
+		participant: "    participant %v as %v\n",
+		section:     "    Note over %v,%v: %v\n",
+		request:     "    %v->>%v: %v\n",
+		reply:       "    %v-->>%v: %v\n",
+		lost:        "    %v--x%v: %v\n",
+		// semicolons end statements in Mermaid
+		escape: strings.NewReplacer(";", "#59;", "\n", " ").Replace,
+	},
+	formatPlantUML: {
+		header:      "@startuml\n",
+		footer:      "@enduml\n",
+		participant: "participant \"%[2]v\" as %[1]v\n",
+		section:     "== %[3]v ==\n",
+		request:     "%v -> %v : %v\n",
+		reply:       "%v --> %v : %v\n",
+		lost:        "%v -->x %v : %v\n",
+		escape:      strings.NewReplacer("\n", " ").Replace,
+	},
+}
+
+// event is a request or an answer in a diagram
+type event struct {
+	at    time.Time
+	reply bool
+	m     client.Message
+}
+
+// diagram draws rounds as a sequence diagram spanning the given instances and every peer the rounds talked to
+func diagram(format string, instances []string, rounds []client.Round) string {
+	s := syntaxes[format]
+	b := strings.Builder{}
+	b.WriteString(s.header)
+
+	// participants get aliases, because instance names may contain characters that have a meaning in the syntax
+	aliases := make(map[string]string)
+	names := []string{}
+	participant := func(name string) {
+		if _, ok := aliases[name]; ok {
+			return
+		}
+		aliases[name] = fmt.Sprintf("P%v", len(names)+1)
+		names = append(names, name)
+	}
+	for _, name := range instances {
+		participant(name)
+	}
+	for _, r := range rounds {
+		for _, m := range r.Messages {
+			participant(m.From)
+			participant(m.To)
+		}
+	}
+	for _, name := range names {
+		fmt.Fprintf(&b, s.participant, aliases[name], s.escape(name))
+	}
+	if len(names) == 0 {
+		b.WriteString(s.footer)
+		return b.String()
+	}
+	first, last := aliases[names[0]], aliases[names[len(names)-1]]
+
+	for _, r := range rounds {
+		fmt.Fprintf(&b, s.section, first, last, s.escape(fmt.Sprintf("%v proposes ID %v", r.Proposer, r.ID)))
+
+		events := []event{}
+		for _, m := range r.Messages {
+			events = append(events, event{at: m.Sent, m: m}, event{at: m.Sent.Add(m.RTT), reply: true, m: m})
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].at.Before(events[j].at)
+		})
+		for _, e := range events {
+			from, to := aliases[e.m.From], aliases[e.m.To]
+			switch {
+			case !e.reply:
+				fmt.Fprintf(&b, s.request, from, to, s.escape(requestText(e.m)))
+			case e.m.Outcome == "failed" || e.m.Outcome == "canceled":
+				fmt.Fprintf(&b, s.lost, to, from, s.escape(replyText(e.m)))
+			default:
+				fmt.Fprintf(&b, s.reply, to, from, s.escape(replyText(e.m)))
+			}
+		}
+	}
+
+	b.WriteString(s.footer)
+	return b.String()
+}
+
+// requestText describes a request
+func requestText(m client.Message) string {
+	if m.Phase == "commit" {
+		if m.Holder == "" {
+			return fmt.Sprintf("Commit(ID %v, no holder)", m.ID)
+		}
+		return fmt.Sprintf("Commit(ID %v, holder %v)", m.ID, m.Holder)
+	}
+	return fmt.Sprintf("Promise(ID %v)", m.ID)
+}
+
+// replyText describes the answer to a request
+func replyText(m client.Message) string {
+	text := ""
+	switch {
+	case m.Outcome == "failed":
+		text = fmt.Sprintf("failed: %v", shortError(m.Err))
+	case m.Outcome == "canceled":
+		text = "canceled"
+	case m.Phase == "commit" && m.Outcome == "yea":
+		text = "committed"
+	case m.Phase == "commit":
+		text = "not committed"
+	case m.Outcome == "yea":
+		text = "promised"
+	default:
+		text = "not promised"
+	}
+	if m.AttachedID > 0 {
+		holder := m.AttachedHolder
+		if holder == "" {
+			holder = "none"
+		}
+		text += fmt.Sprintf(", attached ID %v and holder %v", m.AttachedID, holder)
+	}
+	if m.Outcome != "canceled" {
+		text += fmt.Sprintf(" (%v)", m.RTT.Round(time.Microsecond))
+	}
+	return text
+}
+
+// shortError returns the gRPC code of an error if there is one, full gRPC errors are too long for a diagram
+func shortError(err error) string {
+	if err == nil {
+		return "unknown error"
+	}
+	msg := err.Error()
+	const prefix, suffix = "rpc error: code = ", " desc = "
+	if strings.HasPrefix(msg, prefix) {
+		if i := strings.Index(msg, suffix); i > 0 {
+			return msg[len(prefix):i]
+		}
+	}
+	return msg
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danrl/skinny/client"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// verifyResult is the machine-readable result of the verify command
+type verifyResult struct {
+	Violations []string         `json:"violations" yaml:"violations"`
+	Warnings   []string         `json:"warnings" yaml:"warnings"`
+	Majority   bool             `json:"majority" yaml:"majority"`
+	Agreeing   int              `json:"agreeing" yaml:"agreeing"`
+	Size       int              `json:"size" yaml:"size"`
+	ID         uint64           `json:"id" yaml:"id"`
+	Holder     string           `json:"holder" yaml:"holder"`
+	Instances  []instanceStatus `json:"instances" yaml:"instances"`
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the state of a quorum of Skinny instances for consistency",
+	Run: func(cmd *cobra.Command, args []string) {
+		c := newClient()
+		defer c.Close()
+
+		infof("🔍 verifying quorum of %v instances\n", len(cfgQuorum.Instances))
+		statuses := c.Status(context.Background())
+		report := client.Verify(statuses)
+
+		result := verifyResult{
+			Violations: []string{},
+			Warnings:   []string{},
+			Majority:   report.Majority,
+			Agreeing:   report.Agreeing,
+			Size:       report.Size,
+			ID:         report.ID,
+			Holder:     report.Holder,
+			Instances:  []instanceStatus{},
+		}
+		reachable := 0
+		for _, s := range statuses {
+			if s.Status != nil {
+				reachable++
+			}
+			result.Instances = append(result.Instances, newInstanceStatus(s.Instance, s.Status, s.Err, time.Time{}))
+		}
+		for _, violation := range report.Violations {
+			result.Violations = append(result.Violations, violation.Error())
+		}
+		for _, warning := range report.Warnings {
+			result.Warnings = append(result.Warnings, warning.Error())
+		}
+
+		printResult(result, func() {
+			for _, violation := range result.Violations {
+				fmt.Printf("🚨 %v\n", violation)
+			}
+			for _, warning := range result.Warnings {
+				fmt.Printf("⚠️  %v\n", warning)
+			}
+			if result.Majority {
+				fmt.Printf("🤝 %v of %v instances agree on ID %v and holder `%v`\n",
+					result.Agreeing, result.Size, result.ID, result.Holder)
+			} else {
+				fmt.Printf("💔 no majority, at most %v of %v instances agree\n", result.Agreeing, result.Size)
+			}
+			if len(result.Violations) > 0 {
+				fmt.Printf("🚫 found %v violation(s)\n", len(result.Violations))
+				return
+			}
+			fmt.Println("✅ no violations found")
+		})
+
+		switch {
+		case !report.OK():
+			os.Exit(exitViolation)
+		case reachable == 0:
+			os.Exit(exitFailure)
+		}
+	},
+}
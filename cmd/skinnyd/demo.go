@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/skinny"
+)
+
+// demoNames are the names of the instances of a demo quorum, the same as in the examples. Further instances are
+// numbered.
+var demoNames = []string{"london", "oregon", "spaulo", "sydney", "taiwan"}
+
+// demoQuorumTimeout is the timeout for requests made by skinnyctl to the demo quorum
+const demoQuorumTimeout = 5 * time.Second
+
+// demo runs a quorum of instances in this process on local ports until it is asked to terminate or an instance fails.
+// It returns the exit code.
+func demo(args []string) int {
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	size := fs.Int("size", 5, "Number of instances")
+	port := fs.Int("port", 9000, "Port of the first instance, the others listen on the following ports (0 for random ports)")
+	quorumFile := fs.String("quorum", "quorum.yml",
+		"File to write the quorum configuration for skinnyctl to, removed or restored on shutdown")
+	force := fs.Bool("force", false, "Overwrite an existing quorum configuration until shutdown")
+	timeout := fs.Duration("timeout", skinny.DefaultTimeout, "Timeout for RPCs made to peers")
+	logLevel := fs.String("log", "info", "Log level (debug, info, or quiet)")
+	fs.Parse(args)
+
+	if *size < 1 {
+		fmt.Fprintf(os.Stderr, "demo: size must be at least 1\n")
+		return 1
+	}
+	level, err := skinny.ParseLogLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "demo: %v\n", err)
+		return 1
+	}
+	// an existing quorum configuration is kept to restore it on shutdown
+	original, err := ioutil.ReadFile(*quorumFile)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "demo: %v\n", err)
+		return 1
+	}
+	if existed && !*force {
+		fmt.Fprintf(os.Stderr, "demo: %v exists, use --quorum to choose another file or --force to overwrite it\n",
+			*quorumFile)
+		return 1
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(*quorumFile); err == nil {
+		mode = info.Mode()
+	}
+
+	// listen first, so that every instance knows the addresses of its peers even if the ports are random
+	quorum := config.QuorumConfig{Timeout: demoQuorumTimeout}
+	listeners := []net.Listener{}
+	for i := 0; i < *size; i++ {
+		address := "127.0.0.1:0"
+		if *port > 0 {
+			address = net.JoinHostPort("127.0.0.1", strconv.Itoa(*port+i))
+		}
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "demo: listen: %v\n", err)
+			return 1
+		}
+		listeners = append(listeners, listener)
+		quorum.Instances = append(quorum.Instances, config.Instance{
+			Name:    demoName(i),
+			Address: listener.Addr().String(),
+		})
+	}
+
+	// the instances share stdout, every line tells which instance logged it
+	out := &demoOutput{w: os.Stdout}
+	servers := []*skinny.Server{}
+	for i, listener := range listeners {
+		name := demoName(i)
+		opts := []skinny.ServerOption{
+			skinny.WithListener(listener),
+			skinny.WithTimeout(*timeout),
+			skinny.WithLogLevel(level),
+			skinny.WithLogOutput(out.prefixed(name)),
+		}
+		for _, peer := range quorum.Instances {
+			if peer.Name != name {
+				opts = append(opts, skinny.WithPeer(peer.Name, peer.Address))
+			}
+		}
+		servers = append(servers, skinny.NewServer(name, uint64(i+1), opts...))
+	}
+	done := make(chan error, len(servers))
+	for _, server := range servers {
+		if err := server.Start(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "demo: %v\n", err)
+			return 1
+		}
+		go func(server *skinny.Server) {
+			done <- <-server.Done()
+		}(server)
+	}
+
+	if err := quorum.Save(*quorumFile); err != nil {
+		fmt.Fprintf(os.Stderr, "demo: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if !existed {
+			os.Remove(*quorumFile)
+			return
+		}
+		if err := ioutil.WriteFile(*quorumFile, original, mode); err != nil {
+			fmt.Fprintf(os.Stderr, "demo: restore %v: %v\n", *quorumFile, err)
+			return
+		}
+		out.printf("📝 restored %v\n", *quorumFile)
+	}()
+	out.printf("🎬 demo quorum of %v instance(s) is up\n", len(servers))
+	for _, in := range quorum.Instances {
+		out.printf("   %v on %v\n", in.Name, in.Address)
+	}
+	out.printf("📝 wrote %v, point skinnyctl at it:\n", *quorumFile)
+	out.printf("   skinnyctl --config %v status\n", *quorumFile)
+	out.printf("   skinnyctl --config %v acquire beaver\n", *quorumFile)
+	out.printf("   skinnyctl --config %v release\n", *quorumFile)
+	out.printf("🛑 press Ctrl+C to stop\n")
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM)
+	code := 0
+	select {
+	case err := <-done:
+		// the other instances stop as well, a quorum short of an instance is not what the demo promised
+		fmt.Fprintf(os.Stderr, "serve: %v\n", err)
+		code = 1
+	case sig := <-sc:
+		out.printf("received %v, shutting down\n", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, server := range servers {
+		server.Stop(ctx)
+	}
+	out.printf("stopped\n")
+	return code
+}
+
+// demoName returns the name of the i-th instance of a demo quorum
+func demoName(i int) string {
+	if i < len(demoNames) {
+		return demoNames[i]
+	}
+	return fmt.Sprintf("instance-%v", i+1)
+}
+
+// demoOutput is the output shared by the instances of a demo quorum
+type demoOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (o *demoOutput) printf(format string, a ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Fprintf(o.w, format, a...)
+}
+
+// prefixed returns a writer that prefixes everything written to it with the name of an instance. Instances write one
+// log message at a time, so every write gets a prefix.
+func (o *demoOutput) prefixed(name string) io.Writer {
+	return prefixWriter{out: o, prefix: fmt.Sprintf("[%v] ", name)}
+}
+
+// prefixWriter writes to a demo output with a prefix
+type prefixWriter struct {
+	out    *demoOutput
+	prefix string
+}
+
+func (w prefixWriter) Write(p []byte) (int, error) {
+	w.out.mu.Lock()
+	defer w.out.mu.Unlock()
+	if _, err := io.WriteString(w.out.w, w.prefix); err != nil {
+		return 0, err
+	}
+	return w.out.w.Write(p)
+}
@@ -1,60 +1,166 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/danrl/skinny/config"
-	"github.com/danrl/skinny/proto/consensus"
 	"github.com/danrl/skinny/proto/control"
-	"github.com/danrl/skinny/proto/lock"
 	"github.com/danrl/skinny/skinny"
-	"google.golang.org/grpc"
 )
 
+// optionUsage describes the flags that override configuration options
+var optionUsage = map[string]string{
+	"name":      "Name of the instance",
+	"increment": "Increment of the instance's round number (ID)",
+	"timeout":   "Timeout for RPCs made to peers",
+	"listen":    "Listening address of the instance",
+	"peers":     "Comma separated list of peers as name=address pairs",
+	"retries":   "Number of retries of a lock request",
+	"backoff":   "Base delay between retries",
+	"log":       "Log level (debug, info, or quiet)",
+}
+
 func main() {
-	configFile := flag.String("config", "/etc/skinny/config.yml", "Skinny configuration file")
+	if len(os.Args) > 1 && os.Args[1] == "demo" {
+		os.Exit(demo(os.Args[2:]))
+	}
+
+	configFile := flag.String("config", defaultConfigFile, "Skinny configuration file (env "+configFileEnv+")")
+	drainTimeout := flag.Duration("drain-timeout", 10*time.Second, "Maximum time to wait for in-flight lock requests on shutdown")
+	recordFile := flag.String("record", "", "File to record consensus traffic to for replay, appended to if it exists")
+	options := make(map[string]*string)
+	for _, option := range config.Options {
+		env := config.EnvPrefix + strings.ToUpper(option)
+		options[option] = flag.String(option, "", fmt.Sprintf("%v (env %v)", optionUsage[option], env))
+	}
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags]\n       %v demo [--size N]\n\nFlags:\n",
+			os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	cfg, err := config.NewInstanceConfig(*configFile)
+	// Options are taken from the configuration file first, then from the environment, then from the flags.
+	flagOverrides := make(map[string]string)
+	flagConfigFile := false
+	flag.Visit(func(f *flag.Flag) {
+		if _, ok := options[f.Name]; ok {
+			flagOverrides[f.Name] = f.Value.String()
+		}
+		if f.Name == "config" {
+			flagConfigFile = true
+		}
+	})
+	overrides := []map[string]string{config.EnvOverrides(os.LookupEnv), flagOverrides}
+	fname := configFileName(*configFile, flagConfigFile)
+
+	cfg, err := config.LoadInstanceConfig(fname, overrides...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "load config: %v\n", err)
 		os.Exit(1)
 	}
-	in := skinny.New(cfg.Name, cfg.Increment, cfg.Timeout)
-
-	// add peers
+	level, err := skinny.ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load config: %v\n", err)
+		os.Exit(1)
+	}
+	opts := []skinny.ServerOption{
+		skinny.WithListen(cfg.Listen),
+		skinny.WithTimeout(cfg.Timeout),
+		skinny.WithRetryPolicy(cfg.Retries, cfg.Backoff),
+		skinny.WithLogLevel(level),
+	}
 	for _, peer := range cfg.Peers {
-		conn, err := grpc.Dial(peer.Address, grpc.WithInsecure())
+		opts = append(opts, skinny.WithPeer(peer.Name, peer.Address))
+	}
+	if *recordFile != "" {
+		f, err := os.OpenFile(*recordFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "dial: %v", err)
+			fmt.Fprintf(os.Stderr, "record: %v\n", err)
 			os.Exit(1)
 		}
-		err = in.AddPeer(peer.Name, consensus.NewConsensusClient(conn))
-		if err != nil {
-			conn.Close()
-			fmt.Fprintf(os.Stderr, "add peer `%v`: %v", peer.Name, err)
+		defer f.Close()
+		opts = append(opts, skinny.WithRecording(f))
+	}
+	server := skinny.NewServer(cfg.Name, cfg.Increment, opts...)
+	d := &daemon{
+		configFile: fname,
+		overrides:  overrides,
+		cfg:        cfg,
+		server:     server,
+	}
+	in := server.Instance()
+	in.SetReloadFunc(d.reload)
+	if err := server.Start(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// serve until we fail or are asked to terminate, reload configuration on request
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+serve:
+	for {
+		select {
+		case err := <-server.Done():
+			fmt.Fprintf(os.Stderr, "serve: %v\n", err)
 			os.Exit(1)
+		case sig := <-sc:
+			if sig == syscall.SIGHUP {
+				_, err := in.Reload(context.Background(), &control.ReloadRequest{})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "reload: %v\n", err)
+				}
+				continue
+			}
+			fmt.Printf("received %v, shutting down\n", sig)
+			break serve
 		}
 	}
 
-	// register and serve protocols
-	grpcServer := grpc.NewServer()
-	consensus.RegisterConsensusServer(grpcServer, in)
-	lock.RegisterLockServer(grpcServer, in)
-	control.RegisterControlServer(grpcServer, in)
+	// a second signal skips the graceful part of the shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
+	defer cancel()
+	go func() {
+		for sig := range sc {
+			if sig == syscall.SIGHUP {
+				continue
+			}
+			fmt.Printf("received %v, stopping immediately\n", sig)
+			cancel()
+		}
+	}()
 
-	// start listener
-	listener, err := net.Listen("tcp", cfg.Listen)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "listen: %v", err)
-		os.Exit(1)
+	// stop accepting lock requests, let in-flight rounds finish, and stop serving
+	if err := server.Stop(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 	}
-	err = grpcServer.Serve(listener)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "serve: %v", err)
-		os.Exit(1)
+	fmt.Println("stopped")
+}
+
+const (
+	defaultConfigFile = "/etc/skinny/config.yml"
+	configFileEnv     = "SKINNY_CONFIG"
+)
+
+// configFileName returns the name of the configuration file to load. The flag takes precedence over the environment.
+// A missing default configuration file is not an error, as long as the remaining options are given by other means.
+func configFileName(flagValue string, flagSet bool) string {
+	if flagSet {
+		return flagValue
+	}
+	if fname, ok := os.LookupEnv(configFileEnv); ok {
+		return fname
+	}
+	if _, err := os.Stat(flagValue); os.IsNotExist(err) {
+		return ""
 	}
+	return flagValue
 }
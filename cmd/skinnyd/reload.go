@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/skinny"
+)
+
+// daemon ties a running server to its configuration file
+type daemon struct {
+	mu sync.Mutex
+	// begin protected fields
+	configFile string
+	overrides  []map[string]string
+	cfg        *config.InstanceConfig
+	server     *skinny.Server
+	// end protected fields
+}
+
+// reload re-reads the configuration file and applies all changes that are safe to apply at runtime. Nothing is
+// applied if the new configuration contains an unsafe change.
+func (d *daemon) reload() ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	next, err := config.LoadInstanceConfig(d.configFile, d.overrides...)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %v", err)
+	}
+	if err := d.cfg.CheckReload(next); err != nil {
+		return nil, err
+	}
+	level, err := skinny.ParseLogLevel(next.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	// replace changed peers first, the server dials them all before it replaces any, so that a failure leaves the
+	// instance untouched
+	addresses := make(map[string]string)
+	for _, peer := range d.cfg.Peers {
+		addresses[peer.Name] = peer.Address
+	}
+	changed := make(map[string]string)
+	for _, peer := range next.Peers {
+		if addresses[peer.Name] != peer.Address {
+			changed[peer.Name] = peer.Address
+		}
+	}
+	if err := d.server.ReplacePeers(changed); err != nil {
+		return nil, err
+	}
+
+	in := d.server.Instance()
+	changes := []string{}
+	if next.Timeout != d.cfg.Timeout {
+		in.SetTimeout(next.Timeout)
+		changes = append(changes, fmt.Sprintf("timeout: %v -> %v", d.cfg.Timeout, next.Timeout))
+	}
+	if next.Retries != d.cfg.Retries || next.Backoff != d.cfg.Backoff {
+		in.SetRetryPolicy(next.Retries, next.Backoff)
+		changes = append(changes, fmt.Sprintf("retry policy: %v retries with %v backoff -> %v retries with %v backoff",
+			d.cfg.Retries, d.cfg.Backoff, next.Retries, next.Backoff))
+	}
+	if next.LogLevel != d.cfg.LogLevel {
+		in.SetLogLevel(level)
+		changes = append(changes, fmt.Sprintf("log level: %v -> %v", d.cfg.LogLevel, next.LogLevel))
+	}
+	for _, peer := range next.Peers {
+		if _, ok := changed[peer.Name]; ok {
+			changes = append(changes, fmt.Sprintf("peer %v: %v -> %v", peer.Name, addresses[peer.Name], peer.Address))
+		}
+	}
+
+	d.cfg = next
+	return changes, nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"io/ioutil"
+	"net"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ClusterInstance describes a single Skinny instance within a cluster description
+type ClusterInstance struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Listen  string `yaml:"listen"`
+}
+
+// ClusterConfig describes a whole Skinny quorum. The configurations of all instances and the quorum configuration can
+// be generated from it.
+type ClusterConfig struct {
+	Timeout       time.Duration     `yaml:"timeout"`
+	QuorumTimeout time.Duration     `yaml:"quorumTimeout"`
+	Retries       int               `yaml:"retries"`
+	Backoff       time.Duration     `yaml:"backoff"`
+	LogLevel      string            `yaml:"log"`
+	Instances     []ClusterInstance `yaml:"instances"`
+}
+
+// NewClusterConfig loads a Skinny cluster description from given file
+func NewClusterConfig(fname string) (*ClusterConfig, error) {
+	cfg := ClusterConfig{
+		Retries:  DefaultRetries,
+		Backoff:  DefaultBackoff,
+		LogLevel: DefaultLogLevel,
+	}
+
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.UnmarshalStrict(data, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// listen on all interfaces by default, using the port from the address
+	for i, in := range cfg.Instances {
+		if in.Listen != "" {
+			continue
+		}
+		_, port, err := net.SplitHostPort(in.Address)
+		if err != nil {
+			return nil, ErrInvalidInstanceDefinition
+		}
+		cfg.Instances[i].Listen = net.JoinHostPort("0.0.0.0", port)
+	}
+
+	// sanity checks
+	if err := checkTimeout(cfg.Timeout); err != nil {
+		return nil, err
+	}
+	if err := checkTimeout(cfg.QuorumTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.Retries < 0 || cfg.Backoff < time.Duration(0) {
+		return nil, ErrInvalidRetryPolicy
+	}
+	if err := checkLogLevel(cfg.LogLevel); err != nil {
+		return nil, err
+	}
+	if err := checkInstanceList(cfg.quorum()...); err != nil {
+		return nil, err
+	}
+	for _, in := range cfg.Instances {
+		// the quorum configuration lives next to the instance configurations
+		if in.Name == "quorum" {
+			return nil, ErrInvalidInstanceDefinition
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Generate returns the configurations of all instances of the cluster, in the order they are described, and the
+// matching quorum configuration. Each instance is assigned a unique increment.
+func (cfg *ClusterConfig) Generate() ([]*InstanceConfig, *QuorumConfig) {
+	instances := []*InstanceConfig{}
+	for i, in := range cfg.Instances {
+		peers := []Instance{}
+		for _, peer := range cfg.quorum() {
+			if peer.Name != in.Name {
+				peers = append(peers, peer)
+			}
+		}
+		instances = append(instances, &InstanceConfig{
+			Name:      in.Name,
+			Increment: uint64(i + 1),
+			Timeout:   cfg.Timeout,
+			Listen:    in.Listen,
+			Peers:     peers,
+			Retries:   cfg.Retries,
+			Backoff:   cfg.Backoff,
+			LogLevel:  cfg.LogLevel,
+		})
+	}
+
+	quorum := &QuorumConfig{
+		Timeout:   cfg.QuorumTimeout,
+		Instances: cfg.quorum(),
+	}
+
+	return instances, quorum
+}
+
+// quorum returns the name and address of every instance in the cluster
+func (cfg *ClusterConfig) quorum() []Instance {
+	instances := []Instance{}
+	for _, in := range cfg.Instances {
+		instances = append(instances, Instance{
+			Name:    in.Name,
+			Address: in.Address,
+		})
+	}
+	return instances
+}
+
+// Save writes the instance configuration to given file
+func (cfg *InstanceConfig) Save(fname string) error {
+	return save(fname, cfg)
+}
+
+// Save writes the quorum configuration to given file
+func (cfg *QuorumConfig) Save(fname string) error {
+	return save(fname, cfg)
+}
+
+// save writes v as a YAML document to given file
+func save(fname string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fname, append([]byte("---\n"), data...), 0644)
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewClusterConfig(t *testing.T) {
+	t.Run("invalid filename", func(t *testing.T) {
+		_, err := NewClusterConfig("testdata/nonexistent.yml")
+		if err == nil {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		_, err := NewClusterConfig("testdata/cluster/bad-timeout.yml")
+		if err != ErrInvalidTimeout {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("duplicate instances", func(t *testing.T) {
+		_, err := NewClusterConfig("testdata/cluster/duplicates.yml")
+		if err != ErrDuplicateInstance {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("reserved instance name", func(t *testing.T) {
+		_, err := NewClusterConfig("testdata/cluster/reserved-name.yml")
+		if err != ErrInvalidInstanceDefinition {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("address without port", func(t *testing.T) {
+		_, err := NewClusterConfig("testdata/cluster/bad-address.yml")
+		if err != ErrInvalidInstanceDefinition {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("valid configuration", func(t *testing.T) {
+		cfg, err := NewClusterConfig("testdata/cluster/good.yml")
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		if cfg.Timeout != 500*time.Millisecond {
+			t.Errorf("expected timeout `500ms`, got `%v`", cfg.Timeout)
+		}
+		if cfg.QuorumTimeout != 5*time.Second {
+			t.Errorf("expected quorum timeout `5s`, got `%v`", cfg.QuorumTimeout)
+		}
+		if len(cfg.Instances) != 3 {
+			t.Fatalf("expected %v instances, got %v", 3, len(cfg.Instances))
+		}
+		if cfg.Instances[0].Listen != "0.0.0.0:9000" {
+			t.Errorf("expected listen `0.0.0.0:9000`, got `%v`", cfg.Instances[0].Listen)
+		}
+		if cfg.Instances[2].Listen != "10.0.0.3:9000" {
+			t.Errorf("expected listen `10.0.0.3:9000`, got `%v`", cfg.Instances[2].Listen)
+		}
+	})
+}
+
+func TestClusterConfigGenerate(t *testing.T) {
+	cluster, err := NewClusterConfig("testdata/cluster/good.yml")
+	if err != nil {
+		t.Fatalf("expected `nil`, got `%v`", err)
+	}
+	instances, quorum := cluster.Generate()
+
+	t.Run("instances", func(t *testing.T) {
+		if len(instances) != 3 {
+			t.Fatalf("expected %v instances, got %v", 3, len(instances))
+		}
+		for i, cfg := range instances {
+			if cfg.Name != cluster.Instances[i].Name {
+				t.Errorf("expected name `%v`, got `%v`", cluster.Instances[i].Name, cfg.Name)
+			}
+			if cfg.Increment != uint64(i+1) {
+				t.Errorf("expected increment `%v`, got `%v`", i+1, cfg.Increment)
+			}
+			if len(cfg.Peers) != 2 {
+				t.Errorf("expected %v peers, got %v", 2, len(cfg.Peers))
+			}
+			if hasPeer(cfg, cfg.Name) {
+				t.Errorf("expected `%v` not to peer with itself", cfg.Name)
+			}
+		}
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		if quorum.Timeout != 5*time.Second {
+			t.Errorf("expected timeout `5s`, got `%v`", quorum.Timeout)
+		}
+		if len(quorum.Instances) != 3 {
+			t.Errorf("expected %v instances, got %v", 3, len(quorum.Instances))
+		}
+	})
+
+	t.Run("consistency", func(t *testing.T) {
+		if problems := Lint(quorum, instances...); problems != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, problems)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "skinny")
+		if err != nil {
+			t.Fatalf("create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		fname := filepath.Join(dir, "london.yml")
+		if err := instances[0].Save(fname); err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		cfg, err := NewInstanceConfig(fname)
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		if cfg.Name != "london" || cfg.Timeout != 500*time.Millisecond || len(cfg.Peers) != 2 {
+			t.Errorf("expected `%v`, got `%v`", instances[0], cfg)
+		}
+
+		fname = filepath.Join(dir, "quorum.yml")
+		if err := quorum.Save(fname); err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		qcfg, err := NewQuorumConfig(fname)
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		if len(qcfg.Instances) != 3 {
+			t.Errorf("expected %v instances, got %v", 3, len(qcfg.Instances))
+		}
+	})
+}
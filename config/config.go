@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"time"
 
@@ -24,8 +25,31 @@ var (
 
 	// ErrDuplicateInstance is returned when there are multiple definitions for the same instance
 	ErrDuplicateInstance = errors.New("duplicate instance")
+
+	// ErrInvalidRetryPolicy is returned when the retry policy is not valid, e.g. a negative number of retries
+	ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+	// ErrInvalidLogLevel is returned when the log level is unknown
+	ErrInvalidLogLevel = errors.New("invalid log level")
+
+	// ErrUnsafeChange is returned when a configuration change can not be applied to a running instance
+	ErrUnsafeChange = errors.New("change requires a restart")
+)
+
+const (
+	// DefaultRetries is the number of retries used if the configuration does not specify one
+	DefaultRetries = 3
+
+	// DefaultBackoff is the base delay between retries used if the configuration does not specify one
+	DefaultBackoff = 2 * time.Millisecond
+
+	// DefaultLogLevel is the log level used if the configuration does not specify one
+	DefaultLogLevel = "debug"
 )
 
+// LogLevels lists the valid log levels, from most to least chatty. Package skinny numbers its log levels in this order.
+var LogLevels = []string{"debug", "info", "quiet"}
+
 // Instance describes a single Skinny instance connection information
 type Instance struct {
 	Name    string `yaml:"name"`
@@ -39,6 +63,9 @@ type InstanceConfig struct {
 	Timeout   time.Duration `yaml:"timeout"`
 	Listen    string        `yaml:"listen"`
 	Peers     []Instance    `yaml:"peers"`
+	Retries   int           `yaml:"retries"`
+	Backoff   time.Duration `yaml:"backoff"`
+	LogLevel  string        `yaml:"log"`
 }
 
 // QuorumConfig describes a Skinny quorum configuration file
@@ -49,15 +76,39 @@ type QuorumConfig struct {
 
 // NewInstanceConfig loads a Skinny instance configuration from given file
 func NewInstanceConfig(fname string) (*InstanceConfig, error) {
-	var cfg InstanceConfig
+	return LoadInstanceConfig(fname)
+}
 
-	data, err := ioutil.ReadFile(fname)
-	if err != nil {
-		return nil, err
+// LoadInstanceConfig loads a Skinny instance configuration from given file and applies the overrides in order, so that
+// later overrides take precedence over earlier ones. Each override maps option names to values, see Override. If the
+// file name is empty, the configuration is built from the overrides alone.
+func LoadInstanceConfig(fname string, overrides ...map[string]string) (*InstanceConfig, error) {
+	cfg := InstanceConfig{
+		Retries:  DefaultRetries,
+		Backoff:  DefaultBackoff,
+		LogLevel: DefaultLogLevel,
 	}
-	err = yaml.UnmarshalStrict(data, &cfg)
-	if err != nil {
-		return nil, err
+
+	if fname != "" {
+		data, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.UnmarshalStrict(data, &cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, override := range overrides {
+		for _, option := range Options {
+			value, ok := override[option]
+			if !ok {
+				continue
+			}
+			if err := cfg.Override(option, value); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// sanity checks
@@ -67,6 +118,12 @@ func NewInstanceConfig(fname string) (*InstanceConfig, error) {
 	if err := checkTimeout(cfg.Timeout); err != nil {
 		return nil, err
 	}
+	if cfg.Retries < 0 || cfg.Backoff < time.Duration(0) {
+		return nil, ErrInvalidRetryPolicy
+	}
+	if err := checkLogLevel(cfg.LogLevel); err != nil {
+		return nil, err
+	}
 	instances := append(cfg.Peers, Instance{Name: cfg.Name, Address: cfg.Listen})
 	if err := checkInstanceList(instances...); err != nil {
 		return nil, err
@@ -98,6 +155,36 @@ func NewQuorumConfig(fname string) (*QuorumConfig, error) {
 	return cfg, nil
 }
 
+// CheckReload returns an error if the changes from cfg to next can not be applied to a running instance. Changing the
+// timeout, retry policy, log level, or the address of a peer is safe. Everything else requires a restart.
+func (cfg *InstanceConfig) CheckReload(next *InstanceConfig) error {
+	if next.Name != cfg.Name {
+		return fmt.Errorf("name: %w", ErrUnsafeChange)
+	}
+	if next.Increment != cfg.Increment {
+		return fmt.Errorf("increment: %w", ErrUnsafeChange)
+	}
+	if next.Listen != cfg.Listen {
+		return fmt.Errorf("listen: %w", ErrUnsafeChange)
+	}
+
+	// Adding or removing peers changes the size of the majority. That is not safe to do while the quorum is running.
+	if len(next.Peers) != len(cfg.Peers) {
+		return fmt.Errorf("peers: %w", ErrUnsafeChange)
+	}
+	names := make(map[string]bool)
+	for _, peer := range cfg.Peers {
+		names[peer.Name] = true
+	}
+	for _, peer := range next.Peers {
+		if !names[peer.Name] {
+			return fmt.Errorf("peers: %w", ErrUnsafeChange)
+		}
+	}
+
+	return nil
+}
+
 // checkTimeout performs a sanity check for a timeout value
 func checkTimeout(timeout time.Duration) error {
 	if timeout <= time.Duration(0) {
@@ -106,6 +193,16 @@ func checkTimeout(timeout time.Duration) error {
 	return nil
 }
 
+// checkLogLevel performs a sanity check for a log level
+func checkLogLevel(level string) error {
+	for _, l := range LogLevels {
+		if level == l {
+			return nil
+		}
+	}
+	return ErrInvalidLogLevel
+}
+
 // checkInstanceList performs a sanity check for a list of instances
 func checkInstanceList(instances ...Instance) error {
 	if len(instances) == 0 {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -48,6 +49,36 @@ func TestNewInstanceConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid retry policy", func(t *testing.T) {
+		_, err := NewInstanceConfig("testdata/instance/bad-retries.yml")
+		if err != ErrInvalidRetryPolicy {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("invalid log level", func(t *testing.T) {
+		_, err := NewInstanceConfig("testdata/instance/bad-log-level.yml")
+		if err != ErrInvalidLogLevel {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+
+	t.Run("valid configuration with options", func(t *testing.T) {
+		cfg, err := NewInstanceConfig("testdata/instance/good-options.yml")
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		if cfg.Retries != 5 {
+			t.Errorf("expected retries `5`, got `%v`", cfg.Retries)
+		}
+		if cfg.Backoff != 10*time.Millisecond {
+			t.Errorf("expected backoff `10ms`, got `%v`", cfg.Backoff)
+		}
+		if cfg.LogLevel != "info" {
+			t.Errorf("expected log level `info`, got `%v`", cfg.LogLevel)
+		}
+	})
+
 	t.Run("valid configuration", func(t *testing.T) {
 		cfg, err := NewInstanceConfig("testdata/instance/good.yml")
 		if err != nil {
@@ -74,7 +105,66 @@ func TestNewInstanceConfig(t *testing.T) {
 		if cfg.Peers[0].Address != "oregon.skinny.cakelie.net:9000" {
 			t.Errorf("expected address `oregon.skinny.cakelie.net:9000`, got `%v`", cfg.Peers[0].Address)
 		}
+		if cfg.Retries != DefaultRetries {
+			t.Errorf("expected retries `%v`, got `%v`", DefaultRetries, cfg.Retries)
+		}
+		if cfg.Backoff != DefaultBackoff {
+			t.Errorf("expected backoff `%v`, got `%v`", DefaultBackoff, cfg.Backoff)
+		}
+		if cfg.LogLevel != DefaultLogLevel {
+			t.Errorf("expected log level `%v`, got `%v`", DefaultLogLevel, cfg.LogLevel)
+		}
+	})
+}
+
+func TestInstanceConfigCheckReload(t *testing.T) {
+	current := func() *InstanceConfig {
+		return &InstanceConfig{
+			Name:      "london",
+			Increment: 1,
+			Timeout:   500 * time.Millisecond,
+			Listen:    "0.0.0.0:9000",
+			Peers: []Instance{
+				{Name: "oregon", Address: "oregon.skinny.cakelie.net:9000"},
+				{Name: "sydney", Address: "sydney.skinny.cakelie.net:9000"},
+			},
+			Retries:  3,
+			Backoff:  2 * time.Millisecond,
+			LogLevel: "debug",
+		}
+	}
+
+	t.Run("safe changes", func(t *testing.T) {
+		next := current()
+		next.Timeout = time.Second
+		next.Retries = 5
+		next.Backoff = 10 * time.Millisecond
+		next.LogLevel = "quiet"
+		next.Peers[1].Address = "sydney-2.skinny.cakelie.net:9000"
+		if err := current().CheckReload(next); err != nil {
+			t.Errorf("expected `nil`, got `%v`", err)
+		}
 	})
+
+	for name, change := range map[string]func(cfg *InstanceConfig){
+		"name":      func(cfg *InstanceConfig) { cfg.Name = "paris" },
+		"increment": func(cfg *InstanceConfig) { cfg.Increment = 2 },
+		"listen":    func(cfg *InstanceConfig) { cfg.Listen = "0.0.0.0:9001" },
+		"add peer": func(cfg *InstanceConfig) {
+			cfg.Peers = append(cfg.Peers, Instance{Name: "taiwan", Address: "taiwan.skinny.cakelie.net:9000"})
+		},
+		"rename peer": func(cfg *InstanceConfig) { cfg.Peers[0].Name = "spaulo" },
+	} {
+		change := change
+		t.Run(name, func(t *testing.T) {
+			next := current()
+			change(next)
+			err := current().CheckReload(next)
+			if !errors.Is(err, ErrUnsafeChange) {
+				t.Errorf("expected `%v`, got `%v`", ErrUnsafeChange, err)
+			}
+		})
+	}
 }
 
 func TestNewQuorumConfig(t *testing.T) {
@@ -144,6 +234,24 @@ func TestCheckTimeout(t *testing.T) {
 	})
 }
 
+func TestCheckLogLevel(t *testing.T) {
+	t.Run("unknown level", func(t *testing.T) {
+		expected := ErrInvalidLogLevel
+		got := checkLogLevel("chatty")
+		if got != expected {
+			t.Errorf("expected `%v`, got `%v`", expected, got)
+		}
+	})
+
+	t.Run("good level", func(t *testing.T) {
+		var expected error
+		got := checkLogLevel("info")
+		if got != expected {
+			t.Errorf("expected `%v`, got `%v`", expected, got)
+		}
+	})
+}
+
 func TestCheckInstanceList(t *testing.T) {
 	instanceEmptyName := Instance{
 		Name:    "",
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+)
+
+var (
+	// ErrMembershipMismatch is returned when an instance's view of the quorum differs from the quorum configuration
+	ErrMembershipMismatch = errors.New("membership mismatch")
+
+	// ErrAsymmetricMembership is returned when an instance lists a peer that does not list the instance in return
+	ErrAsymmetricMembership = errors.New("asymmetric membership")
+
+	// ErrDuplicateIncrement is returned when multiple instances use the same increment
+	ErrDuplicateIncrement = errors.New("duplicate increment")
+
+	// ErrEvenQuorum is returned when a quorum consists of an even number of instances
+	ErrEvenQuorum = errors.New("even number of instances")
+
+	// ErrAddressMismatch is returned when an address does not match the listen address of the instance it refers to
+	ErrAddressMismatch = errors.New("address mismatch")
+
+	// ErrDuplicateName is returned when multiple instance configurations declare the same instance
+	ErrDuplicateName = errors.New("duplicate instance name")
+
+	// ErrMissingConfig is returned when a member of the quorum has no instance configuration
+	ErrMissingConfig = errors.New("missing instance configuration")
+)
+
+// Lint checks a set of instance configurations and the quorum configuration for consistency with each other. It
+// returns one error per problem found, or nil if the configurations agree. Every member of the quorum needs an
+// instance configuration. Configurations that share a name are all checked, they are told apart by their position
+// among instances, counting from 1.
+func Lint(quorum *QuorumConfig, instances ...*InstanceConfig) []error {
+	problems := []error{}
+
+	// index everything by instance name
+	members := make(map[string]bool)
+	for _, in := range quorum.Instances {
+		members[in.Name] = true
+	}
+	configs := make(map[string][]*InstanceConfig)
+	for _, cfg := range instances {
+		configs[cfg.Name] = append(configs[cfg.Name], cfg)
+	}
+	linted := []lintedConfig{}
+	for i, cfg := range instances {
+		label := cfg.Name
+		if len(configs[cfg.Name]) > 1 {
+			label = fmt.Sprintf("%v (config %v)", cfg.Name, i+1)
+		}
+		linted = append(linted, lintedConfig{label: label, cfg: cfg})
+	}
+	sort.SliceStable(linted, func(i, j int) bool {
+		return linted[i].cfg.Name < linted[j].cfg.Name
+	})
+
+	if len(quorum.Instances)%2 == 0 {
+		problems = append(problems, fmt.Errorf("quorum: %w (%v)", ErrEvenQuorum, len(quorum.Instances)))
+	}
+	for _, member := range sortedKeys(members) {
+		if len(configs[member]) == 0 {
+			problems = append(problems, fmt.Errorf("quorum: %w for %v", ErrMissingConfig, member))
+		}
+	}
+	reported := make(map[string]bool)
+	for _, l := range linted {
+		if n := len(configs[l.cfg.Name]); n > 1 && !reported[l.cfg.Name] {
+			reported[l.cfg.Name] = true
+			problems = append(problems, fmt.Errorf("%v: %w (%v configs)", l.cfg.Name, ErrDuplicateName, n))
+		}
+	}
+
+	increments := make(map[uint64]string)
+	for _, l := range linted {
+		name, cfg := l.cfg.Name, l.cfg
+
+		// increments must be unique to prevent dueling proposers
+		if other, ok := increments[cfg.Increment]; ok {
+			problems = append(problems, fmt.Errorf("%v: %w %v (also used by %v)",
+				l.label, ErrDuplicateIncrement, cfg.Increment, other))
+		} else {
+			increments[cfg.Increment] = l.label
+		}
+
+		// an instance must know exactly the members of the quorum
+		view := map[string]bool{name: true}
+		for _, peer := range cfg.Peers {
+			view[peer.Name] = true
+		}
+		if len(view)%2 == 0 {
+			problems = append(problems, fmt.Errorf("%v: %w (%v)", l.label, ErrEvenQuorum, len(view)))
+		}
+		for _, member := range sortedKeys(view) {
+			if !members[member] {
+				problems = append(problems, fmt.Errorf("%v: %w: %v is not in the quorum",
+					l.label, ErrMembershipMismatch, member))
+			}
+		}
+		for _, member := range sortedKeys(members) {
+			if !view[member] {
+				problems = append(problems, fmt.Errorf("%v: %w: %v is missing", l.label, ErrMembershipMismatch, member))
+			}
+		}
+
+		// peering must be mutual
+		for _, peer := range cfg.Peers {
+			for _, other := range configs[peer.Name] {
+				if !hasPeer(other, name) {
+					problems = append(problems, fmt.Errorf("%v: %w: %v does not list %v as a peer",
+						l.label, ErrAsymmetricMembership, peer.Name, name))
+				}
+			}
+		}
+	}
+
+	// every address must reach the instance it refers to
+	for _, in := range quorum.Instances {
+		for _, cfg := range configs[in.Name] {
+			if !addressMatches(in.Address, cfg.Listen) {
+				problems = append(problems, fmt.Errorf("quorum: %w: %v does not match %v's listen address %v",
+					ErrAddressMismatch, in.Address, in.Name, cfg.Listen))
+			}
+		}
+	}
+	for _, l := range linted {
+		for _, peer := range l.cfg.Peers {
+			for _, cfg := range configs[peer.Name] {
+				if !addressMatches(peer.Address, cfg.Listen) {
+					problems = append(problems, fmt.Errorf("%v: %w: %v does not match %v's listen address %v",
+						l.label, ErrAddressMismatch, peer.Address, peer.Name, cfg.Listen))
+				}
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// lintedConfig is an instance configuration being linted
+type lintedConfig struct {
+	// label names the configuration in problems
+	label string
+	cfg   *InstanceConfig
+}
+
+// hasPeer returns true if the instance configuration lists a peer of the given name
+func hasPeer(cfg *InstanceConfig, name string) bool {
+	for _, peer := range cfg.Peers {
+		if peer.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addressMatches returns true if address can reach an instance listening on listen. Hosts are only compared if both
+// are IP addresses, because resolving names is out of scope. Listening on an unspecified host (e.g. 0.0.0.0) matches
+// any host.
+func addressMatches(address, listen string) bool {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	listenHost, listenPort, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if port != listenPort {
+		return false
+	}
+	ip, listenIP := net.ParseIP(host), net.ParseIP(listenHost)
+	if ip == nil || listenIP == nil || listenIP.IsUnspecified() {
+		return true
+	}
+	return ip.Equal(listenIP)
+}
+
+// sortedKeys returns the keys of a set in lexical order
+func sortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
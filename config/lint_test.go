@@ -0,0 +1,187 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// lintFixture returns a consistent quorum of three instances
+func lintFixture() (*QuorumConfig, []*InstanceConfig) {
+	quorum := &QuorumConfig{
+		Timeout: 5 * time.Second,
+		Instances: []Instance{
+			{Name: "north", Address: "north.example.com:9000"},
+			{Name: "east", Address: "east.example.com:9000"},
+			{Name: "south", Address: "127.0.0.1:9003"},
+		},
+	}
+	instances := []*InstanceConfig{
+		{
+			Name:      "north",
+			Increment: 1,
+			Timeout:   500 * time.Millisecond,
+			Listen:    "0.0.0.0:9000",
+			Peers: []Instance{
+				{Name: "east", Address: "east.example.com:9000"},
+				{Name: "south", Address: "127.0.0.1:9003"},
+			},
+		},
+		{
+			Name:      "east",
+			Increment: 2,
+			Timeout:   500 * time.Millisecond,
+			Listen:    ":9000",
+			Peers: []Instance{
+				{Name: "north", Address: "north.example.com:9000"},
+				{Name: "south", Address: "127.0.0.1:9003"},
+			},
+		},
+		{
+			Name:      "south",
+			Increment: 3,
+			Timeout:   500 * time.Millisecond,
+			Listen:    "127.0.0.1:9003",
+			Peers: []Instance{
+				{Name: "north", Address: "north.example.com:9000"},
+				{Name: "east", Address: "east.example.com:9000"},
+			},
+		},
+	}
+	return quorum, instances
+}
+
+func TestLint(t *testing.T) {
+	t.Run("consistent configuration", func(t *testing.T) {
+		quorum, instances := lintFixture()
+		problems := Lint(quorum, instances...)
+		if problems != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, problems)
+		}
+	})
+
+	t.Run("partial set of instances", func(t *testing.T) {
+		quorum, instances := lintFixture()
+		problems := Lint(quorum, instances[0])
+		if len(problems) != 2 || !errors.Is(problems[0], ErrMissingConfig) || !errors.Is(problems[1], ErrMissingConfig) {
+			t.Errorf("expected `%v` for east and south, got `%v`", ErrMissingConfig, problems)
+		}
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		quorum, instances := lintFixture()
+		// a copy of north's file with a different listen address and peers replaces south's
+		dup := *instances[0]
+		dup.Listen = "0.0.0.0:9001"
+		dup.Peers = instances[0].Peers[:1]
+		problems := Lint(quorum, instances[0], instances[1], &dup)
+		expected := []string{
+			"quorum: missing instance configuration for south",
+			"north: duplicate instance name (2 configs)",
+			"quorum: address mismatch: north.example.com:9000 does not match north's listen address 0.0.0.0:9001",
+			"east: address mismatch: north.example.com:9000 does not match north's listen address 0.0.0.0:9001",
+			"north (config 3): duplicate increment 1 (also used by north (config 1))",
+			"north (config 3): membership mismatch: south is missing",
+		}
+		got := []string{}
+		for _, problem := range problems {
+			got = append(got, problem.Error())
+		}
+		for _, e := range expected {
+			found := false
+			for _, g := range got {
+				found = found || g == e
+			}
+			if !found {
+				t.Errorf("expected `%v`, got `%v`", e, got)
+			}
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		change   func(quorum *QuorumConfig, instances []*InstanceConfig)
+		expected error
+	}{
+		"duplicate increment": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				instances[1].Increment = 1
+			},
+			expected: ErrDuplicateIncrement,
+		},
+		"missing member": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				instances[0].Peers = instances[0].Peers[:1]
+			},
+			expected: ErrMembershipMismatch,
+		},
+		"unknown member": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				instances[0].Peers[0].Name = "west"
+			},
+			expected: ErrMembershipMismatch,
+		},
+		"asymmetric membership": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				quorum.Instances = append(quorum.Instances, Instance{Name: "west", Address: "west.example.com:9000"})
+				instances[2].Peers[1] = Instance{Name: "west", Address: "west.example.com:9000"}
+			},
+			expected: ErrAsymmetricMembership,
+		},
+		"even quorum": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				quorum.Instances = quorum.Instances[:2]
+			},
+			expected: ErrEvenQuorum,
+		},
+		"wrong port": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				quorum.Instances[0].Address = "north.example.com:9001"
+			},
+			expected: ErrAddressMismatch,
+		},
+		"wrong host": {
+			change: func(quorum *QuorumConfig, instances []*InstanceConfig) {
+				instances[0].Peers[1].Address = "127.0.0.2:9003"
+			},
+			expected: ErrAddressMismatch,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			quorum, instances := lintFixture()
+			tc.change(quorum, instances)
+			problems := Lint(quorum, instances...)
+			found := false
+			for _, problem := range problems {
+				if errors.Is(problem, tc.expected) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected `%v`, got `%v`", tc.expected, problems)
+			}
+		})
+	}
+}
+
+func TestAddressMatches(t *testing.T) {
+	for _, tc := range []struct {
+		address  string
+		listen   string
+		expected bool
+	}{
+		{"london.skinny.cakelie.net:9000", "0.0.0.0:9000", true},
+		{"london.skinny.cakelie.net:9000", "[::]:9000", true},
+		{"london.skinny.cakelie.net:9000", ":9000", true},
+		{"london.skinny.cakelie.net:9000", "0.0.0.0:9001", false},
+		{"127.0.0.1:9000", "127.0.0.1:9000", true},
+		{"127.0.0.1:9000", "127.0.0.2:9000", false},
+		{"localhost:9000", "127.0.0.1:9000", true},
+		{"no-port", "0.0.0.0:9000", false},
+	} {
+		got := addressMatches(tc.address, tc.listen)
+		if got != tc.expected {
+			t.Errorf("%v on %v: expected `%v`, got `%v`", tc.address, tc.listen, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of environment variables that override instance configuration options
+const EnvPrefix = "SKINNY_"
+
+// Options lists the names of all instance configuration options that can be overridden
+var Options = []string{"name", "increment", "timeout", "listen", "peers", "retries", "backoff", "log"}
+
+var (
+	// ErrUnknownOption is returned when an option to override does not exist
+	ErrUnknownOption = errors.New("unknown option")
+
+	// ErrInvalidOption is returned when the value of an option to override can not be parsed
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// Override sets an option of the instance configuration from its textual representation. Peers are given as a comma
+// separated list of name=address pairs, e.g. `oregon=oregon.skinny.cakelie.net:9000,spaulo=10.0.0.3:9000`.
+func (cfg *InstanceConfig) Override(option, value string) error {
+	var err error
+
+	switch option {
+	case "name":
+		cfg.Name = value
+	case "increment":
+		cfg.Increment, err = strconv.ParseUint(value, 10, 64)
+	case "timeout":
+		cfg.Timeout, err = time.ParseDuration(value)
+	case "listen":
+		cfg.Listen = value
+	case "peers":
+		cfg.Peers, err = parsePeers(value)
+	case "retries":
+		cfg.Retries, err = strconv.Atoi(value)
+	case "backoff":
+		cfg.Backoff, err = time.ParseDuration(value)
+	case "log":
+		cfg.LogLevel = value
+	default:
+		return fmt.Errorf("%v: %w", option, ErrUnknownOption)
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %w", option, ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// EnvOverrides returns the overrides found in the environment. An option is looked up by its upper case name with
+// EnvPrefix prepended, e.g. SKINNY_TIMEOUT. lookup is usually os.LookupEnv.
+func EnvOverrides(lookup func(string) (string, bool)) map[string]string {
+	overrides := make(map[string]string)
+	for _, option := range Options {
+		if value, ok := lookup(EnvPrefix + strings.ToUpper(option)); ok {
+			overrides[option] = value
+		}
+	}
+	return overrides
+}
+
+// parsePeers parses a comma separated list of name=address pairs
+func parsePeers(value string) ([]Instance, error) {
+	peers := []Instance{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, ErrInvalidOption
+		}
+		peers = append(peers, Instance{
+			Name:    strings.TrimSpace(parts[0]),
+			Address: strings.TrimSpace(parts[1]),
+		})
+	}
+	return peers, nil
+}
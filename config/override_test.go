@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInstanceConfigOverride(t *testing.T) {
+	t.Run("all options", func(t *testing.T) {
+		var cfg InstanceConfig
+		for option, value := range map[string]string{
+			"name":      "london",
+			"increment": "3",
+			"timeout":   "750ms",
+			"listen":    "0.0.0.0:9000",
+			"peers":     "oregon=oregon.skinny.cakelie.net:9000, spaulo=10.0.0.3:9000",
+			"retries":   "5",
+			"backoff":   "10ms",
+			"log":       "info",
+		} {
+			if err := cfg.Override(option, value); err != nil {
+				t.Errorf("%v: expected `%v`, got `%v`", option, nil, err)
+			}
+		}
+		if cfg.Name != "london" {
+			t.Errorf("expected name `london`, got `%v`", cfg.Name)
+		}
+		if cfg.Increment != 3 {
+			t.Errorf("expected increment `3`, got `%v`", cfg.Increment)
+		}
+		if cfg.Timeout != 750*time.Millisecond {
+			t.Errorf("expected timeout `750ms`, got `%v`", cfg.Timeout)
+		}
+		if cfg.Listen != "0.0.0.0:9000" {
+			t.Errorf("expected listen `0.0.0.0:9000`, got `%v`", cfg.Listen)
+		}
+		if len(cfg.Peers) != 2 {
+			t.Fatalf("expected %v peers, got %v", 2, len(cfg.Peers))
+		}
+		if cfg.Peers[1].Name != "spaulo" || cfg.Peers[1].Address != "10.0.0.3:9000" {
+			t.Errorf("expected peer `spaulo` at `10.0.0.3:9000`, got `%v` at `%v`", cfg.Peers[1].Name, cfg.Peers[1].Address)
+		}
+		if cfg.Retries != 5 {
+			t.Errorf("expected retries `5`, got `%v`", cfg.Retries)
+		}
+		if cfg.Backoff != 10*time.Millisecond {
+			t.Errorf("expected backoff `10ms`, got `%v`", cfg.Backoff)
+		}
+		if cfg.LogLevel != "info" {
+			t.Errorf("expected log level `info`, got `%v`", cfg.LogLevel)
+		}
+	})
+
+	t.Run("no peers", func(t *testing.T) {
+		cfg := InstanceConfig{
+			Peers: []Instance{{Name: "oregon", Address: "oregon.skinny.cakelie.net:9000"}},
+		}
+		if err := cfg.Override("peers", ""); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(cfg.Peers) != 0 {
+			t.Errorf("expected %v peers, got %v", 0, len(cfg.Peers))
+		}
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		var cfg InstanceConfig
+		err := cfg.Override("color", "blue")
+		if !errors.Is(err, ErrUnknownOption) {
+			t.Errorf("expected `%v`, got `%v`", ErrUnknownOption, err)
+		}
+	})
+
+	for option, value := range map[string]string{
+		"increment": "-1",
+		"timeout":   "soon",
+		"peers":     "oregon",
+		"retries":   "many",
+		"backoff":   "1 fortnight",
+	} {
+		option, value := option, value
+		t.Run("invalid "+option, func(t *testing.T) {
+			var cfg InstanceConfig
+			err := cfg.Override(option, value)
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("expected `%v`, got `%v`", ErrInvalidOption, err)
+			}
+		})
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	env := map[string]string{
+		"SKINNY_TIMEOUT": "1s",
+		"SKINNY_PEERS":   "oregon=127.0.0.1:9002",
+		"SKINNY_COLOR":   "blue",
+		"TIMEOUT":        "2s",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	overrides := EnvOverrides(lookup)
+	if len(overrides) != 2 {
+		t.Errorf("expected %v overrides, got %v", 2, len(overrides))
+	}
+	if overrides["timeout"] != "1s" {
+		t.Errorf("expected timeout `1s`, got `%v`", overrides["timeout"])
+	}
+	if overrides["peers"] != "oregon=127.0.0.1:9002" {
+		t.Errorf("expected peers `oregon=127.0.0.1:9002`, got `%v`", overrides["peers"])
+	}
+}
+
+func TestLoadInstanceConfig(t *testing.T) {
+	t.Run("precedence", func(t *testing.T) {
+		env := map[string]string{"timeout": "1s", "retries": "7"}
+		flags := map[string]string{"timeout": "2s"}
+
+		cfg, err := LoadInstanceConfig("testdata/instance/good.yml", env, flags)
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		// from the file
+		if cfg.Name != "london" {
+			t.Errorf("expected name `london`, got `%v`", cfg.Name)
+		}
+		// from the environment
+		if cfg.Retries != 7 {
+			t.Errorf("expected retries `7`, got `%v`", cfg.Retries)
+		}
+		// from the flags
+		if cfg.Timeout != 2*time.Second {
+			t.Errorf("expected timeout `2s`, got `%v`", cfg.Timeout)
+		}
+	})
+
+	t.Run("without file", func(t *testing.T) {
+		cfg, err := LoadInstanceConfig("", map[string]string{
+			"name":      "london",
+			"increment": "1",
+			"timeout":   "500ms",
+			"listen":    "0.0.0.0:9000",
+			"peers":     "oregon=127.0.0.1:9002,spaulo=127.0.0.1:9003",
+		})
+		if err != nil {
+			t.Fatalf("expected `nil`, got `%v`", err)
+		}
+		if len(cfg.Peers) != 2 {
+			t.Errorf("expected %v peers, got %v", 2, len(cfg.Peers))
+		}
+		if cfg.Retries != DefaultRetries {
+			t.Errorf("expected retries `%v`, got `%v`", DefaultRetries, cfg.Retries)
+		}
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		_, err := LoadInstanceConfig("testdata/instance/good.yml", map[string]string{"timeout": "0s"})
+		if err != ErrInvalidTimeout {
+			t.Errorf("expected error, got `%v`", err)
+		}
+	})
+}
@@ -64,8 +64,13 @@ func Test() error {
 
 // Build binary executables.
 func Build() error {
+	version, err := sh.Output("git", "describe", "--tags", "--always", "--dirty")
+	if err != nil {
+		return err
+	}
+	ldflags := "-X github.com/danrl/skinny/skinny.Version=" + version
 	for _, name := range targets {
-		err := sh.RunV("go", "build", "-v", "-o", "./bin/"+name, "./cmd/"+name)
+		err = sh.RunV("go", "build", "-v", "-ldflags", ldflags, "-o", "./bin/"+name, "./cmd/"+name)
 		if err != nil {
 			return err
 		}
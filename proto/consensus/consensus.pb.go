@@ -208,30 +208,107 @@ func (m *CommitResponse) GetCommitted() bool {
 	return false
 }
 
+// Ping is not part of the protocol. It is used to diagnose the connection between two instances.
+type PingRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PingRequest) Reset()         { *m = PingRequest{} }
+func (m *PingRequest) String() string { return proto.CompactTextString(m) }
+func (*PingRequest) ProtoMessage()    {}
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_292e7e1f14c44e53, []int{4}
+}
+
+func (m *PingRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PingRequest.Unmarshal(m, b)
+}
+func (m *PingRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PingRequest.Marshal(b, m, deterministic)
+}
+func (m *PingRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingRequest.Merge(m, src)
+}
+func (m *PingRequest) XXX_Size() int {
+	return xxx_messageInfo_PingRequest.Size(m)
+}
+func (m *PingRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingRequest proto.InternalMessageInfo
+
+type PingResponse struct {
+	// Name of the instance that answered
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PingResponse) Reset()         { *m = PingResponse{} }
+func (m *PingResponse) String() string { return proto.CompactTextString(m) }
+func (*PingResponse) ProtoMessage()    {}
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_292e7e1f14c44e53, []int{5}
+}
+
+func (m *PingResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PingResponse.Unmarshal(m, b)
+}
+func (m *PingResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PingResponse.Marshal(b, m, deterministic)
+}
+func (m *PingResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingResponse.Merge(m, src)
+}
+func (m *PingResponse) XXX_Size() int {
+	return xxx_messageInfo_PingResponse.Size(m)
+}
+func (m *PingResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingResponse proto.InternalMessageInfo
+
+func (m *PingResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*PromiseRequest)(nil), "PromiseRequest")
 	proto.RegisterType((*PromiseResponse)(nil), "PromiseResponse")
 	proto.RegisterType((*CommitRequest)(nil), "CommitRequest")
 	proto.RegisterType((*CommitResponse)(nil), "CommitResponse")
+	proto.RegisterType((*PingRequest)(nil), "PingRequest")
+	proto.RegisterType((*PingResponse)(nil), "PingResponse")
 }
 
 func init() { proto.RegisterFile("proto/consensus/consensus.proto", fileDescriptor_292e7e1f14c44e53) }
 
 var fileDescriptor_292e7e1f14c44e53 = []byte{
-	// 205 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2f, 0x28, 0xca, 0x2f,
-	0xc9, 0xd7, 0x4f, 0xce, 0xcf, 0x2b, 0x4e, 0xcd, 0x2b, 0x2e, 0x2d, 0x46, 0xb0, 0xf4, 0xc0, 0x32,
-	0x4a, 0x0a, 0x5c, 0x7c, 0x01, 0x45, 0xf9, 0xb9, 0x99, 0xc5, 0xa9, 0x41, 0xa9, 0x85, 0xa5, 0xa9,
-	0xc5, 0x25, 0x42, 0x7c, 0x5c, 0x4c, 0x9e, 0x2e, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x2c, 0x41, 0x4c,
-	0x9e, 0x2e, 0x4a, 0xa1, 0x5c, 0xfc, 0x70, 0x15, 0xc5, 0x05, 0x20, 0xed, 0x42, 0x52, 0x5c, 0x1c,
-	0x50, 0xa1, 0x14, 0xb0, 0x42, 0x8e, 0x20, 0x38, 0x1f, 0xaa, 0x9d, 0x09, 0xa6, 0x5d, 0x48, 0x8c,
-	0x8b, 0xcd, 0x23, 0x3f, 0x27, 0x25, 0xb5, 0x48, 0x82, 0x59, 0x81, 0x51, 0x83, 0x33, 0x08, 0xca,
-	0x53, 0x32, 0xe7, 0xe2, 0x75, 0xce, 0xcf, 0xcd, 0xcd, 0x2c, 0xc1, 0x61, 0x2f, 0x92, 0x46, 0x26,
-	0x14, 0x8d, 0x7a, 0x5c, 0x7c, 0x30, 0x8d, 0x50, 0xe7, 0xc8, 0x70, 0x71, 0x42, 0x44, 0x4a, 0xe0,
-	0xee, 0x41, 0x08, 0x18, 0xa5, 0x80, 0x64, 0xa1, 0x9e, 0x16, 0xd2, 0xe1, 0x62, 0x87, 0xba, 0x54,
-	0x88, 0x5f, 0x0f, 0xd5, 0xe3, 0x52, 0x02, 0x7a, 0xe8, 0xfe, 0xd4, 0xe4, 0x62, 0x83, 0x98, 0x23,
-	0xc4, 0xa7, 0x87, 0xe2, 0x58, 0x29, 0x7e, 0x3d, 0x54, 0x37, 0x24, 0xb1, 0x81, 0x83, 0xd3, 0x18,
-	0x10, 0x00, 0x00, 0xff, 0xff, 0x24, 0x9b, 0x21, 0xc4, 0x71, 0x01, 0x00, 0x00,
+	// 250 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x40, 0x49, 0x0c, 0xb1, 0x19, 0x9b, 0x44, 0xe6, 0x20, 0x25, 0x08, 0x86, 0xf5, 0x52, 0x41,
+	0x46, 0xd0, 0x83, 0x1f, 0x60, 0x0f, 0xf6, 0x22, 0x65, 0xc1, 0x0f, 0x50, 0xbb, 0x48, 0xc0, 0xcd,
+	0xd6, 0xec, 0xf6, 0x23, 0xfc, 0x6b, 0xc9, 0x66, 0x9a, 0x26, 0x81, 0xde, 0x66, 0x26, 0xf3, 0x32,
+	0x6f, 0x66, 0xe1, 0x66, 0xd7, 0x18, 0x67, 0x1e, 0xbe, 0x4c, 0x6d, 0x55, 0x6d, 0xf7, 0xf6, 0x18,
+	0x91, 0xff, 0x22, 0x4a, 0xc8, 0x36, 0x8d, 0xd1, 0x95, 0x55, 0x52, 0xfd, 0xee, 0x95, 0x75, 0x98,
+	0x41, 0xb8, 0x5e, 0x2d, 0x82, 0x32, 0x58, 0x46, 0x32, 0x5c, 0xaf, 0xc4, 0x3b, 0xe4, 0x7d, 0x87,
+	0xdd, 0xb5, 0x38, 0x16, 0x30, 0xe3, 0xd2, 0xd6, 0x37, 0xce, 0x64, 0x9f, 0x33, 0x1e, 0x1e, 0x70,
+	0xbc, 0x82, 0xf8, 0xd5, 0xfc, 0x6c, 0x55, 0xb3, 0x38, 0x2b, 0x83, 0x65, 0x22, 0x39, 0x13, 0xcf,
+	0x90, 0xbe, 0x18, 0xad, 0x2b, 0x77, 0x62, 0xee, 0x00, 0x0c, 0x47, 0x20, 0x41, 0x76, 0x00, 0x59,
+	0xe7, 0x1a, 0x92, 0xae, 0xe2, 0x7a, 0x9f, 0x63, 0x41, 0xa4, 0x70, 0xb1, 0xa9, 0xea, 0x6f, 0x1e,
+	0x23, 0x04, 0xcc, 0xbb, 0x94, 0x61, 0x84, 0xe8, 0xed, 0x43, 0x2b, 0xcf, 0x25, 0xd2, 0xc7, 0x8f,
+	0x7f, 0x41, 0xfb, 0x47, 0x3e, 0x14, 0xde, 0xc3, 0x39, 0x6f, 0x87, 0x39, 0x8d, 0x8f, 0x55, 0x5c,
+	0xd2, 0xf4, 0x36, 0x77, 0x10, 0x77, 0xb3, 0x31, 0xa3, 0xd1, 0x82, 0x45, 0x4e, 0x13, 0xef, 0x5b,
+	0x88, 0x5a, 0x15, 0x9c, 0xd3, 0x40, 0xb0, 0x48, 0x69, 0xe8, 0xf7, 0x19, 0xfb, 0x77, 0x7a, 0xfa,
+	0x1f, 0x00, 0xaf, 0x29, 0x63, 0x2a, 0xca, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -248,6 +325,7 @@ const _ = grpc.SupportPackageIsVersion4
 type ConsensusClient interface {
 	Promise(ctx context.Context, in *PromiseRequest, opts ...grpc.CallOption) (*PromiseResponse, error)
 	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 }
 
 type consensusClient struct {
@@ -276,10 +354,20 @@ func (c *consensusClient) Commit(ctx context.Context, in *CommitRequest, opts ..
 	return out, nil
 }
 
+func (c *consensusClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, "/Consensus/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConsensusServer is the server API for Consensus service.
 type ConsensusServer interface {
 	Promise(context.Context, *PromiseRequest) (*PromiseResponse, error)
 	Commit(context.Context, *CommitRequest) (*CommitResponse, error)
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
 }
 
 // UnimplementedConsensusServer can be embedded to have forward compatible implementations.
@@ -292,6 +380,9 @@ func (*UnimplementedConsensusServer) Promise(ctx context.Context, req *PromiseRe
 func (*UnimplementedConsensusServer) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
 }
+func (*UnimplementedConsensusServer) Ping(ctx context.Context, req *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
 
 func RegisterConsensusServer(s *grpc.Server, srv ConsensusServer) {
 	s.RegisterService(&_Consensus_serviceDesc, srv)
@@ -333,6 +424,24 @@ func _Consensus_Commit_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Consensus_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Consensus/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Consensus_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "Consensus",
 	HandlerType: (*ConsensusServer)(nil),
@@ -345,6 +454,10 @@ var _Consensus_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Commit",
 			Handler:    _Consensus_Commit_Handler,
 		},
+		{
+			MethodName: "Ping",
+			Handler:    _Consensus_Ping_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/consensus/consensus.proto",
@@ -24,6 +24,199 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// A Fault disturbs consensus requests between an instance and its peers. It applies to requests in both directions.
+type Fault struct {
+	// Peer is the peer the fault applies to, empty for all peers
+	Peer string `protobuf:"bytes,1,opt,name=Peer,proto3" json:"Peer,omitempty"`
+	// Latency is added to every request
+	Latency string `protobuf:"bytes,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	// Drop is the percentage of Promise and Commit requests that get lost
+	Drop uint32 `protobuf:"varint,3,opt,name=Drop,proto3" json:"Drop,omitempty"`
+	// Partition fails every request
+	Partition            bool     `protobuf:"varint,4,opt,name=Partition,proto3" json:"Partition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Fault) Reset()         { *m = Fault{} }
+func (m *Fault) String() string { return proto.CompactTextString(m) }
+func (*Fault) ProtoMessage()    {}
+func (*Fault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{0}
+}
+
+func (m *Fault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Fault.Unmarshal(m, b)
+}
+func (m *Fault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Fault.Marshal(b, m, deterministic)
+}
+func (m *Fault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Fault.Merge(m, src)
+}
+func (m *Fault) XXX_Size() int {
+	return xxx_messageInfo_Fault.Size(m)
+}
+func (m *Fault) XXX_DiscardUnknown() {
+	xxx_messageInfo_Fault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Fault proto.InternalMessageInfo
+
+func (m *Fault) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *Fault) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+func (m *Fault) GetDrop() uint32 {
+	if m != nil {
+		return m.Drop
+	}
+	return 0
+}
+
+func (m *Fault) GetPartition() bool {
+	if m != nil {
+		return m.Partition
+	}
+	return false
+}
+
+// A Message is a consensus request an instance sent to a peer while proposing, together with the peer's answer.
+type Message struct {
+	// Phase is either promise or commit
+	Phase string `protobuf:"bytes,1,opt,name=Phase,proto3" json:"Phase,omitempty"`
+	From  string `protobuf:"bytes,2,opt,name=From,proto3" json:"From,omitempty"`
+	To    string `protobuf:"bytes,3,opt,name=To,proto3" json:"To,omitempty"`
+	ID    uint64 `protobuf:"varint,4,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Holder is the holder to commit, empty for promise requests
+	Holder string `protobuf:"bytes,5,opt,name=Holder,proto3" json:"Holder,omitempty"`
+	// AttachedID and AttachedHolder are the previously committed values a peer attached to its promise
+	AttachedID     uint64 `protobuf:"varint,6,opt,name=AttachedID,proto3" json:"AttachedID,omitempty"`
+	AttachedHolder string `protobuf:"bytes,7,opt,name=AttachedHolder,proto3" json:"AttachedHolder,omitempty"`
+	// Outcome is one of yea, nay, failed, or canceled
+	Outcome string `protobuf:"bytes,8,opt,name=Outcome,proto3" json:"Outcome,omitempty"`
+	Error   string `protobuf:"bytes,9,opt,name=Error,proto3" json:"Error,omitempty"`
+	// Sent is when the request was sent, RTT is how long the peer took to answer
+	Sent                 string   `protobuf:"bytes,10,opt,name=Sent,proto3" json:"Sent,omitempty"`
+	RTT                  string   `protobuf:"bytes,11,opt,name=RTT,proto3" json:"RTT,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Message) Reset()         { *m = Message{} }
+func (m *Message) String() string { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()    {}
+func (*Message) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{1}
+}
+
+func (m *Message) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Message.Unmarshal(m, b)
+}
+func (m *Message) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Message.Marshal(b, m, deterministic)
+}
+func (m *Message) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Message.Merge(m, src)
+}
+func (m *Message) XXX_Size() int {
+	return xxx_messageInfo_Message.Size(m)
+}
+func (m *Message) XXX_DiscardUnknown() {
+	xxx_messageInfo_Message.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Message proto.InternalMessageInfo
+
+func (m *Message) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *Message) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Message) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *Message) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *Message) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *Message) GetAttachedID() uint64 {
+	if m != nil {
+		return m.AttachedID
+	}
+	return 0
+}
+
+func (m *Message) GetAttachedHolder() string {
+	if m != nil {
+		return m.AttachedHolder
+	}
+	return ""
+}
+
+func (m *Message) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *Message) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Message) GetSent() string {
+	if m != nil {
+		return m.Sent
+	}
+	return ""
+}
+
+func (m *Message) GetRTT() string {
+	if m != nil {
+		return m.RTT
+	}
+	return ""
+}
+
 type StatusRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -34,7 +227,7 @@ func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
 func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
 func (*StatusRequest) ProtoMessage()    {}
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bd1b96e1722d1ee5, []int{0}
+	return fileDescriptor_bd1b96e1722d1ee5, []int{2}
 }
 
 func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
@@ -63,6 +256,12 @@ type StatusResponse struct {
 	ID                   uint64                 `protobuf:"varint,5,opt,name=ID,proto3" json:"ID,omitempty"`
 	Holder               string                 `protobuf:"bytes,6,opt,name=Holder,proto3" json:"Holder,omitempty"`
 	Peers                []*StatusResponse_Peer `protobuf:"bytes,7,rep,name=Peers,proto3" json:"Peers,omitempty"`
+	Draining             bool                   `protobuf:"varint,8,opt,name=Draining,proto3" json:"Draining,omitempty"`
+	Uptime               string                 `protobuf:"bytes,9,opt,name=Uptime,proto3" json:"Uptime,omitempty"`
+	Version              string                 `protobuf:"bytes,10,opt,name=Version,proto3" json:"Version,omitempty"`
+	Storage              string                 `protobuf:"bytes,11,opt,name=Storage,proto3" json:"Storage,omitempty"`
+	Role                 string                 `protobuf:"bytes,12,opt,name=Role,proto3" json:"Role,omitempty"`
+	Faults               []*Fault               `protobuf:"bytes,13,rep,name=Faults,proto3" json:"Faults,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
 	XXX_unrecognized     []byte                 `json:"-"`
 	XXX_sizecache        int32                  `json:"-"`
@@ -72,7 +271,7 @@ func (m *StatusResponse) Reset()         { *m = StatusResponse{} }
 func (m *StatusResponse) String() string { return proto.CompactTextString(m) }
 func (*StatusResponse) ProtoMessage()    {}
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bd1b96e1722d1ee5, []int{1}
+	return fileDescriptor_bd1b96e1722d1ee5, []int{3}
 }
 
 func (m *StatusResponse) XXX_Unmarshal(b []byte) error {
@@ -142,18 +341,120 @@ func (m *StatusResponse) GetPeers() []*StatusResponse_Peer {
 	return nil
 }
 
-type StatusResponse_Peer struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+func (m *StatusResponse) GetDraining() bool {
+	if m != nil {
+		return m.Draining
+	}
+	return false
+}
+
+func (m *StatusResponse) GetUptime() string {
+	if m != nil {
+		return m.Uptime
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetStorage() string {
+	if m != nil {
+		return m.Storage
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetFaults() []*Fault {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+type StatusResponse_Vote struct {
+	Phase                string   `protobuf:"bytes,1,opt,name=Phase,proto3" json:"Phase,omitempty"`
+	ID                   uint64   `protobuf:"varint,2,opt,name=ID,proto3" json:"ID,omitempty"`
+	Outcome              string   `protobuf:"bytes,3,opt,name=Outcome,proto3" json:"Outcome,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *StatusResponse_Vote) Reset()         { *m = StatusResponse_Vote{} }
+func (m *StatusResponse_Vote) String() string { return proto.CompactTextString(m) }
+func (*StatusResponse_Vote) ProtoMessage()    {}
+func (*StatusResponse_Vote) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{3, 0}
+}
+
+func (m *StatusResponse_Vote) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusResponse_Vote.Unmarshal(m, b)
+}
+func (m *StatusResponse_Vote) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusResponse_Vote.Marshal(b, m, deterministic)
+}
+func (m *StatusResponse_Vote) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusResponse_Vote.Merge(m, src)
+}
+func (m *StatusResponse_Vote) XXX_Size() int {
+	return xxx_messageInfo_StatusResponse_Vote.Size(m)
+}
+func (m *StatusResponse_Vote) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusResponse_Vote.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusResponse_Vote proto.InternalMessageInfo
+
+func (m *StatusResponse_Vote) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *StatusResponse_Vote) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *StatusResponse_Vote) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+type StatusResponse_Peer struct {
+	Name                 string                 `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Reachable            bool                   `protobuf:"varint,2,opt,name=Reachable,proto3" json:"Reachable,omitempty"`
+	Connection           string                 `protobuf:"bytes,3,opt,name=Connection,proto3" json:"Connection,omitempty"`
+	LastSuccess          string                 `protobuf:"bytes,4,opt,name=LastSuccess,proto3" json:"LastSuccess,omitempty"`
+	RTT                  string                 `protobuf:"bytes,5,opt,name=RTT,proto3" json:"RTT,omitempty"`
+	Votes                []*StatusResponse_Vote `protobuf:"bytes,6,rep,name=Votes,proto3" json:"Votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
 func (m *StatusResponse_Peer) Reset()         { *m = StatusResponse_Peer{} }
 func (m *StatusResponse_Peer) String() string { return proto.CompactTextString(m) }
 func (*StatusResponse_Peer) ProtoMessage()    {}
 func (*StatusResponse_Peer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_bd1b96e1722d1ee5, []int{1, 0}
+	return fileDescriptor_bd1b96e1722d1ee5, []int{3, 1}
 }
 
 func (m *StatusResponse_Peer) XXX_Unmarshal(b []byte) error {
@@ -181,89 +482,987 @@ func (m *StatusResponse_Peer) GetName() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*StatusRequest)(nil), "StatusRequest")
-	proto.RegisterType((*StatusResponse)(nil), "StatusResponse")
-	proto.RegisterType((*StatusResponse_Peer)(nil), "StatusResponse.Peer")
+func (m *StatusResponse_Peer) GetReachable() bool {
+	if m != nil {
+		return m.Reachable
+	}
+	return false
 }
 
-func init() { proto.RegisterFile("proto/control/control.proto", fileDescriptor_bd1b96e1722d1ee5) }
+func (m *StatusResponse_Peer) GetConnection() string {
+	if m != nil {
+		return m.Connection
+	}
+	return ""
+}
 
-var fileDescriptor_bd1b96e1722d1ee5 = []byte{
-	// 236 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x41, 0x4e, 0xc3, 0x30,
-	0x10, 0x45, 0x95, 0x34, 0x4d, 0xe8, 0x20, 0x52, 0x69, 0x84, 0xd0, 0x28, 0xb0, 0x88, 0xba, 0x0a,
-	0x2c, 0x8c, 0x54, 0xb8, 0x01, 0x5d, 0x90, 0x0d, 0xaa, 0x0c, 0x17, 0x28, 0xed, 0x2c, 0x2a, 0xd5,
-	0x9e, 0x62, 0x3b, 0x77, 0xe6, 0x18, 0xa8, 0x0e, 0x01, 0x05, 0xb1, 0xf2, 0xfc, 0x67, 0xff, 0x2f,
-	0xcf, 0x87, 0xeb, 0xa3, 0x93, 0x20, 0xf7, 0x5b, 0xb1, 0xc1, 0xc9, 0x61, 0x38, 0x55, 0xa4, 0x8b,
-	0x39, 0x5c, 0xbc, 0x86, 0x4d, 0xe8, 0xbc, 0xe6, 0x8f, 0x8e, 0x7d, 0x58, 0x7c, 0x26, 0x50, 0x0e,
-	0xc4, 0x1f, 0xc5, 0x7a, 0x46, 0x84, 0xec, 0x65, 0x63, 0x98, 0x92, 0x3a, 0x69, 0x66, 0x3a, 0xce,
-	0x78, 0x03, 0xb3, 0xd6, 0x6e, 0x1d, 0x1b, 0xb6, 0x81, 0xd2, 0x3a, 0x69, 0x32, 0xfd, 0x0b, 0x90,
-	0xa0, 0x78, 0xdb, 0x1b, 0x96, 0x2e, 0xd0, 0x24, 0x9a, 0x06, 0x89, 0x15, 0x9c, 0xad, 0x9d, 0x98,
-	0xbd, 0xe7, 0x1d, 0x65, 0xd1, 0xf6, 0xa3, 0xb1, 0x84, 0xb4, 0x5d, 0xd1, 0x34, 0xd2, 0xb4, 0x5d,
-	0xe1, 0x15, 0xe4, 0xcf, 0x72, 0xd8, 0xb1, 0xa3, 0x3c, 0x86, 0x7c, 0x2b, 0xbc, 0x83, 0xe9, 0x9a,
-	0xd9, 0x79, 0x2a, 0xea, 0x49, 0x73, 0xbe, 0xbc, 0x54, 0xe3, 0xff, 0xaa, 0xd3, 0xa5, 0xee, 0x9f,
-	0x54, 0x15, 0x64, 0xa7, 0xe1, 0xbf, 0x1d, 0x96, 0x8f, 0x50, 0x3c, 0xf5, 0x65, 0xe0, 0x2d, 0xe4,
-	0x7d, 0x08, 0x96, 0x6a, 0xd4, 0x47, 0x35, 0xff, 0x93, 0xfe, 0x9e, 0xc7, 0xe2, 0x1e, 0xbe, 0x02,
-	0x00, 0x00, 0xff, 0xff, 0x37, 0xde, 0x0e, 0x5a, 0x57, 0x01, 0x00, 0x00,
+func (m *StatusResponse_Peer) GetLastSuccess() string {
+	if m != nil {
+		return m.LastSuccess
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *StatusResponse_Peer) GetRTT() string {
+	if m != nil {
+		return m.RTT
+	}
+	return ""
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *StatusResponse_Peer) GetVotes() []*StatusResponse_Vote {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
 
-// ControlClient is the client API for Control service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ControlClient interface {
-	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+type WatchStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type controlClient struct {
-	cc *grpc.ClientConn
+func (m *WatchStatusRequest) Reset()         { *m = WatchStatusRequest{} }
+func (m *WatchStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchStatusRequest) ProtoMessage()    {}
+func (*WatchStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{4}
 }
 
-func NewControlClient(cc *grpc.ClientConn) ControlClient {
-	return &controlClient{cc}
+func (m *WatchStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchStatusRequest.Unmarshal(m, b)
+}
+func (m *WatchStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchStatusRequest.Merge(m, src)
+}
+func (m *WatchStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchStatusRequest.Size(m)
+}
+func (m *WatchStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchStatusRequest.DiscardUnknown(m)
 }
 
-func (c *controlClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
-	out := new(StatusResponse)
-	err := c.cc.Invoke(ctx, "/Control/Status", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+var xxx_messageInfo_WatchStatusRequest proto.InternalMessageInfo
+
+type ProbePeersRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// ControlServer is the server API for Control service.
-type ControlServer interface {
-	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+func (m *ProbePeersRequest) Reset()         { *m = ProbePeersRequest{} }
+func (m *ProbePeersRequest) String() string { return proto.CompactTextString(m) }
+func (*ProbePeersRequest) ProtoMessage()    {}
+func (*ProbePeersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{5}
 }
 
-// UnimplementedControlServer can be embedded to have forward compatible implementations.
-type UnimplementedControlServer struct {
+func (m *ProbePeersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProbePeersRequest.Unmarshal(m, b)
+}
+func (m *ProbePeersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProbePeersRequest.Marshal(b, m, deterministic)
+}
+func (m *ProbePeersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProbePeersRequest.Merge(m, src)
+}
+func (m *ProbePeersRequest) XXX_Size() int {
+	return xxx_messageInfo_ProbePeersRequest.Size(m)
+}
+func (m *ProbePeersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProbePeersRequest.DiscardUnknown(m)
 }
 
-func (*UnimplementedControlServer) Status(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+var xxx_messageInfo_ProbePeersRequest proto.InternalMessageInfo
+
+type ProbePeersResponse struct {
+	Probes               []*ProbePeersResponse_Probe `protobuf:"bytes,1,rep,name=Probes,proto3" json:"Probes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                    `json:"-"`
+	XXX_unrecognized     []byte                      `json:"-"`
+	XXX_sizecache        int32                       `json:"-"`
 }
 
-func RegisterControlServer(s *grpc.Server, srv ControlServer) {
-	s.RegisterService(&_Control_serviceDesc, srv)
+func (m *ProbePeersResponse) Reset()         { *m = ProbePeersResponse{} }
+func (m *ProbePeersResponse) String() string { return proto.CompactTextString(m) }
+func (*ProbePeersResponse) ProtoMessage()    {}
+func (*ProbePeersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{6}
 }
 
-func _Control_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StatusRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ControlServer).Status(ctx, in)
+func (m *ProbePeersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProbePeersResponse.Unmarshal(m, b)
+}
+func (m *ProbePeersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProbePeersResponse.Marshal(b, m, deterministic)
+}
+func (m *ProbePeersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProbePeersResponse.Merge(m, src)
+}
+func (m *ProbePeersResponse) XXX_Size() int {
+	return xxx_messageInfo_ProbePeersResponse.Size(m)
+}
+func (m *ProbePeersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProbePeersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProbePeersResponse proto.InternalMessageInfo
+
+func (m *ProbePeersResponse) GetProbes() []*ProbePeersResponse_Probe {
+	if m != nil {
+		return m.Probes
+	}
+	return nil
+}
+
+type ProbePeersResponse_Probe struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Reachable            bool     `protobuf:"varint,2,opt,name=Reachable,proto3" json:"Reachable,omitempty"`
+	RTT                  string   `protobuf:"bytes,3,opt,name=RTT,proto3" json:"RTT,omitempty"`
+	Error                string   `protobuf:"bytes,4,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProbePeersResponse_Probe) Reset()         { *m = ProbePeersResponse_Probe{} }
+func (m *ProbePeersResponse_Probe) String() string { return proto.CompactTextString(m) }
+func (*ProbePeersResponse_Probe) ProtoMessage()    {}
+func (*ProbePeersResponse_Probe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{6, 0}
+}
+
+func (m *ProbePeersResponse_Probe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProbePeersResponse_Probe.Unmarshal(m, b)
+}
+func (m *ProbePeersResponse_Probe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProbePeersResponse_Probe.Marshal(b, m, deterministic)
+}
+func (m *ProbePeersResponse_Probe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProbePeersResponse_Probe.Merge(m, src)
+}
+func (m *ProbePeersResponse_Probe) XXX_Size() int {
+	return xxx_messageInfo_ProbePeersResponse_Probe.Size(m)
+}
+func (m *ProbePeersResponse_Probe) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProbePeersResponse_Probe.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProbePeersResponse_Probe proto.InternalMessageInfo
+
+func (m *ProbePeersResponse_Probe) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ProbePeersResponse_Probe) GetReachable() bool {
+	if m != nil {
+		return m.Reachable
+	}
+	return false
+}
+
+func (m *ProbePeersResponse_Probe) GetRTT() string {
+	if m != nil {
+		return m.RTT
+	}
+	return ""
+}
+
+func (m *ProbePeersResponse_Probe) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type DrainRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DrainRequest) Reset()         { *m = DrainRequest{} }
+func (m *DrainRequest) String() string { return proto.CompactTextString(m) }
+func (*DrainRequest) ProtoMessage()    {}
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{7}
+}
+
+func (m *DrainRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DrainRequest.Unmarshal(m, b)
+}
+func (m *DrainRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DrainRequest.Marshal(b, m, deterministic)
+}
+func (m *DrainRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DrainRequest.Merge(m, src)
+}
+func (m *DrainRequest) XXX_Size() int {
+	return xxx_messageInfo_DrainRequest.Size(m)
+}
+func (m *DrainRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DrainRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DrainRequest proto.InternalMessageInfo
+
+type DrainResponse struct {
+	Drained              bool     `protobuf:"varint,1,opt,name=Drained,proto3" json:"Drained,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DrainResponse) Reset()         { *m = DrainResponse{} }
+func (m *DrainResponse) String() string { return proto.CompactTextString(m) }
+func (*DrainResponse) ProtoMessage()    {}
+func (*DrainResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{8}
+}
+
+func (m *DrainResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DrainResponse.Unmarshal(m, b)
+}
+func (m *DrainResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DrainResponse.Marshal(b, m, deterministic)
+}
+func (m *DrainResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DrainResponse.Merge(m, src)
+}
+func (m *DrainResponse) XXX_Size() int {
+	return xxx_messageInfo_DrainResponse.Size(m)
+}
+func (m *DrainResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DrainResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DrainResponse proto.InternalMessageInfo
+
+func (m *DrainResponse) GetDrained() bool {
+	if m != nil {
+		return m.Drained
+	}
+	return false
+}
+
+type InjectFaultRequest struct {
+	// Faults replace the faults injected for the same peers. A fault that disturbs nothing heals its peer.
+	Faults []*Fault `protobuf:"bytes,1,rep,name=Faults,proto3" json:"Faults,omitempty"`
+	// Clear heals all peers before Faults are injected
+	Clear                bool     `protobuf:"varint,2,opt,name=Clear,proto3" json:"Clear,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InjectFaultRequest) Reset()         { *m = InjectFaultRequest{} }
+func (m *InjectFaultRequest) String() string { return proto.CompactTextString(m) }
+func (*InjectFaultRequest) ProtoMessage()    {}
+func (*InjectFaultRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{9}
+}
+
+func (m *InjectFaultRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InjectFaultRequest.Unmarshal(m, b)
+}
+func (m *InjectFaultRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InjectFaultRequest.Marshal(b, m, deterministic)
+}
+func (m *InjectFaultRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InjectFaultRequest.Merge(m, src)
+}
+func (m *InjectFaultRequest) XXX_Size() int {
+	return xxx_messageInfo_InjectFaultRequest.Size(m)
+}
+func (m *InjectFaultRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InjectFaultRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InjectFaultRequest proto.InternalMessageInfo
+
+func (m *InjectFaultRequest) GetFaults() []*Fault {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+func (m *InjectFaultRequest) GetClear() bool {
+	if m != nil {
+		return m.Clear
+	}
+	return false
+}
+
+type InjectFaultResponse struct {
+	Faults               []*Fault `protobuf:"bytes,1,rep,name=Faults,proto3" json:"Faults,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InjectFaultResponse) Reset()         { *m = InjectFaultResponse{} }
+func (m *InjectFaultResponse) String() string { return proto.CompactTextString(m) }
+func (*InjectFaultResponse) ProtoMessage()    {}
+func (*InjectFaultResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{10}
+}
+
+func (m *InjectFaultResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InjectFaultResponse.Unmarshal(m, b)
+}
+func (m *InjectFaultResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InjectFaultResponse.Marshal(b, m, deterministic)
+}
+func (m *InjectFaultResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InjectFaultResponse.Merge(m, src)
+}
+func (m *InjectFaultResponse) XXX_Size() int {
+	return xxx_messageInfo_InjectFaultResponse.Size(m)
+}
+func (m *InjectFaultResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InjectFaultResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InjectFaultResponse proto.InternalMessageInfo
+
+func (m *InjectFaultResponse) GetFaults() []*Fault {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+type TraceRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TraceRequest) Reset()         { *m = TraceRequest{} }
+func (m *TraceRequest) String() string { return proto.CompactTextString(m) }
+func (*TraceRequest) ProtoMessage()    {}
+func (*TraceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{11}
+}
+
+func (m *TraceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TraceRequest.Unmarshal(m, b)
+}
+func (m *TraceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TraceRequest.Marshal(b, m, deterministic)
+}
+func (m *TraceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceRequest.Merge(m, src)
+}
+func (m *TraceRequest) XXX_Size() int {
+	return xxx_messageInfo_TraceRequest.Size(m)
+}
+func (m *TraceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceRequest proto.InternalMessageInfo
+
+type TraceResponse struct {
+	// Messages are the most recent messages of the instance, oldest first
+	Messages             []*Message `protobuf:"bytes,1,rep,name=Messages,proto3" json:"Messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *TraceResponse) Reset()         { *m = TraceResponse{} }
+func (m *TraceResponse) String() string { return proto.CompactTextString(m) }
+func (*TraceResponse) ProtoMessage()    {}
+func (*TraceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{12}
+}
+
+func (m *TraceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TraceResponse.Unmarshal(m, b)
+}
+func (m *TraceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TraceResponse.Marshal(b, m, deterministic)
+}
+func (m *TraceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceResponse.Merge(m, src)
+}
+func (m *TraceResponse) XXX_Size() int {
+	return xxx_messageInfo_TraceResponse.Size(m)
+}
+func (m *TraceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceResponse proto.InternalMessageInfo
+
+func (m *TraceResponse) GetMessages() []*Message {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// A HeldMessage is a consensus request held back in step mode until it is delivered, delayed, or dropped.
+type HeldMessage struct {
+	// Seq identifies the message within the instance
+	Seq uint64 `protobuf:"varint,1,opt,name=Seq,proto3" json:"Seq,omitempty"`
+	// Phase is either promise or commit
+	Phase string `protobuf:"bytes,2,opt,name=Phase,proto3" json:"Phase,omitempty"`
+	To    string `protobuf:"bytes,3,opt,name=To,proto3" json:"To,omitempty"`
+	ID    uint64 `protobuf:"varint,4,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Holder is the holder to commit, empty for promise requests
+	Holder string `protobuf:"bytes,5,opt,name=Holder,proto3" json:"Holder,omitempty"`
+	// Held is how long the message has been held back
+	Held                 string   `protobuf:"bytes,6,opt,name=Held,proto3" json:"Held,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HeldMessage) Reset()         { *m = HeldMessage{} }
+func (m *HeldMessage) String() string { return proto.CompactTextString(m) }
+func (*HeldMessage) ProtoMessage()    {}
+func (*HeldMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{13}
+}
+
+func (m *HeldMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeldMessage.Unmarshal(m, b)
+}
+func (m *HeldMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeldMessage.Marshal(b, m, deterministic)
+}
+func (m *HeldMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeldMessage.Merge(m, src)
+}
+func (m *HeldMessage) XXX_Size() int {
+	return xxx_messageInfo_HeldMessage.Size(m)
+}
+func (m *HeldMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeldMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeldMessage proto.InternalMessageInfo
+
+func (m *HeldMessage) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *HeldMessage) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *HeldMessage) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *HeldMessage) GetID() uint64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *HeldMessage) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *HeldMessage) GetHeld() string {
+	if m != nil {
+		return m.Held
+	}
+	return ""
+}
+
+type StepRequest struct {
+	// Enable turns step mode on, Disable turns it off and delivers all held messages
+	Enable  bool `protobuf:"varint,1,opt,name=Enable,proto3" json:"Enable,omitempty"`
+	Disable bool `protobuf:"varint,2,opt,name=Disable,proto3" json:"Disable,omitempty"`
+	// Action is one of deliver, delay, or drop, empty to only list held messages
+	Action string `protobuf:"bytes,3,opt,name=Action,proto3" json:"Action,omitempty"`
+	// Messages are the sequence numbers of the messages to act on, empty for all held messages
+	Messages []uint64 `protobuf:"varint,4,rep,packed,name=Messages,proto3" json:"Messages,omitempty"`
+	// Delay is how long delayed messages wait before they are delivered
+	Delay                string   `protobuf:"bytes,5,opt,name=Delay,proto3" json:"Delay,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StepRequest) Reset()         { *m = StepRequest{} }
+func (m *StepRequest) String() string { return proto.CompactTextString(m) }
+func (*StepRequest) ProtoMessage()    {}
+func (*StepRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{14}
+}
+
+func (m *StepRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StepRequest.Unmarshal(m, b)
+}
+func (m *StepRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StepRequest.Marshal(b, m, deterministic)
+}
+func (m *StepRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StepRequest.Merge(m, src)
+}
+func (m *StepRequest) XXX_Size() int {
+	return xxx_messageInfo_StepRequest.Size(m)
+}
+func (m *StepRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StepRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StepRequest proto.InternalMessageInfo
+
+func (m *StepRequest) GetEnable() bool {
+	if m != nil {
+		return m.Enable
+	}
+	return false
+}
+
+func (m *StepRequest) GetDisable() bool {
+	if m != nil {
+		return m.Disable
+	}
+	return false
+}
+
+func (m *StepRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *StepRequest) GetMessages() []uint64 {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+func (m *StepRequest) GetDelay() string {
+	if m != nil {
+		return m.Delay
+	}
+	return ""
+}
+
+type StepResponse struct {
+	Enabled bool `protobuf:"varint,1,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+	// Stepped are the messages the action was applied to
+	Stepped []*HeldMessage `protobuf:"bytes,2,rep,name=Stepped,proto3" json:"Stepped,omitempty"`
+	// Held are the messages still held back, oldest first
+	Held                 []*HeldMessage `protobuf:"bytes,3,rep,name=Held,proto3" json:"Held,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *StepResponse) Reset()         { *m = StepResponse{} }
+func (m *StepResponse) String() string { return proto.CompactTextString(m) }
+func (*StepResponse) ProtoMessage()    {}
+func (*StepResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{15}
+}
+
+func (m *StepResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StepResponse.Unmarshal(m, b)
+}
+func (m *StepResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StepResponse.Marshal(b, m, deterministic)
+}
+func (m *StepResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StepResponse.Merge(m, src)
+}
+func (m *StepResponse) XXX_Size() int {
+	return xxx_messageInfo_StepResponse.Size(m)
+}
+func (m *StepResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StepResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StepResponse proto.InternalMessageInfo
+
+func (m *StepResponse) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *StepResponse) GetStepped() []*HeldMessage {
+	if m != nil {
+		return m.Stepped
+	}
+	return nil
+}
+
+func (m *StepResponse) GetHeld() []*HeldMessage {
+	if m != nil {
+		return m.Held
+	}
+	return nil
+}
+
+type ReloadRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadRequest) Reset()         { *m = ReloadRequest{} }
+func (m *ReloadRequest) String() string { return proto.CompactTextString(m) }
+func (*ReloadRequest) ProtoMessage()    {}
+func (*ReloadRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{16}
+}
+
+func (m *ReloadRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadRequest.Unmarshal(m, b)
+}
+func (m *ReloadRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadRequest.Marshal(b, m, deterministic)
+}
+func (m *ReloadRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadRequest.Merge(m, src)
+}
+func (m *ReloadRequest) XXX_Size() int {
+	return xxx_messageInfo_ReloadRequest.Size(m)
+}
+func (m *ReloadRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadRequest proto.InternalMessageInfo
+
+type ReloadResponse struct {
+	Changes              []string `protobuf:"bytes,1,rep,name=Changes,proto3" json:"Changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadResponse) Reset()         { *m = ReloadResponse{} }
+func (m *ReloadResponse) String() string { return proto.CompactTextString(m) }
+func (*ReloadResponse) ProtoMessage()    {}
+func (*ReloadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bd1b96e1722d1ee5, []int{17}
+}
+
+func (m *ReloadResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadResponse.Unmarshal(m, b)
+}
+func (m *ReloadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadResponse.Marshal(b, m, deterministic)
+}
+func (m *ReloadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadResponse.Merge(m, src)
+}
+func (m *ReloadResponse) XXX_Size() int {
+	return xxx_messageInfo_ReloadResponse.Size(m)
+}
+func (m *ReloadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadResponse proto.InternalMessageInfo
+
+func (m *ReloadResponse) GetChanges() []string {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Fault)(nil), "Fault")
+	proto.RegisterType((*Message)(nil), "Message")
+	proto.RegisterType((*StatusRequest)(nil), "StatusRequest")
+	proto.RegisterType((*StatusResponse)(nil), "StatusResponse")
+	proto.RegisterType((*StatusResponse_Vote)(nil), "StatusResponse.Vote")
+	proto.RegisterType((*StatusResponse_Peer)(nil), "StatusResponse.Peer")
+	proto.RegisterType((*WatchStatusRequest)(nil), "WatchStatusRequest")
+	proto.RegisterType((*ProbePeersRequest)(nil), "ProbePeersRequest")
+	proto.RegisterType((*ProbePeersResponse)(nil), "ProbePeersResponse")
+	proto.RegisterType((*ProbePeersResponse_Probe)(nil), "ProbePeersResponse.Probe")
+	proto.RegisterType((*DrainRequest)(nil), "DrainRequest")
+	proto.RegisterType((*DrainResponse)(nil), "DrainResponse")
+	proto.RegisterType((*InjectFaultRequest)(nil), "InjectFaultRequest")
+	proto.RegisterType((*InjectFaultResponse)(nil), "InjectFaultResponse")
+	proto.RegisterType((*TraceRequest)(nil), "TraceRequest")
+	proto.RegisterType((*TraceResponse)(nil), "TraceResponse")
+	proto.RegisterType((*HeldMessage)(nil), "HeldMessage")
+	proto.RegisterType((*StepRequest)(nil), "StepRequest")
+	proto.RegisterType((*StepResponse)(nil), "StepResponse")
+	proto.RegisterType((*ReloadRequest)(nil), "ReloadRequest")
+	proto.RegisterType((*ReloadResponse)(nil), "ReloadResponse")
+}
+
+func init() { proto.RegisterFile("proto/control/control.proto", fileDescriptor_bd1b96e1722d1ee5) }
+
+var fileDescriptor_bd1b96e1722d1ee5 = []byte{
+	// 951 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcd, 0x6e, 0x1b, 0x37,
+	0x10, 0xc6, 0xae, 0x56, 0x2b, 0x69, 0xf4, 0xe3, 0x96, 0x32, 0x02, 0x76, 0x5b, 0x04, 0xc2, 0xb6,
+	0x30, 0x9c, 0x1c, 0xd8, 0x36, 0x85, 0x81, 0x5e, 0x03, 0x2b, 0x46, 0x54, 0xa4, 0xad, 0x40, 0xa9,
+	0xe9, 0x99, 0x5e, 0x11, 0x96, 0x0a, 0x69, 0xa9, 0x70, 0xa9, 0x43, 0x8e, 0x3d, 0xe6, 0x55, 0xfa,
+	0x04, 0x7d, 0xa7, 0xbe, 0x42, 0x0f, 0x05, 0x87, 0xa4, 0xb4, 0xb2, 0x14, 0x14, 0xc8, 0xc9, 0xfc,
+	0x3e, 0x72, 0x39, 0x33, 0xdf, 0xcc, 0x47, 0x0b, 0xbe, 0xdc, 0x6a, 0x65, 0xd4, 0xb7, 0x85, 0x2a,
+	0x8d, 0x56, 0xeb, 0xf0, 0x97, 0x21, 0x9b, 0x3f, 0x40, 0xf3, 0x4e, 0xec, 0xd6, 0x86, 0x10, 0x48,
+	0xa6, 0x52, 0x6a, 0x1a, 0x8d, 0xa2, 0xeb, 0x0e, 0xc7, 0x35, 0xa1, 0xd0, 0x7a, 0x23, 0x8c, 0x2c,
+	0x8b, 0xf7, 0x34, 0x46, 0x3a, 0x40, 0x7b, 0x7a, 0xac, 0xd5, 0x96, 0x36, 0x46, 0xd1, 0x75, 0x9f,
+	0xe3, 0x9a, 0x7c, 0x05, 0x9d, 0xa9, 0xd0, 0x66, 0x65, 0x56, 0xaa, 0xa4, 0xc9, 0x28, 0xba, 0x6e,
+	0xf3, 0x03, 0x91, 0x7f, 0x88, 0xa1, 0xf5, 0xb3, 0xac, 0x2a, 0xf1, 0x20, 0xc9, 0x25, 0x34, 0xa7,
+	0x4b, 0x51, 0x49, 0x1f, 0xcc, 0x01, 0x7b, 0xe7, 0x9d, 0x56, 0x1b, 0x1f, 0x0a, 0xd7, 0x64, 0x00,
+	0xf1, 0x5c, 0x61, 0x94, 0x0e, 0x8f, 0xe7, 0xca, 0xe2, 0xc9, 0x18, 0x2f, 0x4f, 0x78, 0x3c, 0x19,
+	0x93, 0x27, 0x90, 0xbe, 0x56, 0xeb, 0x85, 0xd4, 0xb4, 0x89, 0x67, 0x3c, 0x22, 0x4f, 0x01, 0x5e,
+	0x1a, 0x23, 0x8a, 0xa5, 0x5c, 0x4c, 0xc6, 0x34, 0xc5, 0xf3, 0x35, 0x86, 0x5c, 0xc1, 0x20, 0x20,
+	0xff, 0x7d, 0x0b, 0xbf, 0x7f, 0xc4, 0x5a, 0x05, 0x7e, 0xdd, 0x99, 0x42, 0x6d, 0x24, 0x6d, 0x3b,
+	0x05, 0x3c, 0xb4, 0x35, 0xbc, 0xd2, 0x5a, 0x69, 0xda, 0x71, 0x35, 0x20, 0xb0, 0x35, 0xcc, 0x64,
+	0x69, 0x28, 0xb8, 0x1a, 0xec, 0x9a, 0x7c, 0x06, 0x0d, 0x3e, 0x9f, 0xd3, 0x2e, 0x52, 0x76, 0x99,
+	0x5f, 0x40, 0x7f, 0x66, 0x84, 0xd9, 0x55, 0x5c, 0xbe, 0xdb, 0xc9, 0xca, 0xe4, 0xff, 0x24, 0x30,
+	0x08, 0x4c, 0xb5, 0x55, 0xa5, 0x53, 0xe3, 0x17, 0xb1, 0x09, 0x12, 0xe1, 0xda, 0x2a, 0x3c, 0x29,
+	0x0b, 0x2d, 0x37, 0x36, 0x44, 0x8c, 0x45, 0x1d, 0x08, 0x9b, 0xeb, 0x7c, 0xb5, 0x91, 0x6a, 0x67,
+	0xbc, 0x60, 0x01, 0x92, 0x0c, 0xda, 0x53, 0xad, 0x36, 0xab, 0x4a, 0x2e, 0xbc, 0x76, 0x7b, 0xec,
+	0x15, 0x6d, 0x9e, 0x51, 0x34, 0x3d, 0x52, 0xf4, 0x39, 0x34, 0xed, 0x4c, 0x54, 0xb4, 0x35, 0x6a,
+	0x5c, 0x77, 0x5f, 0x5c, 0xb2, 0xe3, 0x7c, 0x99, 0xdd, 0xe4, 0xee, 0x88, 0x8d, 0x37, 0xd6, 0x62,
+	0x55, 0xae, 0xca, 0x07, 0x94, 0xad, 0xcd, 0xf7, 0xd8, 0xde, 0xff, 0xdb, 0xd6, 0xac, 0x36, 0xd2,
+	0x0b, 0xe7, 0x91, 0xcd, 0xfe, 0xad, 0xd4, 0x95, 0x9d, 0x1d, 0x27, 0x5e, 0x80, 0x76, 0x67, 0x66,
+	0x94, 0x16, 0x0f, 0xd2, 0x6b, 0x18, 0xa0, 0xd5, 0x88, 0xab, 0xb5, 0xa4, 0x3d, 0xa7, 0x91, 0x5d,
+	0x93, 0xa7, 0x90, 0xe2, 0x40, 0x57, 0xb4, 0x8f, 0x89, 0xa6, 0x0c, 0x21, 0xf7, 0x6c, 0x76, 0x07,
+	0xc9, 0x5b, 0x65, 0x3e, 0x36, 0x83, 0x4e, 0x8d, 0x78, 0xaf, 0x46, 0xad, 0xff, 0x8d, 0xa3, 0xfe,
+	0x67, 0x7f, 0x47, 0xce, 0x30, 0x1f, 0x6b, 0x14, 0x97, 0xa2, 0x58, 0x8a, 0xfb, 0xb5, 0xc4, 0xdb,
+	0xda, 0xfc, 0x40, 0xd8, 0xe1, 0xbc, 0x55, 0x65, 0x29, 0x0b, 0x74, 0x8a, 0xbb, 0xb7, 0xc6, 0x90,
+	0x11, 0x74, 0xdf, 0x88, 0xca, 0xcc, 0x76, 0x45, 0x21, 0xab, 0x0a, 0x3b, 0xd6, 0xe1, 0x75, 0x2a,
+	0x8c, 0x54, 0x73, 0x3f, 0x52, 0xb6, 0x3d, 0xb6, 0xac, 0x8a, 0xa6, 0xe7, 0xdb, 0x63, 0x37, 0xb9,
+	0x3b, 0x92, 0x5f, 0x02, 0xf9, 0x5d, 0x98, 0x62, 0x79, 0x3c, 0x83, 0x43, 0xf8, 0x7c, 0xaa, 0xd5,
+	0xbd, 0xc4, 0x16, 0x06, 0xf2, 0xaf, 0x08, 0x48, 0x9d, 0xf5, 0xc3, 0xf9, 0x3d, 0xa4, 0xc8, 0x56,
+	0x34, 0xc2, 0x70, 0x5f, 0xb0, 0xd3, 0x43, 0x8e, 0xe2, 0xfe, 0x60, 0x26, 0xa0, 0x89, 0xab, 0x4f,
+	0xd0, 0xcb, 0x57, 0xdb, 0x38, 0x54, 0xbb, 0x37, 0x5f, 0x52, 0x33, 0x5f, 0x3e, 0x80, 0x1e, 0x8e,
+	0x59, 0x48, 0xfe, 0x19, 0xf4, 0x3d, 0xf6, 0x69, 0x53, 0x68, 0x21, 0x21, 0x17, 0x18, 0xbd, 0xcd,
+	0x03, 0xcc, 0x7f, 0x02, 0x32, 0x29, 0xff, 0x90, 0x85, 0x71, 0xc3, 0xe2, 0x2e, 0xa8, 0xcd, 0x52,
+	0x74, 0x6e, 0x96, 0x6c, 0x1a, 0xb7, 0x6b, 0x29, 0xb4, 0x4f, 0xd9, 0x81, 0xfc, 0x06, 0x86, 0x47,
+	0x77, 0xf9, 0xe0, 0xff, 0x73, 0x99, 0xcd, 0x7e, 0xae, 0x45, 0x21, 0x43, 0xf6, 0x37, 0xd0, 0xf7,
+	0xd8, 0x5f, 0xf0, 0x0d, 0xb4, 0xfd, 0x03, 0x1a, 0xae, 0x68, 0x33, 0x4f, 0xf0, 0xfd, 0x4e, 0xfe,
+	0x67, 0x04, 0xdd, 0xd7, 0x72, 0xbd, 0xf0, 0x84, 0x15, 0x6f, 0x26, 0xdf, 0x61, 0xbd, 0x09, 0xb7,
+	0xcb, 0xc3, 0xe4, 0xc7, 0x8f, 0x26, 0xff, 0x93, 0x5e, 0x5a, 0x02, 0x89, 0x0d, 0xe7, 0x5f, 0x0b,
+	0x5c, 0xe7, 0x1f, 0x22, 0xe8, 0xce, 0x8c, 0xdc, 0x06, 0x1d, 0x9f, 0x40, 0xfa, 0xaa, 0xc4, 0xde,
+	0x3a, 0xd9, 0x3d, 0xc2, 0x7e, 0xac, 0xaa, 0x5a, 0xd3, 0x03, 0xb4, 0x5f, 0xbc, 0xac, 0xdb, 0xc3,
+	0x23, 0xfb, 0xb2, 0xec, 0x35, 0x48, 0x46, 0x0d, 0xfb, 0x92, 0x05, 0x6c, 0xeb, 0x1a, 0xcb, 0xb5,
+	0x78, 0xef, 0x13, 0x74, 0x20, 0xd7, 0xd0, 0x73, 0xa9, 0x1c, 0x66, 0xc0, 0x45, 0xdf, 0xcf, 0x80,
+	0x87, 0xe4, 0xca, 0xbe, 0x33, 0x72, 0xbb, 0x95, 0x0b, 0x1a, 0xa3, 0xbc, 0x3d, 0x56, 0x13, 0x92,
+	0x87, 0x4d, 0x32, 0xf2, 0x15, 0x37, 0xce, 0x1c, 0x72, 0xf5, 0x5f, 0x40, 0x9f, 0xcb, 0xb5, 0x12,
+	0x8b, 0xd0, 0xcb, 0xe7, 0x30, 0x08, 0xc4, 0x21, 0x8d, 0xdb, 0xa5, 0x28, 0x43, 0x2f, 0x3b, 0x3c,
+	0xc0, 0x17, 0xff, 0xc6, 0xd0, 0xba, 0x75, 0xff, 0xa3, 0xc9, 0x33, 0x48, 0x9d, 0x49, 0xc9, 0x80,
+	0x1d, 0xb9, 0x35, 0xbb, 0x78, 0x64, 0x70, 0x72, 0x03, 0xdd, 0x9a, 0xa9, 0xc9, 0x90, 0x9d, 0x5a,
+	0xfc, 0xe4, 0xa3, 0xef, 0x22, 0x72, 0x03, 0x70, 0xb0, 0x2e, 0x21, 0xec, 0xe4, 0x09, 0xc8, 0x86,
+	0x67, 0xbc, 0x4d, 0xae, 0xa0, 0x89, 0xd6, 0x21, 0x7d, 0x56, 0xb7, 0x5c, 0x36, 0x60, 0xc7, 0x8e,
+	0x7b, 0x06, 0xa9, 0x2b, 0x9c, 0x0c, 0xd8, 0x91, 0x24, 0xd9, 0x05, 0x7b, 0xa4, 0xc8, 0x8f, 0xd0,
+	0xad, 0xd9, 0x86, 0x0c, 0xd9, 0xa9, 0x21, 0xb3, 0x4b, 0x76, 0xce, 0x59, 0x57, 0xd0, 0x44, 0xa7,
+	0x90, 0x3e, 0xab, 0x3b, 0x28, 0x1b, 0xb0, 0x63, 0x03, 0x7d, 0x0d, 0x89, 0xed, 0x21, 0xe9, 0xb1,
+	0xda, 0x70, 0x66, 0x7d, 0x56, 0x9f, 0x8f, 0xfb, 0x14, 0x7f, 0x17, 0xfd, 0xf0, 0xdf, 0x00, 0x89,
+	0xdf, 0xd8, 0x48, 0x36, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ControlClient is the client API for Control service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ControlClient interface {
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	WatchStatus(ctx context.Context, in *WatchStatusRequest, opts ...grpc.CallOption) (Control_WatchStatusClient, error)
+	ProbePeers(ctx context.Context, in *ProbePeersRequest, opts ...grpc.CallOption) (*ProbePeersResponse, error)
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error)
+	Reload(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ReloadResponse, error)
+	InjectFault(ctx context.Context, in *InjectFaultRequest, opts ...grpc.CallOption) (*InjectFaultResponse, error)
+	Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error)
+	Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error)
+}
+
+type controlClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewControlClient(cc *grpc.ClientConn) ControlClient {
+	return &controlClient{cc}
+}
+
+func (c *controlClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/Control/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) WatchStatus(ctx context.Context, in *WatchStatusRequest, opts ...grpc.CallOption) (Control_WatchStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Control_serviceDesc.Streams[0], "/Control/WatchStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controlWatchStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Control_WatchStatusClient interface {
+	Recv() (*StatusResponse, error)
+	grpc.ClientStream
+}
+
+type controlWatchStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *controlWatchStatusClient) Recv() (*StatusResponse, error) {
+	m := new(StatusResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controlClient) ProbePeers(ctx context.Context, in *ProbePeersRequest, opts ...grpc.CallOption) (*ProbePeersResponse, error) {
+	out := new(ProbePeersResponse)
+	err := c.cc.Invoke(ctx, "/Control/ProbePeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error) {
+	out := new(DrainResponse)
+	err := c.cc.Invoke(ctx, "/Control/Drain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Reload(ctx context.Context, in *ReloadRequest, opts ...grpc.CallOption) (*ReloadResponse, error) {
+	out := new(ReloadResponse)
+	err := c.cc.Invoke(ctx, "/Control/Reload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) InjectFault(ctx context.Context, in *InjectFaultRequest, opts ...grpc.CallOption) (*InjectFaultResponse, error) {
+	out := new(InjectFaultResponse)
+	err := c.cc.Invoke(ctx, "/Control/InjectFault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error) {
+	out := new(TraceResponse)
+	err := c.cc.Invoke(ctx, "/Control/Trace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error) {
+	out := new(StepResponse)
+	err := c.cc.Invoke(ctx, "/Control/Step", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ControlServer is the server API for Control service.
+type ControlServer interface {
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	WatchStatus(*WatchStatusRequest, Control_WatchStatusServer) error
+	ProbePeers(context.Context, *ProbePeersRequest) (*ProbePeersResponse, error)
+	Drain(context.Context, *DrainRequest) (*DrainResponse, error)
+	Reload(context.Context, *ReloadRequest) (*ReloadResponse, error)
+	InjectFault(context.Context, *InjectFaultRequest) (*InjectFaultResponse, error)
+	Trace(context.Context, *TraceRequest) (*TraceResponse, error)
+	Step(context.Context, *StepRequest) (*StepResponse, error)
+}
+
+// UnimplementedControlServer can be embedded to have forward compatible implementations.
+type UnimplementedControlServer struct {
+}
+
+func (*UnimplementedControlServer) Status(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedControlServer) WatchStatus(req *WatchStatusRequest, srv Control_WatchStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStatus not implemented")
+}
+func (*UnimplementedControlServer) ProbePeers(ctx context.Context, req *ProbePeersRequest) (*ProbePeersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProbePeers not implemented")
+}
+func (*UnimplementedControlServer) Drain(ctx context.Context, req *DrainRequest) (*DrainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (*UnimplementedControlServer) Reload(ctx context.Context, req *ReloadRequest) (*ReloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
+}
+func (*UnimplementedControlServer) InjectFault(ctx context.Context, req *InjectFaultRequest) (*InjectFaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InjectFault not implemented")
+}
+func (*UnimplementedControlServer) Trace(ctx context.Context, req *TraceRequest) (*TraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Trace not implemented")
+}
+func (*UnimplementedControlServer) Step(ctx context.Context, req *StepRequest) (*StepResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Step not implemented")
+}
+
+func RegisterControlServer(s *grpc.Server, srv ControlServer) {
+	s.RegisterService(&_Control_serviceDesc, srv)
+}
+
+func _Control_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Status(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
@@ -275,6 +1474,135 @@ func _Control_Status_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Control_WatchStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControlServer).WatchStatus(m, &controlWatchStatusServer{stream})
+}
+
+type Control_WatchStatusServer interface {
+	Send(*StatusResponse) error
+	grpc.ServerStream
+}
+
+type controlWatchStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *controlWatchStatusServer) Send(m *StatusResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Control_ProbePeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProbePeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).ProbePeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/ProbePeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).ProbePeers(ctx, req.(*ProbePeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Reload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/Reload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Reload(ctx, req.(*ReloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_InjectFault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InjectFaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).InjectFault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/InjectFault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).InjectFault(ctx, req.(*InjectFaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Trace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Trace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/Trace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Trace(ctx, req.(*TraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Control/Step",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Step(ctx, req.(*StepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Control_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "Control",
 	HandlerType: (*ControlServer)(nil),
@@ -283,7 +1611,37 @@ var _Control_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _Control_Status_Handler,
 		},
+		{
+			MethodName: "ProbePeers",
+			Handler:    _Control_ProbePeers_Handler,
+		},
+		{
+			MethodName: "Drain",
+			Handler:    _Control_Drain_Handler,
+		},
+		{
+			MethodName: "Reload",
+			Handler:    _Control_Reload_Handler,
+		},
+		{
+			MethodName: "InjectFault",
+			Handler:    _Control_InjectFault_Handler,
+		},
+		{
+			MethodName: "Trace",
+			Handler:    _Control_Trace_Handler,
+		},
+		{
+			MethodName: "Step",
+			Handler:    _Control_Step_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStatus",
+			Handler:       _Control_WatchStatus_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/control/control.proto",
 }
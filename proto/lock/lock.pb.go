@@ -111,6 +111,9 @@ func (m *AcquireResponse) GetHolder() string {
 }
 
 type ReleaseRequest struct {
+	// Holder is the holder expected to hold the lock. If set, the lock is only released if it is still held by Holder,
+	// so that a delayed request can not release a lock that has changed hands in the meantime.
+	Holder               string   `protobuf:"bytes,1,opt,name=Holder,proto3" json:"Holder,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -141,8 +144,18 @@ func (m *ReleaseRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ReleaseRequest proto.InternalMessageInfo
 
+func (m *ReleaseRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
 type ReleaseResponse struct {
-	Released             bool     `protobuf:"varint,1,opt,name=Released,proto3" json:"Released,omitempty"`
+	Released bool `protobuf:"varint,1,opt,name=Released,proto3" json:"Released,omitempty"`
+	// Holder is the holder of the lock after the request, e.g. the holder that took over the lock if it was not
+	// released
+	Holder               string   `protobuf:"bytes,2,opt,name=Holder,proto3" json:"Holder,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -180,6 +193,13 @@ func (m *ReleaseResponse) GetReleased() bool {
 	return false
 }
 
+func (m *ReleaseResponse) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*AcquireRequest)(nil), "AcquireRequest")
 	proto.RegisterType((*AcquireResponse)(nil), "AcquireResponse")
@@ -190,19 +210,18 @@ func init() {
 func init() { proto.RegisterFile("proto/lock/lock.proto", fileDescriptor_857bf7c05cf10ff3) }
 
 var fileDescriptor_857bf7c05cf10ff3 = []byte{
-	// 178 bytes of a gzipped FileDescriptorProto
+	// 174 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2d, 0x28, 0xca, 0x2f,
 	0xc9, 0xd7, 0xcf, 0xc9, 0x4f, 0xce, 0x06, 0x13, 0x7a, 0x60, 0xbe, 0x92, 0x06, 0x17, 0x9f, 0x63,
 	0x72, 0x61, 0x69, 0x66, 0x51, 0x6a, 0x50, 0x6a, 0x61, 0x69, 0x6a, 0x71, 0x89, 0x90, 0x18, 0x17,
 	0x9b, 0x47, 0x7e, 0x4e, 0x4a, 0x6a, 0x91, 0x04, 0xa3, 0x02, 0xa3, 0x06, 0x67, 0x10, 0x94, 0xa7,
 	0xe4, 0xca, 0xc5, 0x0f, 0x57, 0x59, 0x5c, 0x90, 0x9f, 0x57, 0x9c, 0x2a, 0x24, 0xc5, 0xc5, 0x01,
-	0x15, 0x4a, 0x01, 0x2b, 0xe6, 0x08, 0x82, 0xf3, 0x91, 0x8c, 0x61, 0x42, 0x31, 0x46, 0x80, 0x8b,
-	0x2f, 0x28, 0x35, 0x27, 0x35, 0xb1, 0x18, 0x66, 0xa1, 0x92, 0x2e, 0x17, 0x3f, 0x5c, 0x04, 0x61,
-	0x30, 0x54, 0x08, 0x6e, 0x30, 0x8c, 0x6f, 0x94, 0xc4, 0xc5, 0xe2, 0x93, 0x9f, 0x9c, 0x2d, 0xa4,
-	0xc3, 0xc5, 0x0e, 0xb5, 0x4c, 0x88, 0x5f, 0x0f, 0xd5, 0x0f, 0x52, 0x02, 0x7a, 0xe8, 0x4e, 0xd5,
-	0xe1, 0x62, 0x87, 0x9a, 0x20, 0xc4, 0xaf, 0x87, 0xea, 0x00, 0x29, 0x01, 0x3d, 0x34, 0xfb, 0x93,
-	0xd8, 0xc0, 0x81, 0x63, 0x0c, 0x08, 0x00, 0x00, 0xff, 0xff, 0x67, 0xfa, 0xd1, 0xa6, 0x35, 0x01,
-	0x00, 0x00,
+	0x15, 0x4a, 0x01, 0x2b, 0xe6, 0x08, 0x82, 0xf3, 0x91, 0x8c, 0x61, 0x42, 0x31, 0x46, 0x83, 0x8b,
+	0x2f, 0x28, 0x35, 0x27, 0x35, 0xb1, 0x98, 0x18, 0x0b, 0xe1, 0x2a, 0x11, 0x16, 0x42, 0x85, 0xe0,
+	0x16, 0xc2, 0xf8, 0xb8, 0x2c, 0x34, 0x4a, 0xe2, 0x62, 0xf1, 0xc9, 0x4f, 0xce, 0x16, 0xd2, 0xe1,
+	0x62, 0x87, 0x3a, 0x4e, 0x88, 0x5f, 0x0f, 0xd5, 0xcf, 0x52, 0x02, 0x7a, 0xe8, 0x5e, 0xd3, 0xe1,
+	0x62, 0x87, 0x9a, 0x2c, 0xc4, 0xaf, 0x87, 0xea, 0x60, 0x29, 0x01, 0x3d, 0x34, 0x77, 0x25, 0xb1,
+	0x81, 0x03, 0xd3, 0x18, 0x30, 0x00, 0x5b, 0xa5, 0x67, 0xa3, 0x65, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,66 @@
+package skinny
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and measures durations for an instance. Instances use the real clock unless told otherwise.
+// Simulations use a virtual clock to run many rounds in no time and to replay them exactly.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep pauses the calling goroutine for at least d
+	Sleep(d time.Duration)
+	// WithTimeout returns a copy of parent that is canceled once d has elapsed
+	WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc)
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, d)
+}
+
+// SetClock changes the clock of the instance
+func (in *Instance) SetClock(clock Clock) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.clock = clock
+	in.started = clock.Now()
+}
+
+// now returns the current time according to the instance's clock
+func (in *Instance) now() time.Time {
+	if in.clock == nil {
+		return time.Now()
+	}
+	return in.clock.Now()
+}
+
+// sleep pauses the calling goroutine according to the instance's clock
+func (in *Instance) sleep(d time.Duration) {
+	if in.clock == nil {
+		time.Sleep(d)
+		return
+	}
+	in.clock.Sleep(d)
+}
+
+// withTimeout returns a copy of parent that is canceled once d has elapsed according to the instance's clock
+func (in *Instance) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if in.clock == nil {
+		return context.WithTimeout(parent, d)
+	}
+	return in.clock.WithTimeout(parent, d)
+}
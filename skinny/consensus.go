@@ -3,7 +3,6 @@ package skinny
 import (
 	"context"
 	"fmt"
-	"sync"
 
 	pb "github.com/danrl/skinny/proto/consensus"
 )
@@ -26,10 +25,19 @@ func (in *Instance) Promise(ctx context.Context, req *pb.PromiseRequest) (*pb.Pr
 	if req.ID > in.promised {
 		promise.Promised = true
 		in.promised = req.ID
-		fmt.Printf("promised ID %v%v\n", req.ID, attachment)
+		in.log.infof("promised ID %v%v\n", req.ID, attachment)
+		in.notify()
 	} else {
-		fmt.Printf("did not promise ID %v%v\n", req.ID, attachment)
+		in.log.infof("did not promise ID %v%v\n", req.ID, attachment)
 	}
+	in.recordTraffic(Record{
+		Type:           RecordPromise,
+		Peer:           requestPeer(ctx),
+		ID:             req.ID,
+		Granted:        promise.Promised,
+		AttachedID:     promise.ID,
+		AttachedHolder: promise.Holder,
+	})
 
 	return &promise, nil
 }
@@ -42,92 +50,96 @@ func (in *Instance) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.Comm
 	if req.ID >= in.promised {
 		in.id = req.ID
 		in.holder = req.Holder
-		fmt.Printf("committed ID %v and holder `%v`\n", in.id, in.holder)
+		in.log.infof("committed ID %v and holder `%v`\n", in.id, in.holder)
+		in.notify()
 	} else {
-		fmt.Printf("did not commit ID %v and holder `%v`\n", req.ID, req.Holder)
+		in.log.infof("did not commit ID %v and holder `%v`\n", req.ID, req.Holder)
 	}
-
-	return &pb.CommitResponse{
+	resp := pb.CommitResponse{
 		Committed: req.ID == in.id,
+	}
+	in.recordTraffic(Record{
+		Type:    RecordCommit,
+		Peer:    requestPeer(ctx),
+		ID:      req.ID,
+		Holder:  req.Holder,
+		Granted: resp.Committed,
+	})
+
+	return &resp, nil
+}
+
+// Ping answers with the name of the instance. It helps diagnosing the connection between instances. It does not lock
+// the instance, which is locked for entire rounds, so that it answers right away. The name never changes.
+func (in *Instance) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{
+		Name: in.name,
 	}, nil
 }
 
 // propose asks the quorum to promise a round number (ID). It learns previous consensus if there is any.
 func (in *Instance) propose() bool {
-	type response struct {
-		from     string
-		promised bool
-		id       uint64
-		holder   string
-	}
-
 	in.promised += in.increment
+	in.notify()
 
-	responses := make(chan *response)
-	ctx, cancel := context.WithTimeout(context.Background(), in.timeout)
+	stepping := in.stepping()
+	ctx, cancel := in.phaseContext(stepping)
 	// We cancel as soon as we have a majority to speed things up.
 	// We always cancel before leaving the function to prevent a context leak.
 	defer cancel()
 
-	wg := sync.WaitGroup{}
-	for _, p := range in.peers {
-		wg.Add(1)
-
-		// send proposal
-		go func(p peer) {
-			defer wg.Done()
-
-			resp, err := p.client.Promise(ctx, &pb.PromiseRequest{
-				ID: in.promised,
-			})
-			fmt.Printf("propose ID %v to %v: sent\n", in.promised, p.name)
-			if err != nil {
-				if ctx.Err() == context.Canceled {
-					fmt.Printf("propose ID %v to %v: canceled\n", in.promised, p.name)
-					return
-				}
-				// We want errors which are not the result of a canceled
-				// proposal to be counted as a negative answer (nay) later.
-				// For that we emit an empty response into the channel in those
-				// cases.
-				responses <- &response{from: p.name}
-				fmt.Printf("propose ID %v to %v: %v\n", in.promised, p.name, err)
-				return
-			}
-			responses <- &response{
-				from:     p.name,
-				promised: resp.Promised,
-				id:       resp.ID,
-				holder:   resp.Holder,
-			}
-		}(p)
-	}
-
-	// close responses channel once all responses have been received, failed, or
-	// canceled
-	go func() {
-		wg.Wait()
-		close(responses)
-	}()
+	// send proposal
+	id := in.promised
+	replies := in.broadcast(ctx, &pb.PromiseRequest{
+		ID: id,
+	}, stepping)
 
 	// count the votes
 	yea, nay := 1, 0
 	canceled := false
-	for r := range responses {
+	outdated := false
+	for rep, ok := replies.next(); ok; rep, ok = replies.next() {
+		r := promiseResponse(rep)
+		m := message{
+			phase:          phasePromise,
+			id:             id,
+			attachedID:     r.ID,
+			attachedHolder: r.Holder,
+			outcome:        outcome(rep.err != nil, r.Promised),
+		}
+		if rep.err != nil {
+			if ctx.Err() == context.Canceled {
+				in.log.debugf("propose ID %v to %v: canceled\n", id, rep.from)
+				m.outcome = messageCanceled
+				in.record(m, rep)
+				continue
+			}
+			// We want errors which are not the result of a canceled proposal to be counted as a negative answer
+			// (nay).
+			in.log.infof("propose ID %v to %v: %v\n", id, rep.from, rep.err)
+		}
+		in.record(m, rep)
+		in.observe(rep.from, vote{phase: phasePromise, id: id, outcome: m.outcome}, rep.rtt)
+
 		// count the promises
-		if r.promised {
+		if r.Promised {
 			yea++
-			fmt.Printf("propose ID %v to %v: got yea\n", in.promised, r.from)
+			in.log.debugf("propose ID %v to %v: got yea\n", id, rep.from)
 		} else {
 			nay++
-			fmt.Printf("propose ID %v to %v: got nay\n", in.promised, r.from)
+			in.log.debugf("propose ID %v to %v: got nay\n", id, rep.from)
 		}
 
 		// learn previously committed ID and holder from other instances
-		if r.id > in.id {
-			in.id = r.id
-			in.holder = r.holder
-			fmt.Printf("propose ID %v to %v: learned ID %v and holder `%v`\n", in.promised, r.from, r.id, r.holder)
+		if r.ID > in.id {
+			in.id = r.ID
+			in.holder = r.Holder
+			in.log.infof("propose ID %v to %v: learned ID %v and holder `%v`\n", id, rep.from, r.ID, r.Holder)
+			in.notify()
+		}
+		// someone else already committed a value for an ID at least as high as ours
+		if r.ID >= id {
+			outdated = true
 		}
 
 		// stop counting as soon as we have a majority
@@ -143,7 +155,15 @@ func (in *Instance) propose() bool {
 	// if we learned a higher ID than our initial proposal suggested, then we also promise this higher ID
 	if in.id > in.promised {
 		in.promised = in.id
-		fmt.Printf("jumped to promise ID %v\n", in.promised)
+		in.log.infof("jumped to promise ID %v\n", in.promised)
+		in.notify()
+	}
+
+	// An outdated proposal must not be committed, it would overwrite a value committed for the same ID. A retry
+	// proposes a fresh ID above the one we learned.
+	if outdated {
+		in.log.infof("propose ID %v: outdated\n", id)
+		return false
 	}
 
 	return in.isMajority(yea)
@@ -151,69 +171,66 @@ func (in *Instance) propose() bool {
 
 // commit asks the quorum to accept the acquisition or release of a lock
 func (in *Instance) commit(id uint64, holder string) bool {
-	type response struct {
-		from      string
-		committed bool
-	}
+	in.log.infof("committing ID %v and holder `%v`\n", id, holder)
 
-	fmt.Printf("committing ID %v and holder `%v`\n", id, holder)
-
-	responses := make(chan *response)
-	ctx, cancel := context.WithTimeout(context.Background(), in.timeout)
+	stepping := in.stepping()
+	ctx, cancel := in.phaseContext(stepping)
 	defer cancel()
 
-	wg := sync.WaitGroup{}
-	for _, p := range in.peers {
-		wg.Add(1)
-
-		// send commit requests
-		go func(p peer) {
-			defer wg.Done()
-
-			resp, err := p.client.Commit(ctx, &pb.CommitRequest{
-				ID:     id,
-				Holder: holder,
-			})
-			fmt.Printf("commit ID %v and holder `%v` to %v: sent\n", id, holder, p.name)
-
-			if err != nil {
-				if ctx.Err() == context.DeadlineExceeded {
-					fmt.Printf("commit ID %v and holder `%v` to %v: deadline exceeded\n", id, holder, p.name)
-					return
-				}
-				// We want errors which are not the result of a canceled commit to be counted as a negative answer (nay)
-				// later. For that we emit an empty response into the channel in those cases.
-				responses <- &response{from: p.name}
-				fmt.Printf("commit ID %v and holder `%v` to %v: %v\n", id, holder, p.name, err)
-				return
-			}
-			responses <- &response{
-				from:      p.name,
-				committed: resp.Committed,
-			}
-		}(p)
-	}
-
-	// close responses channel once all responses have been received, failed, or canceled
-	go func() {
-		wg.Wait()
-		close(responses)
-	}()
+	// send commit requests
+	replies := in.broadcast(ctx, &pb.CommitRequest{
+		ID:     id,
+		Holder: holder,
+	}, stepping)
 
 	// we have to commit our own data
 	in.id = id
 	in.holder = holder
+	in.notify()
 
 	// count the vote
 	yea := 1 // we just committed our own data. make it count.
-	for r := range responses {
-		if r.committed {
+	for rep, ok := replies.next(); ok; rep, ok = replies.next() {
+		r := commitResponse(rep)
+		m := message{
+			phase:   phaseCommit,
+			id:      id,
+			holder:  holder,
+			outcome: outcome(rep.err != nil, r.Committed),
+		}
+		in.record(m, rep)
+		if rep.err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				in.log.debugf("commit ID %v and holder `%v` to %v: deadline exceeded\n", id, holder, rep.from)
+				continue
+			}
+			// We want errors which are not the result of a canceled commit to be counted as a negative answer (nay).
+			in.log.infof("commit ID %v and holder `%v` to %v: %v\n", id, holder, rep.from, rep.err)
+		}
+		in.observe(rep.from, vote{phase: phaseCommit, id: id, outcome: m.outcome}, rep.rtt)
+		if r.Committed {
 			yea++
-			fmt.Printf("commit ID %v and holder `%v` to %v: got yea\n", id, holder, r.from)
+			in.log.debugf("commit ID %v and holder `%v` to %v: got yea\n", id, holder, rep.from)
 			continue
 		}
-		fmt.Printf("commit ID %v and holder `%v` to %v: got nay\n", id, holder, r.from)
+		in.log.debugf("commit ID %v and holder `%v` to %v: got nay\n", id, holder, rep.from)
 	}
 
 	return in.isMajority(yea)
 }
+
+// promiseResponse returns the response of a reply to a promise request. Failed requests get an empty response.
+func promiseResponse(rep *reply) *pb.PromiseResponse {
+	if resp, ok := rep.resp.(*pb.PromiseResponse); ok {
+		return resp
+	}
+	return &pb.PromiseResponse{}
+}
+
+// commitResponse returns the response of a reply to a commit request. Failed requests get an empty response.
+func commitResponse(rep *reply) *pb.CommitResponse {
+	if resp, ok := rep.resp.(*pb.CommitResponse); ok {
+		return resp
+	}
+	return &pb.CommitResponse{}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/lock"
 )
 
 const (
@@ -119,6 +120,58 @@ func TestInstanceCommitRPC(t *testing.T) {
 	})
 }
 
+func TestInstancePingRPC(t *testing.T) {
+	t.Run("name", func(t *testing.T) {
+		in := Instance{
+			name: "foo",
+		}
+
+		resp, err := in.Ping(context.Background(), &consensus.PingRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Name != "foo" {
+			t.Errorf("expected `%v`, got `%v`", "foo", resp.Name)
+		}
+	})
+
+	t.Run("during a round", func(t *testing.T) {
+		leader := newMockInstance(t, "leader", 1, time.Second)
+		defer leader.destroy()
+		peer := newMockInstance(t, "peer-1", 2, time.Second)
+		defer peer.destroy()
+		peer.latency = 500 * time.Millisecond
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+
+		acquired := make(chan struct{})
+		go func() {
+			_, _ = leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+			close(acquired)
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		start := time.Now()
+		resp, err := consensus.NewConsensusClient(leader.conn).Ping(context.Background(), &consensus.PingRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Name != "leader" {
+			t.Errorf("expected `%v`, got `%v`", "leader", resp.Name)
+		}
+		select {
+		case <-acquired:
+			t.Errorf("expected the round to be in progress while pinging")
+		default:
+		}
+		if rtt := time.Since(start); rtt > 200*time.Millisecond {
+			t.Errorf("expected ping to be answered right away, took `%v`", rtt)
+		}
+		<-acquired
+	})
+}
+
 func TestInstancePropose(t *testing.T) {
 	t.Run("successful propose", func(t *testing.T) {
 		if testing.Short() {
@@ -130,14 +183,14 @@ func TestInstancePropose(t *testing.T) {
 
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
 
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -158,7 +211,7 @@ func TestInstancePropose(t *testing.T) {
 
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -166,7 +219,7 @@ func TestInstancePropose(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.latency = 500 * time.Millisecond
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -187,7 +240,7 @@ func TestInstancePropose(t *testing.T) {
 
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -195,7 +248,7 @@ func TestInstancePropose(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.latency = 500 * time.Millisecond // to make sure we learn from peer1 before reaching a majority
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -204,9 +257,10 @@ func TestInstancePropose(t *testing.T) {
 		peer1.in.id = 23
 		peer1.in.holder = beaver
 
+		// the proposal is outdated, ID 23 has already been committed
 		got := leader.in.propose()
-		if !got {
-			t.Errorf("expected `%v`, got `%v`", true, got)
+		if got {
+			t.Errorf("expected `%v`, got `%v`", false, got)
 		}
 
 		// leader must have learned new value
@@ -233,14 +287,14 @@ func TestInstanceCommit(t *testing.T) {
 
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
 
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -270,7 +324,7 @@ func TestInstanceCommit(t *testing.T) {
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
 		peer1.latency = 500 * time.Millisecond
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -278,7 +332,7 @@ func TestInstanceCommit(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.latency = 500 * time.Millisecond
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -307,7 +361,7 @@ func TestInstanceCommit(t *testing.T) {
 
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -315,7 +369,7 @@ func TestInstanceCommit(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.fail = true
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
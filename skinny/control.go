@@ -2,8 +2,18 @@ package skinny
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	pbcs "github.com/danrl/skinny/proto/consensus"
 	pb "github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrReloadUnsupported is returned when an instance is asked to reload but does not know how to
+	ErrReloadUnsupported = status.Error(codes.Unimplemented, "reload not supported")
 )
 
 // Status exposes internal state information of an instance
@@ -11,20 +21,93 @@ func (in *Instance) Status(ctx context.Context, req *pb.StatusRequest) (*pb.Stat
 	in.mu.Lock()
 	defer in.mu.Unlock()
 
-	status := pb.StatusResponse{
-		Name:      in.name,
-		Increment: in.increment,
-		Timeout:   in.timeout.String(),
-		Promised:  in.promised,
-		ID:        in.id,
-		Holder:    in.holder,
+	return in.status(), nil
+}
+
+// ProbePeers pings all peers concurrently and reports which of them answered and how fast. Peers are pinged over the
+// same connections that consensus rounds use.
+func (in *Instance) ProbePeers(ctx context.Context, req *pb.ProbePeersRequest) (*pb.ProbePeersResponse, error) {
+	in.mu.Lock()
+	peers := make([]peer, len(in.peers))
+	copy(peers, in.peers)
+	timeout := in.timeout
+	in.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	probes := make([]*pb.ProbePeersResponse_Probe, len(peers))
+	wg := sync.WaitGroup{}
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p peer) {
+			defer wg.Done()
+
+			probe := pb.ProbePeersResponse_Probe{
+				Name: p.name,
+			}
+			start := time.Now()
+			_, err := p.transport.Ping(ctx, &pbcs.PingRequest{})
+			if err != nil {
+				probe.Error = err.Error()
+			} else {
+				probe.Reachable = true
+				probe.RTT = time.Since(start).String()
+			}
+			probes[i] = &probe
+		}(i, p)
+	}
+	wg.Wait()
+
+	return &pb.ProbePeersResponse{
+		Probes: probes,
+	}, nil
+}
+
+// Drain stops the instance from accepting new lock requests and waits for in-flight lock requests to finish. A drained
+// instance still takes part in consensus rounds started by its peers.
+func (in *Instance) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	in.mu.Lock()
+	if !in.draining {
+		in.draining = true
+		in.log.infof("draining\n")
+		in.notify()
+	}
+	in.mu.Unlock()
+
+	// No new lock requests are tracked once draining is set, so waiting for the in-flight ones is safe.
+	done := make(chan struct{})
+	go func() {
+		in.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		in.log.infof("drained\n")
+		return &pb.DrainResponse{Drained: true}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
 
-	for _, peer := range in.peers {
-		status.Peers = append(status.Peers, &pb.StatusResponse_Peer{
-			Name: peer.name,
-		})
+// Reload asks the instance to re-read its configuration and apply all changes that are safe to apply at runtime
+func (in *Instance) Reload(ctx context.Context, req *pb.ReloadRequest) (*pb.ReloadResponse, error) {
+	in.mu.Lock()
+	reload := in.reload
+	in.mu.Unlock()
+
+	if reload == nil {
+		return nil, ErrReloadUnsupported
+	}
+	changes, err := reload()
+	if err != nil {
+		in.log.infof("reload failed: %v\n", err)
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
+	in.log.infof("reloaded with %v change(s)\n", len(changes))
 
-	return &status, nil
+	return &pb.ReloadResponse{
+		Changes: changes,
+	}, nil
 }
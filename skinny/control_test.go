@@ -2,10 +2,14 @@ package skinny
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 )
 
 func TestInstanceStatusRPC(t *testing.T) {
@@ -16,9 +20,16 @@ func TestInstanceStatusRPC(t *testing.T) {
 		promised:  100,
 		id:        23,
 		holder:    "alien",
+		started:   time.Now().Add(-time.Hour),
+		proposing: 1,
 		peers: []peer{
 			{
-				name: "peer-1",
+				name:        "peer-1",
+				conn:        testConn(connectivity.Ready),
+				reachable:   true,
+				lastSuccess: time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+				rtt:         3 * time.Millisecond,
+				votes:       []vote{{phase: phaseCommit, id: 23, outcome: voteYea}},
 			},
 			{
 				name: "peer-2",
@@ -58,4 +69,179 @@ func TestInstanceStatusRPC(t *testing.T) {
 	if resp.Peers[1].Name != in.peers[1].name {
 		t.Errorf("expected `%v`, got `%v`", in.peers[1].name, resp.Peers[1].Name)
 	}
+	if resp.Uptime != "1h0m0s" {
+		t.Errorf("expected `%v`, got `%v`", "1h0m0s", resp.Uptime)
+	}
+	if resp.Version != Version {
+		t.Errorf("expected `%v`, got `%v`", Version, resp.Version)
+	}
+	if resp.Storage != Storage {
+		t.Errorf("expected `%v`, got `%v`", Storage, resp.Storage)
+	}
+	if resp.Role != roleProposer {
+		t.Errorf("expected `%v`, got `%v`", roleProposer, resp.Role)
+	}
+
+	t.Run("peer details", func(t *testing.T) {
+		p := resp.Peers[0]
+		if p.Connection != "READY" {
+			t.Errorf("expected `%v`, got `%v`", "READY", p.Connection)
+		}
+		if p.LastSuccess != "2019-06-01T12:00:00Z" {
+			t.Errorf("expected `%v`, got `%v`", "2019-06-01T12:00:00Z", p.LastSuccess)
+		}
+		if p.RTT != "3ms" {
+			t.Errorf("expected `%v`, got `%v`", "3ms", p.RTT)
+		}
+		if len(p.Votes) != 1 || p.Votes[0].Phase != phaseCommit || p.Votes[0].ID != 23 ||
+			p.Votes[0].Outcome != voteYea {
+			t.Errorf("expected yea on commit of ID 23, got `%v`", p.Votes)
+		}
+
+		// nothing is known about a peer that was never contacted
+		p = resp.Peers[1]
+		if p.Connection != "" || p.LastSuccess != "" || p.RTT != "0s" || len(p.Votes) != 0 {
+			t.Errorf("expected empty peer details, got `%v`", p)
+		}
+	})
+}
+
+func TestInstanceProbePeersRPC(t *testing.T) {
+	leader := newMockInstance(t, "leader", 1, 100*time.Millisecond)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+	peer3 := newMockInstance(t, "peer-3", 4, time.Second)
+	defer peer3.destroy()
+	for _, peer := range []*mockInstance{peer1, peer2, peer3} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+	}
+	peer1.latency = 5 * time.Millisecond
+	peer2.fail = true
+	peer3.latency = 300 * time.Millisecond
+
+	resp, err := leader.in.ProbePeers(context.Background(), &control.ProbePeersRequest{})
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if len(resp.Probes) != 3 {
+		t.Fatalf("expected `%v` probes, got `%v`", 3, len(resp.Probes))
+	}
+
+	// peers are reported in order
+	for i, name := range []string{"peer-1", "peer-2", "peer-3"} {
+		if resp.Probes[i].Name != name {
+			t.Errorf("expected `%v`, got `%v`", name, resp.Probes[i].Name)
+		}
+	}
+
+	t.Run("reachable peer", func(t *testing.T) {
+		probe := resp.Probes[0]
+		if !probe.Reachable || probe.Error != "" {
+			t.Fatalf("expected reachable peer, got `%v`", probe)
+		}
+		rtt, err := time.ParseDuration(probe.RTT)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if rtt < peer1.latency {
+			t.Errorf("expected round-trip time of at least `%v`, got `%v`", peer1.latency, rtt)
+		}
+	})
+
+	t.Run("failing peer", func(t *testing.T) {
+		probe := resp.Probes[1]
+		if probe.Reachable || probe.Error == "" || probe.RTT != "" {
+			t.Errorf("expected unreachable peer, got `%v`", probe)
+		}
+	})
+
+	t.Run("slow peer", func(t *testing.T) {
+		// probes time out after the instance's timeout
+		probe := resp.Probes[2]
+		if probe.Reachable || probe.Error == "" {
+			t.Errorf("expected unreachable peer, got `%v`", probe)
+		}
+	})
+}
+
+func TestInstanceDrainRPC(t *testing.T) {
+	t.Run("idle instance", func(t *testing.T) {
+		var in Instance
+
+		resp, err := in.Drain(context.Background(), &control.DrainRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if !resp.Drained {
+			t.Errorf("expected `%v`, got `%v`", true, resp.Drained)
+		}
+		if !in.draining {
+			t.Errorf("expected `%v`, got `%v`", true, in.draining)
+		}
+	})
+
+	t.Run("in-flight request", func(t *testing.T) {
+		var in Instance
+		in.inflight.Add(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := in.Drain(ctx, &control.DrainRequest{})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected `%v`, got `%v`", context.DeadlineExceeded, err)
+		}
+
+		// finishing the request completes the drain
+		in.inflight.Done()
+		resp, err := in.Drain(context.Background(), &control.DrainRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if !resp.Drained {
+			t.Errorf("expected `%v`, got `%v`", true, resp.Drained)
+		}
+	})
+}
+
+func TestInstanceReloadRPC(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		var in Instance
+
+		_, err := in.Reload(context.Background(), &control.ReloadRequest{})
+		if err != ErrReloadUnsupported {
+			t.Errorf("expected `%v`, got `%v`", ErrReloadUnsupported, err)
+		}
+	})
+
+	t.Run("successful reload", func(t *testing.T) {
+		var in Instance
+		in.SetReloadFunc(func() ([]string, error) {
+			return []string{"timeout: 1s -> 2s"}, nil
+		})
+
+		resp, err := in.Reload(context.Background(), &control.ReloadRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(resp.Changes) != 1 {
+			t.Errorf("expected `%v` changes, got `%v`", 1, len(resp.Changes))
+		}
+	})
+
+	t.Run("failed reload", func(t *testing.T) {
+		var in Instance
+		in.SetReloadFunc(func() ([]string, error) {
+			return nil, errors.New("name: change requires a restart")
+		})
+
+		_, err := in.Reload(context.Background(), &control.ReloadRequest{})
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("expected `%v`, got `%v`", codes.FailedPrecondition, status.Code(err))
+		}
+	})
 }
@@ -0,0 +1,669 @@
+package skinny
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/lock"
+)
+
+var flagExploreRetries = flag.Int("explore.retries", 1, "number of times each proposer of the model checker retries")
+
+/* --- begin: test helper: model checker ---------------------------------------------------------------------------- */
+
+// The model checker explores every execution of a small quorum: three instances, two of which try to acquire the lock
+// for a holder of their own. It runs the real lock and consensus code. At every step, it picks one of the actions that
+// are possible: start a proposer, deliver a message, time out a round, or wake a proposer from its backoff. Requests to
+// an instance that is busy with a round of its own wait, just like they wait for the instance's lock. A lost message is
+// a message that is never delivered, so every pattern of losses is covered by the orders of delivery and timeouts.
+//
+// An execution can not be copied half way, since the proposers are goroutines. Instead, every execution starts from
+// scratch and replays the choices that lead to the state to explore. States that have been explored before are not
+// explored again.
+
+// exploreProposer is a goroutine acquiring the lock on behalf of its holder. It runs only when the model checker hands
+// over control to it.
+type exploreProposer struct {
+	holder string
+	si     *exploreInstance
+	resume chan struct{}
+	// status is one of idle, running, waiting, sleeping, and done
+	status   string
+	round    *exploreRound
+	sleeps   int
+	acquired bool
+}
+
+// exploreInstance is an instance taking part in the model checker's executions
+type exploreInstance struct {
+	in *Instance
+	// proposer is the proposer running on the instance, nil if there is none
+	proposer *exploreProposer
+}
+
+// busy returns true if the instance's lock is held by its proposer
+func (si *exploreInstance) busy() bool {
+	return si.proposer != nil && (si.proposer.status == "running" || si.proposer.status == "waiting")
+}
+
+// exploreMessage is a request or a reply in flight
+type exploreMessage struct {
+	round *exploreRound
+	// peer is the receiver of a request or the sender of a reply
+	peer string
+	req  interface{}
+	// resp is nil for requests
+	resp interface{}
+}
+
+func (m *exploreMessage) String() string {
+	if m.resp == nil {
+		return fmt.Sprintf("%v → %v: %v", m.round.from, m.peer, describe(m.req))
+	}
+	return fmt.Sprintf("%v ← %v: %v", m.round.from, m.peer, describe(m.resp))
+}
+
+// live returns true if the message still matters to the round it belongs to. Replies to other requests are discarded.
+func (m *exploreMessage) live() bool {
+	return m.round == m.round.owner.round && m.round.outstanding[m.peer]
+}
+
+// exploreRound are the replies to a request broadcast by a proposer
+type exploreRound struct {
+	e     *exploration
+	from  string
+	owner *exploreProposer
+	req   interface{}
+	ctx   *simContext
+	// order and outstanding are the peers that did not answer yet
+	order       []string
+	outstanding map[string]bool
+	ready       []*reply
+	// seen are the replies the proposer has processed
+	seen    []string
+	waiting bool
+}
+
+// exploreAction is a step the model checker can take
+type exploreAction struct {
+	// on is the instance the action happens on
+	on   string
+	desc func() string
+	fn   func()
+}
+
+// exploration is a single execution of the model checker
+type exploration struct {
+	instances []*exploreInstance
+	byName    map[string]*exploreInstance
+	proposers []*exploreProposer
+	messages  []*exploreMessage
+
+	current *exploreProposer
+	yield   chan struct{}
+	// kill ends the goroutines of proposers once the execution is over
+	kill   chan struct{}
+	killed bool
+
+	// committed are the holders committed for every ID ever seen
+	committed  map[uint64]map[string]bool
+	violations []string
+	// trace is what happened, recorded only if tracing is true
+	tracing bool
+	trace   []string
+}
+
+// newExploration creates an execution. Instance in-1 acquires the lock for beaver, in-2 for hamster, in-3 only answers.
+func newExploration(retries int) *exploration {
+	e := exploration{
+		byName:    make(map[string]*exploreInstance),
+		yield:     make(chan struct{}),
+		kill:      make(chan struct{}),
+		committed: make(map[uint64]map[string]bool),
+	}
+	for i := 1; i <= 3; i++ {
+		si := &exploreInstance{
+			in: &Instance{
+				name:      fmt.Sprintf("in-%v", i),
+				increment: uint64(i),
+				timeout:   time.Second,
+				retries:   retries,
+				backoff:   DefaultBackoff,
+				network:   &e,
+			},
+		}
+		si.in.clock = &exploreClock{e: &e, si: si}
+		si.in.log.setLevel(LogQuiet)
+		e.instances = append(e.instances, si)
+		e.byName[si.in.name] = si
+	}
+	for _, si := range e.instances {
+		for _, other := range e.instances {
+			if other != si {
+				si.in.peers = append(si.in.peers, peer{name: other.in.name, reachable: true})
+			}
+		}
+	}
+	for i, holder := range []string{"beaver", "hamster"} {
+		p := &exploreProposer{
+			holder: holder,
+			si:     e.instances[i],
+			resume: make(chan struct{}),
+			status: "idle",
+		}
+		p.si.proposer = p
+		e.proposers = append(e.proposers, p)
+	}
+	return &e
+}
+
+// tracef records what happened for the counterexample
+func (e *exploration) tracef(format string, v ...interface{}) {
+	if e.tracing {
+		e.trace = append(e.trace, fmt.Sprintf(format, v...))
+	}
+}
+
+// violationf records a breach of safety
+func (e *exploration) violationf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	e.tracef("🚨 %v", msg)
+	e.violations = append(e.violations, msg)
+}
+
+// run hands control to a proposer until it parks or finishes
+func (e *exploration) run(p *exploreProposer) {
+	if e.killed {
+		return
+	}
+	e.current = p
+	p.resume <- struct{}{}
+	<-e.yield
+	e.current = nil
+}
+
+// park hands control back to the model checker until the proposer is run again
+func (e *exploration) park(p *exploreProposer) {
+	e.yield <- struct{}{}
+	select {
+	case <-p.resume:
+	case <-e.kill:
+		runtime.Goexit()
+	}
+}
+
+// stop ends the goroutines of all proposers that are still parked
+func (e *exploration) stop() {
+	e.killed = true
+	close(e.kill)
+}
+
+// actions returns every action possible in the current state, always in the same order
+func (e *exploration) actions() []exploreAction {
+	actions := []exploreAction{}
+	for _, p := range e.proposers {
+		p := p
+		on := p.si.in.name
+		switch {
+		case p.status == "idle":
+			actions = append(actions, exploreAction{
+				on:   on,
+				desc: func() string { return fmt.Sprintf("%v starts acquiring the lock for `%v`", on, p.holder) },
+				fn:   func() { e.start(p) },
+			})
+		case p.status == "sleeping":
+			actions = append(actions, exploreAction{
+				on:   on,
+				desc: func() string { return fmt.Sprintf("%v wakes up from its backoff", on) },
+				fn: func() {
+					p.status = "running"
+					e.run(p)
+				},
+			})
+		case p.status == "waiting" && len(p.round.outstanding) > 0:
+			actions = append(actions, exploreAction{
+				on: on,
+				fn: func() { p.round.ctx.finish(context.DeadlineExceeded) },
+				desc: func() string {
+					return fmt.Sprintf("%v times out waiting for %v", on, strings.Join(p.round.pending(), ", "))
+				},
+			})
+		}
+	}
+	for _, m := range e.messages {
+		m := m
+		on := m.round.from
+		if m.resp == nil {
+			on = m.peer
+			if e.byName[on].busy() {
+				continue
+			}
+		}
+		actions = append(actions, exploreAction{
+			on:   on,
+			desc: m.String,
+			fn:   func() { e.deliver(m) },
+		})
+	}
+	return actions
+}
+
+// choices returns the positions of the actions worth taking in the current state. A proposer waiting for replies to
+// requests that have all been delivered depends on nothing but its own actions: other actions neither change what it
+// does nor are changed by it. It is enough to take the proposer's actions first and everything else afterwards.
+func (e *exploration) choices(actions []exploreAction) []int {
+	for _, p := range e.proposers {
+		if p.status != "waiting" || e.undelivered(p.round) {
+			continue
+		}
+		own := []int{}
+		for i, a := range actions {
+			if a.on == p.si.in.name {
+				own = append(own, i)
+			}
+		}
+		return own
+	}
+	all := []int{}
+	for i := range actions {
+		all = append(all, i)
+	}
+	return all
+}
+
+// undelivered returns true if a request of the round is still in flight
+func (e *exploration) undelivered(r *exploreRound) bool {
+	for _, m := range e.messages {
+		if m.round == r && m.resp == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// do takes an action and checks the invariants afterwards
+func (e *exploration) do(a exploreAction) {
+	if e.tracing {
+		e.tracef("%v", a.desc())
+	}
+	a.fn()
+	e.collect()
+	e.check()
+	if e.tracing {
+		e.tracef("    %v", e.state())
+	}
+}
+
+// start starts a proposer
+func (e *exploration) start(p *exploreProposer) {
+	p.status = "running"
+	go func() {
+		select {
+		case <-p.resume:
+		case <-e.kill:
+			return
+		}
+		resp, _ := p.si.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: p.holder})
+		p.status = "done"
+		p.acquired = resp.Acquired
+		e.tracef("%v: acquired %v, holder `%v`", p.si.in.name, resp.Acquired, resp.Holder)
+		if resp.Acquired {
+			for _, other := range e.proposers {
+				if other != p && other.acquired && other.holder != p.holder {
+					e.violationf("lock granted to `%v` while `%v` holds it", p.holder, other.holder)
+				}
+			}
+		}
+		e.yield <- struct{}{}
+	}()
+	e.run(p)
+}
+
+// deliver delivers a message. Requests are served right away, replies are handed to the waiting proposer.
+func (e *exploration) deliver(m *exploreMessage) {
+	e.remove(m)
+	if m.resp == nil {
+		in := e.byName[m.peer].in
+		var resp interface{}
+		switch req := m.req.(type) {
+		case *consensus.PromiseRequest:
+			resp, _ = in.Promise(context.Background(), req)
+		case *consensus.CommitRequest:
+			resp, _ = in.Commit(context.Background(), req)
+		}
+		// replies to a round that is over are discarded
+		if m.live() {
+			e.messages = append(e.messages, &exploreMessage{round: m.round, peer: m.peer, req: m.req, resp: resp})
+		}
+		return
+	}
+	m.round.deliver(&reply{from: m.peer, resp: m.resp})
+}
+
+// remove removes a message from the network
+func (e *exploration) remove(m *exploreMessage) {
+	for i := range e.messages {
+		if e.messages[i] == m {
+			e.messages = append(e.messages[:i], e.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+// collect discards requests that can not make a difference anymore. Nobody waits for their replies, and the receiver
+// already promised an ID too high for them to change anything. Promised IDs never decrease, so the requests would not
+// change anything later either.
+func (e *exploration) collect() {
+	kept := []*exploreMessage{}
+	for _, m := range e.messages {
+		if m.resp == nil && !m.live() {
+			promised := e.byName[m.peer].in.promised
+			switch req := m.req.(type) {
+			case *consensus.PromiseRequest:
+				if req.ID <= promised {
+					continue
+				}
+			case *consensus.CommitRequest:
+				if req.ID < promised {
+					continue
+				}
+			}
+		}
+		kept = append(kept, m)
+	}
+	e.messages = kept
+}
+
+// check verifies that instances never commit different holders for the same ID
+func (e *exploration) check() {
+	for _, si := range e.instances {
+		in := si.in
+		if in.id == 0 || e.committed[in.id][in.holder] {
+			continue
+		}
+		if e.committed[in.id] == nil {
+			e.committed[in.id] = make(map[string]bool)
+		}
+		for holder := range e.committed[in.id] {
+			e.violationf("%v committed holder `%v` for ID %v, but `%v` was committed before", in.name, in.holder,
+				in.id, holder)
+		}
+		e.committed[in.id][in.holder] = true
+	}
+}
+
+// state describes the state of all instances for the counterexample
+func (e *exploration) state() string {
+	states := []string{}
+	for _, si := range e.instances {
+		states = append(states, fmt.Sprintf("%v: promised %v, ID %v, holder `%v`", si.in.name, si.in.promised, si.in.id,
+			si.in.holder))
+	}
+	return strings.Join(states, " | ")
+}
+
+// key identifies the state of an execution. Executions reaching the same state behave the same from there on, no
+// matter how they got there. A proposer's state is given by the request it waits for and the replies it processed, in
+// any order.
+func (e *exploration) key() string {
+	parts := []string{e.state()}
+	for _, p := range e.proposers {
+		part := fmt.Sprintf("%v %v %v %v", p.holder, p.status, p.sleeps, p.acquired)
+		if p.status == "waiting" {
+			r := p.round
+			seen := append([]string{}, r.seen...)
+			sort.Strings(seen)
+			part += fmt.Sprintf(" %v %v %v %v", describe(r.req), r.ctx.err, r.pending(), seen)
+		}
+		parts = append(parts, part)
+	}
+	messages := []string{}
+	for _, m := range e.messages {
+		messages = append(messages, fmt.Sprintf("%v %v", m, m.live()))
+	}
+	sort.Strings(messages)
+	committed := []string{}
+	for id, holders := range e.committed {
+		for holder := range holders {
+			committed = append(committed, fmt.Sprintf("%v %v", id, holder))
+		}
+	}
+	sort.Strings(committed)
+	parts = append(parts, strings.Join(messages, ", "), strings.Join(committed, ", "))
+	return strings.Join(parts, "\n")
+}
+
+// broadcast sends requests to all peers. Replies arrive when the model checker delivers them.
+func (e *exploration) broadcast(ctx context.Context, from string, peers []peer, req interface{}) replies {
+	p := e.byName[from].proposer
+	round := &exploreRound{
+		e:           e,
+		from:        from,
+		owner:       p,
+		req:         req,
+		outstanding: make(map[string]bool),
+	}
+	p.round = round
+	if ctx, ok := ctx.(*simContext); ok {
+		round.ctx = ctx
+		ctx.onDone = append(ctx.onDone, round.fail)
+	}
+	for _, other := range peers {
+		round.order = append(round.order, other.name)
+		round.outstanding[other.name] = true
+		e.messages = append(e.messages, &exploreMessage{round: round, peer: other.name, req: req})
+	}
+	return round
+}
+
+// pending returns the peers that did not answer yet
+func (r *exploreRound) pending() []string {
+	pending := []string{}
+	for _, name := range r.order {
+		if r.outstanding[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// deliver hands a reply to the waiting proposer
+func (r *exploreRound) deliver(rep *reply) {
+	delete(r.outstanding, rep.from)
+	r.ready = append(r.ready, rep)
+	if r.waiting {
+		r.e.run(r.owner)
+	}
+}
+
+// fail fails all requests still waiting for a reply, like gRPC does once a request's context is done. Replies in
+// flight are discarded, requests in flight may still be served.
+func (r *exploreRound) fail(err error) {
+	if r.e.killed {
+		return
+	}
+	for _, name := range r.pending() {
+		delete(r.outstanding, name)
+		r.ready = append(r.ready, &reply{from: name, err: err})
+	}
+	kept := []*exploreMessage{}
+	for _, m := range r.e.messages {
+		if m.round != r || m.resp == nil {
+			kept = append(kept, m)
+		}
+	}
+	r.e.messages = kept
+	if r.waiting && len(r.ready) > 0 {
+		r.e.run(r.owner)
+	}
+}
+
+func (r *exploreRound) next() (*reply, bool) {
+	if len(r.ready) == 0 && len(r.outstanding) > 0 {
+		r.waiting = true
+		r.owner.status = "waiting"
+		r.e.park(r.owner)
+		r.waiting = false
+		r.owner.status = "running"
+	}
+	if len(r.ready) == 0 {
+		return nil, false
+	}
+	rep := r.ready[0]
+	r.ready = r.ready[1:]
+	if rep.err != nil {
+		r.seen = append(r.seen, fmt.Sprintf("%v: %v", rep.from, rep.err))
+	} else {
+		r.seen = append(r.seen, fmt.Sprintf("%v: %v", rep.from, describe(rep.resp)))
+	}
+	return rep, true
+}
+
+// exploreClock is the clock of an instance taking part in the model checker's executions. Time does not pass, the
+// model checker decides when timeouts expire and backoffs end.
+type exploreClock struct {
+	e  *exploration
+	si *exploreInstance
+}
+
+func (c *exploreClock) Now() time.Time {
+	return time.Unix(0, 0)
+}
+
+// Sleep parks the proposer until the model checker wakes it up. The instance's lock is released meanwhile.
+func (c *exploreClock) Sleep(d time.Duration) {
+	p := c.si.proposer
+	p.sleeps++
+	p.status = "sleeping"
+	c.e.park(p)
+}
+
+func (c *exploreClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx := &simContext{Context: parent, deadline: c.Now().Add(d), done: make(chan struct{})}
+	return ctx, func() {
+		ctx.finish(context.Canceled)
+	}
+}
+
+// exploreResult is the outcome of exploring all executions
+type exploreResult struct {
+	states int
+	// ends are the states in which no action is left
+	ends int
+	// grants are the locks granted in those states
+	grants int
+	// counterexample is the first execution that violated safety, nil if there is none
+	counterexample *exploration
+}
+
+// explore explores all executions of proposers that retry the given number of times. Setup, if not nil, changes every
+// execution before it starts.
+//
+// Only some of the actions are taken in states with a waiting proposer, see choices. This still reaches every state
+// in which no action is left. A violation is never undone, so it is found in one of those states at the latest.
+func explore(retries int, setup func(e *exploration)) exploreResult {
+	result := exploreResult{}
+	visited := make(map[string]bool)
+	paths := [][]int{{}}
+	for len(paths) > 0 {
+		path := paths[len(paths)-1]
+		paths = paths[:len(paths)-1]
+
+		e := newExploration(retries)
+		if setup != nil {
+			setup(e)
+		}
+		for _, i := range path {
+			e.do(e.actions()[i])
+		}
+		for {
+			key := e.key()
+			if visited[key] {
+				break
+			}
+			visited[key] = true
+			result.states++
+			if len(e.violations) > 0 {
+				e.stop()
+				result.counterexample = replay(retries, setup, path)
+				return result
+			}
+			actions := e.actions()
+			choices := e.choices(actions)
+			if len(choices) == 0 {
+				result.ends++
+				for _, p := range e.proposers {
+					if p.acquired {
+						result.grants++
+					}
+				}
+				break
+			}
+			for _, i := range choices[1:] {
+				paths = append(paths, append(append([]int{}, path...), i))
+			}
+			path = append(path, choices[0])
+			e.do(actions[choices[0]])
+		}
+		e.stop()
+	}
+	return result
+}
+
+// replay replays an execution and records what happens
+func replay(retries int, setup func(e *exploration), path []int) *exploration {
+	e := newExploration(retries)
+	e.tracing = true
+	if setup != nil {
+		setup(e)
+	}
+	for _, i := range path {
+		e.do(e.actions()[i])
+	}
+	e.stop()
+	return e
+}
+
+/* --- end: test helper: model checker ------------------------------------------------------------------------------ */
+
+func TestExplore(t *testing.T) {
+	retries := *flagExploreRetries
+	if testing.Short() {
+		retries = 0
+	}
+	result := explore(retries, nil)
+	if e := result.counterexample; e != nil {
+		t.Fatalf("%v\n\ncounterexample:\n%v", strings.Join(e.violations, "\n"), strings.Join(e.trace, "\n"))
+	}
+
+	// make sure the proposers get anywhere at all
+	if result.grants == 0 {
+		t.Errorf("expected locks to be granted, got none in %v states", result.states)
+	}
+	t.Logf("%v retries: %v states, %v of them final, %v grants", retries, result.states, result.ends, result.grants)
+}
+
+func TestExploreCounterexample(t *testing.T) {
+	// in-1 believes it is on its own, so it is a majority by itself
+	result := explore(0, func(e *exploration) {
+		e.instances[0].in.peers = nil
+	})
+	e := result.counterexample
+	if e == nil {
+		t.Fatalf("expected a counterexample, got none in %v states", result.states)
+	}
+	if len(e.violations) == 0 {
+		t.Errorf("expected violations, got none")
+	}
+	if len(e.trace) == 0 || !strings.HasPrefix(e.trace[len(e.trace)-1], "    ") {
+		t.Errorf("expected a trace ending with the state of the instances, got `%v`", e.trace)
+	}
+	t.Logf("%v\n%v", strings.Join(e.violations, "\n"), strings.Join(e.trace, "\n"))
+}
@@ -0,0 +1,246 @@
+package skinny
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	pb "github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// faultPeerKey is the metadata key carrying the name of the instance that sends a consensus request
+const faultPeerKey = "skinny-peer"
+
+// consensus methods as seen by gRPC interceptors
+const (
+	methodConsensus = "/Consensus/"
+	methodPromise   = "/Consensus/Promise"
+	methodCommit    = "/Consensus/Commit"
+)
+
+// fault is a failure injected into consensus requests between the instance and a peer
+type fault struct {
+	// peer is the peer the fault applies to, empty for all peers
+	peer      string
+	latency   time.Duration
+	drop      uint32
+	partition bool
+}
+
+// healthy returns true if the fault does not disturb anything
+func (f fault) healthy() bool {
+	return f.latency == 0 && f.drop == 0 && !f.partition
+}
+
+// String describes the fault, e.g. "partitioned from peer-1"
+func (f fault) String() string {
+	peer := f.peer
+	if peer == "" {
+		peer = "all peers"
+	}
+	effects := []string{}
+	if f.partition {
+		effects = append(effects, "partitioned from "+peer)
+	}
+	if f.latency > 0 {
+		effects = append(effects, fmt.Sprintf("%v latency to and from %v", f.latency, peer))
+	}
+	if f.drop > 0 {
+		effects = append(effects, fmt.Sprintf("%v%% of promises and commits to and from %v dropped", f.drop, peer))
+	}
+	if len(effects) == 0 {
+		return "healed " + peer
+	}
+	return strings.Join(effects, ", ")
+}
+
+// parseFault converts a fault as found in a request
+func parseFault(f *pb.Fault) (fault, error) {
+	parsed := fault{
+		peer:      f.Peer,
+		drop:      f.Drop,
+		partition: f.Partition,
+	}
+	if f.Latency != "" {
+		latency, err := time.ParseDuration(f.Latency)
+		if err != nil || latency < 0 {
+			return parsed, fmt.Errorf("invalid latency: %v", f.Latency)
+		}
+		parsed.latency = latency
+	}
+	if f.Drop > 100 {
+		return parsed, fmt.Errorf("invalid drop percentage: %v", f.Drop)
+	}
+	return parsed, nil
+}
+
+// InjectFault disturbs consensus requests between the instance and its peers, e.g. for failure drills. Faults take
+// effect through the interceptors returned by FaultServerInterceptor and FaultClientInterceptor.
+func (in *Instance) InjectFault(ctx context.Context, req *pb.InjectFaultRequest) (*pb.InjectFaultResponse, error) {
+	faults := []fault{}
+	for _, f := range req.Faults {
+		parsed, err := parseFault(f)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		faults = append(faults, parsed)
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	// check all peers before injecting anything, so that an invalid request changes nothing
+	for _, f := range faults {
+		if f.peer == "" {
+			continue
+		}
+		known := false
+		for _, p := range in.peers {
+			known = known || p.name == f.peer
+		}
+		if !known {
+			return nil, status.Errorf(codes.InvalidArgument, "%v: %v", ErrUnknownPeer, f.peer)
+		}
+	}
+
+	in.faultsMu.Lock()
+	if req.Clear {
+		in.faults = nil
+		in.log.infof("fault: healed all peers\n")
+	}
+	for _, f := range faults {
+		kept := []fault{}
+		for _, old := range in.faults {
+			if old.peer != f.peer {
+				kept = append(kept, old)
+			}
+		}
+		if !f.healthy() {
+			kept = append(kept, f)
+		}
+		in.faults = kept
+		in.log.infof("fault: %v\n", f)
+	}
+	in.faultsMu.Unlock()
+	in.notify()
+
+	return &pb.InjectFaultResponse{
+		Faults: in.faultStatus(),
+	}, nil
+}
+
+// faultStatus returns all injected faults
+func (in *Instance) faultStatus() []*pb.Fault {
+	in.faultsMu.Lock()
+	defer in.faultsMu.Unlock()
+
+	faults := []*pb.Fault{}
+	for _, f := range in.faults {
+		status := pb.Fault{
+			Peer:      f.peer,
+			Drop:      f.drop,
+			Partition: f.partition,
+		}
+		if f.latency > 0 {
+			status.Latency = f.latency.String()
+		}
+		faults = append(faults, &status)
+	}
+	return faults
+}
+
+// faultFor returns the fault injected for a peer. Faults for a single peer take precedence over faults for all peers.
+func (in *Instance) faultFor(peer string) (fault, bool) {
+	in.faultsMu.Lock()
+	defer in.faultsMu.Unlock()
+
+	var found *fault
+	for i := range in.faults {
+		f := &in.faults[i]
+		if f.peer == peer && peer != "" {
+			return *f, true
+		}
+		if f.peer == "" {
+			found = f
+		}
+	}
+	if found == nil {
+		return fault{}, false
+	}
+	return *found, true
+}
+
+// applyFault delays or fails a consensus request to or from a peer according to the injected faults. A dropped request
+// is lost, so it only fails once its context is done.
+func (in *Instance) applyFault(ctx context.Context, peer, method string) error {
+	f, ok := in.faultFor(peer)
+	if !ok {
+		return nil
+	}
+	if f.partition {
+		in.log.debugf("fault: %v %v: partitioned\n", method, peer)
+		return status.Errorf(codes.Unavailable, "fault injected: partitioned from %v", peer)
+	}
+	if f.latency > 0 {
+		select {
+		case <-time.After(f.latency):
+		case <-ctx.Done():
+			return contextError(ctx.Err())
+		}
+	}
+	if f.drop > 0 && (method == methodPromise || method == methodCommit) && uint32(rand.Intn(100)) < f.drop {
+		in.log.debugf("fault: %v %v: dropped\n", method, peer)
+		<-ctx.Done()
+		return contextError(ctx.Err())
+	}
+	return nil
+}
+
+// FaultServerInterceptor returns an interceptor that applies injected faults to consensus requests the instance
+// receives. Peers connected with FaultClientInterceptor tell who they are, requests from others are only subject to
+// faults for all peers.
+func (in *Instance) FaultServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodConsensus) {
+			return handler(ctx, req)
+		}
+		if err := in.applyFault(ctx, requestPeer(ctx), info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// requestPeer returns the name of the peer that sent a request, empty if the peer did not tell
+func requestPeer(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if names := md.Get(faultPeerKey); len(names) > 0 {
+			return names[0]
+		}
+	}
+	return ""
+}
+
+// FaultClientInterceptor returns an interceptor for the connection to a peer. It applies injected faults to consensus
+// requests the instance sends to the peer.
+func (in *Instance) FaultClientInterceptor(peer string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !strings.HasPrefix(method, methodConsensus) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		// the name is set once and never changes
+		ctx = metadata.AppendToOutgoingContext(ctx, faultPeerKey, in.name)
+		if err := in.applyFault(ctx, peer, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,257 @@
+package skinny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestFaultString(t *testing.T) {
+	for _, tc := range []struct {
+		f    fault
+		want string
+	}{
+		{fault{peer: "peer-1"}, "healed peer-1"},
+		{fault{partition: true}, "partitioned from all peers"},
+		{fault{peer: "peer-1", latency: 200 * time.Millisecond}, "200ms latency to and from peer-1"},
+		{
+			fault{peer: "peer-1", latency: time.Second, drop: 30},
+			"1s latency to and from peer-1, 30% of promises and commits to and from peer-1 dropped",
+		},
+	} {
+		if got := tc.f.String(); got != tc.want {
+			t.Errorf("expected `%v`, got `%v`", tc.want, got)
+		}
+	}
+}
+
+func TestInstanceInjectFaultRPC(t *testing.T) {
+	in := Instance{
+		peers: []peer{{name: "peer-1"}, {name: "peer-2"}},
+	}
+
+	t.Run("invalid faults", func(t *testing.T) {
+		for _, f := range []*control.Fault{
+			{Latency: "soon"},
+			{Latency: "-1s"},
+			{Drop: 101},
+			{Peer: "peer-3", Partition: true},
+		} {
+			_, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+				Faults: []*control.Fault{{Peer: "peer-1", Partition: true}, f},
+			})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected `%v`, got `%v`", codes.InvalidArgument, status.Code(err))
+			}
+		}
+		if len(in.faults) != 0 {
+			t.Errorf("expected `%v` faults, got `%v`", 0, len(in.faults))
+		}
+	})
+
+	t.Run("inject", func(t *testing.T) {
+		resp, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+			Faults: []*control.Fault{
+				{Latency: "200ms"},
+				{Peer: "peer-1", Drop: 30},
+				{Peer: "peer-1", Partition: true},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		// the second fault for peer-1 replaced the first one
+		if len(resp.Faults) != 2 {
+			t.Fatalf("expected `%v` faults, got `%v`", 2, len(resp.Faults))
+		}
+		if resp.Faults[0].Latency != "200ms" {
+			t.Errorf("expected `%v`, got `%v`", "200ms", resp.Faults[0].Latency)
+		}
+		if !resp.Faults[1].Partition || resp.Faults[1].Drop != 0 {
+			t.Errorf("expected partition only, got `%v`", resp.Faults[1])
+		}
+
+		f, ok := in.faultFor("peer-1")
+		if !ok || !f.partition {
+			t.Errorf("expected peer-1 to be partitioned, got `%v`", f)
+		}
+		f, ok = in.faultFor("peer-2")
+		if !ok || f.latency != 200*time.Millisecond {
+			t.Errorf("expected 200ms latency for peer-2, got `%v`", f)
+		}
+
+		if got := len(in.status().Faults); got != 2 {
+			t.Errorf("expected `%v` faults in status, got `%v`", 2, got)
+		}
+	})
+
+	t.Run("heal", func(t *testing.T) {
+		resp, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+			Faults: []*control.Fault{{Peer: "peer-1"}},
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(resp.Faults) != 1 {
+			t.Errorf("expected `%v` faults, got `%v`", 1, len(resp.Faults))
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		resp, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+			Clear: true,
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(resp.Faults) != 0 {
+			t.Errorf("expected `%v` faults, got `%v`", 0, len(resp.Faults))
+		}
+		if _, ok := in.faultFor("peer-1"); ok {
+			t.Errorf("expected no fault for peer-1")
+		}
+	})
+}
+
+func TestFaultClientInterceptor(t *testing.T) {
+	in := Instance{
+		name:  "leader",
+		peers: []peer{{name: "peer-1"}},
+	}
+	intercept := in.FaultClientInterceptor("peer-1")
+
+	invoked := false
+	var sender string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		opts ...grpc.CallOption) error {
+		invoked = true
+		if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(faultPeerKey)) > 0 {
+			sender = md.Get(faultPeerKey)[0]
+		}
+		return nil
+	}
+	inject := func(f *control.Fault) {
+		_, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+			Faults: []*control.Fault{f},
+			Clear:  true,
+		})
+		if err != nil {
+			t.Fatalf("inject fault: %v", err)
+		}
+		invoked = false
+	}
+
+	t.Run("no fault", func(t *testing.T) {
+		err := intercept(context.Background(), methodPromise, nil, nil, nil, invoker)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if !invoked {
+			t.Errorf("expected request to be sent")
+		}
+		if sender != "leader" {
+			t.Errorf("expected `%v`, got `%v`", "leader", sender)
+		}
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		inject(&control.Fault{Peer: "peer-1", Partition: true})
+		err := intercept(context.Background(), "/Consensus/Ping", nil, nil, nil, invoker)
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("expected `%v`, got `%v`", codes.Unavailable, status.Code(err))
+		}
+		if invoked {
+			t.Errorf("expected request not to be sent")
+		}
+
+		// other services are not disturbed
+		err = intercept(context.Background(), "/Control/Status", nil, nil, nil, invoker)
+		if err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		inject(&control.Fault{Latency: "50ms"})
+		start := time.Now()
+		err := intercept(context.Background(), methodCommit, nil, nil, nil, invoker)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("expected at least `%v`, got `%v`", 50*time.Millisecond, elapsed)
+		}
+		if !invoked {
+			t.Errorf("expected request to be sent")
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		inject(&control.Fault{Peer: "peer-1", Drop: 100})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := intercept(ctx, methodPromise, nil, nil, nil, invoker)
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("expected `%v`, got `%v`", codes.DeadlineExceeded, status.Code(err))
+		}
+		if invoked {
+			t.Errorf("expected request not to be sent")
+		}
+
+		// pings are never dropped
+		err = intercept(context.Background(), "/Consensus/Ping", nil, nil, nil, invoker)
+		if err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+	})
+}
+
+func TestFaultServerInterceptor(t *testing.T) {
+	in := Instance{
+		name:  "leader",
+		peers: []peer{{name: "peer-1"}, {name: "peer-2"}},
+	}
+	_, err := in.InjectFault(context.Background(), &control.InjectFaultRequest{
+		Faults: []*control.Fault{{Peer: "peer-1", Partition: true}},
+	})
+	if err != nil {
+		t.Fatalf("inject fault: %v", err)
+	}
+	intercept := in.FaultServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "handled", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: methodPromise}
+
+	t.Run("partitioned peer", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(faultPeerKey, "peer-1"))
+		_, err := intercept(ctx, nil, info, handler)
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("expected `%v`, got `%v`", codes.Unavailable, status.Code(err))
+		}
+	})
+
+	t.Run("other peer", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(faultPeerKey, "peer-2"))
+		resp, err := intercept(ctx, nil, info, handler)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp != "handled" {
+			t.Errorf("expected `%v`, got `%v`", "handled", resp)
+		}
+	})
+
+	t.Run("unknown sender", func(t *testing.T) {
+		_, err := intercept(context.Background(), nil, info, handler)
+		if err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/danrl/skinny/proto/consensus"
 	"github.com/danrl/skinny/proto/lock"
 )
 
@@ -31,7 +30,7 @@ func TestIntegration(t *testing.T) {
 				// do not self-peer
 				continue
 			}
-			err := mi.in.AddPeer(peer.in.name, consensus.NewConsensusClient(peer.conn))
+			err := mi.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn))
 			if err != nil {
 				t.Fatalf("add peer: %v", err)
 			}
@@ -2,7 +2,6 @@ package skinny
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"time"
 
@@ -12,66 +11,114 @@ import (
 // Acquire tries to acquire a lock
 func (in *Instance) Acquire(ctx context.Context, req *pb.AcquireRequest) (*pb.AcquireResponse, error) {
 	in.mu.Lock()
-	fmt.Printf("client: acquire lock on behalf of '%v'\n", req.Holder)
+	if in.draining {
+		in.mu.Unlock()
+		return nil, ErrDraining
+	}
+	in.inflight.Add(1)
+	defer in.inflight.Done()
+	in.proposing++
+	in.notify()
+	in.log.infof("client: acquire lock on behalf of '%v'\n", req.Holder)
+	request := in.recordRequest(RecordAcquire, req.Holder)
 	retries := 0
 retry:
-	promised := in.propose()
-	if promised {
+	committed := false
+	if in.propose() {
 		if in.holder == "" {
 			// The lock is available and we got promised an ID!
-			_ = in.commit(in.promised, req.Holder)
+			committed = in.commit(in.promised, req.Holder)
 		} else {
 			// The lock is not available. Let's commit the learned holder.
-			_ = in.commit(in.promised, in.holder)
+			committed = in.commit(in.promised, in.holder)
 		}
-	} else if retries < 3 {
+	}
+	if !committed && retries < in.retries {
 		retries++
-		backoff := time.Duration(retries) * 2 * time.Millisecond
-		jitter := time.Duration(rand.Int63n(1000)) * time.Microsecond
-		fmt.Printf("waiting %v before retry #%v\n", backoff+jitter, retries)
+		backoff := in.backoffDuration(retries)
+		in.log.infof("waiting %v before retry #%v\n", backoff, retries)
 
 		in.mu.Unlock()
-		time.Sleep(backoff + jitter)
+		in.sleep(backoff)
 		in.mu.Lock()
+		in.resumeRequest(request)
 
-		fmt.Printf("retry #%v\n", retries)
+		in.log.infof("retry #%v\n", retries)
 		goto retry
 	}
 	resp := pb.AcquireResponse{
-		Acquired: in.holder == req.Holder,
+		// without a majority of commits the quorum may still decide for someone else
+		Acquired: committed && in.holder == req.Holder,
 		Holder:   in.holder,
 	}
+	in.recordTraffic(Record{Type: RecordDone, Granted: resp.Acquired})
+	in.proposing--
+	in.notify()
 	in.mu.Unlock()
 
 	return &resp, nil
 }
 
-// Release releases a previously held lock
+// Release releases a previously held lock. If the request names a holder, the lock is only released if it is held by
+// that holder.
 func (in *Instance) Release(ctx context.Context, req *pb.ReleaseRequest) (*pb.ReleaseResponse, error) {
 	in.mu.Lock()
-	fmt.Println("client: release lock")
+	if in.draining {
+		in.mu.Unlock()
+		return nil, ErrDraining
+	}
+	in.inflight.Add(1)
+	defer in.inflight.Done()
+	in.proposing++
+	in.notify()
+	in.log.infof("client: release lock\n")
+	request := in.recordRequest(RecordRelease, req.Holder)
 	retries := 0
 retry:
-	promised := in.propose()
-	if promised {
-		_ = in.commit(in.promised, "")
-	} else if retries < 3 {
+	committed := false
+	if in.propose() {
+		holder := ""
+		if req.Holder != "" && in.holder != req.Holder {
+			// The lock has changed hands. Let's commit the learned holder.
+			holder = in.holder
+		}
+		committed = in.commit(in.promised, holder)
+	}
+	if !committed && retries < in.retries {
 		retries++
-		backoff := time.Duration(retries) * 2 * time.Millisecond
-		jitter := time.Duration(rand.Int63n(1000)) * time.Microsecond
-		fmt.Printf("waiting %v before retry #%v\n", backoff+jitter, retries)
+		backoff := in.backoffDuration(retries)
+		in.log.infof("waiting %v before retry #%v\n", backoff, retries)
 
 		in.mu.Unlock()
-		time.Sleep(backoff + jitter)
+		in.sleep(backoff)
 		in.mu.Lock()
+		in.resumeRequest(request)
 
-		fmt.Printf("retry #%v\n", retries)
+		in.log.infof("retry #%v\n", retries)
 		goto retry
 	}
 	resp := pb.ReleaseResponse{
-		Released: in.holder == "",
+		Released: committed && in.holder == "",
+		Holder:   in.holder,
 	}
+	in.recordTraffic(Record{Type: RecordDone, Granted: resp.Released})
+	in.proposing--
+	in.notify()
 	in.mu.Unlock()
 
 	return &resp, nil
 }
+
+// backoffDuration returns how long to wait before the given retry. Caller must hold a lock on in (Instance).
+func (in *Instance) backoffDuration(retry int) time.Duration {
+	backoff := time.Duration(retry) * in.backoff
+	if in.backoff > 1 {
+		// add up to half a backoff of jitter
+		if in.random != nil {
+			backoff += time.Duration(in.random.Int63n(int64(in.backoff / 2)))
+		} else {
+			backoff += time.Duration(rand.Int63n(int64(in.backoff / 2)))
+		}
+	}
+	return backoff
+}
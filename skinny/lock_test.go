@@ -5,8 +5,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/danrl/skinny/proto/consensus"
-
 	"github.com/danrl/skinny/proto/lock"
 )
 
@@ -49,6 +47,22 @@ func TestInstanceAcquireRPC(t *testing.T) {
 		}
 	})
 
+	t.Run("draining instance", func(t *testing.T) {
+		in := Instance{
+			draining: true,
+		}
+
+		_, err := in.Acquire(context.Background(), &lock.AcquireRequest{
+			Holder: "alien",
+		})
+		if err != ErrDraining {
+			t.Errorf("expected `%v`, got `%v`", ErrDraining, err)
+		}
+		if in.holder != "" {
+			t.Errorf("expected `%v`, got `%v`", "", in.holder)
+		}
+	})
+
 	t.Run("with retry", func(t *testing.T) {
 		if testing.Short() {
 			t.Skip("skipping test in short mode.")
@@ -60,7 +74,7 @@ func TestInstanceAcquireRPC(t *testing.T) {
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
 		peer1.latency = 2 * time.Second
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -68,7 +82,7 @@ func TestInstanceAcquireRPC(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.latency = 2 * time.Second
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -117,6 +131,45 @@ func TestInstanceReleaseRPC(t *testing.T) {
 		}
 	})
 
+	t.Run("lock taken by someone else", func(t *testing.T) {
+		in := Instance{
+			promised: 23,
+			id:       23,
+			holder:   "beaver",
+		}
+
+		resp, err := in.Release(context.Background(), &lock.ReleaseRequest{
+			Holder: "alien",
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Released {
+			t.Errorf("expected `%v`, got `%v`", false, resp.Released)
+		}
+		if resp.Holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", resp.Holder)
+		}
+		if in.holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", in.holder)
+		}
+	})
+
+	t.Run("draining instance", func(t *testing.T) {
+		in := Instance{
+			holder:   "beaver",
+			draining: true,
+		}
+
+		_, err := in.Release(context.Background(), &lock.ReleaseRequest{})
+		if err != ErrDraining {
+			t.Errorf("expected `%v`, got `%v`", ErrDraining, err)
+		}
+		if in.holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", in.holder)
+		}
+	})
+
 	t.Run("with retry", func(t *testing.T) {
 		if testing.Short() {
 			t.Skip("skipping test in short mode.")
@@ -128,7 +181,7 @@ func TestInstanceReleaseRPC(t *testing.T) {
 		peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 		defer peer1.destroy()
 		peer1.latency = 2 * time.Second
-		err := leader.in.AddPeer(peer1.in.name, consensus.NewConsensusClient(peer1.conn))
+		err := leader.in.AddPeer(peer1.in.name, NewGRPCTransport(peer1.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
@@ -136,7 +189,7 @@ func TestInstanceReleaseRPC(t *testing.T) {
 		peer2 := newMockInstance(t, "peer-2", 3, time.Second)
 		defer peer2.destroy()
 		peer2.latency = 2 * time.Second
-		err = leader.in.AddPeer(peer2.in.name, consensus.NewConsensusClient(peer2.conn))
+		err = leader.in.AddPeer(peer2.in.name, NewGRPCTransport(peer2.conn))
 		if err != nil {
 			t.Fatalf("add peer: %v", err)
 		}
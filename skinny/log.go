@@ -0,0 +1,81 @@
+package skinny
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/danrl/skinny/config"
+)
+
+// LogLevel controls how much an instance tells about its work
+type LogLevel int32
+
+const (
+	// LogDebug logs every message sent and received during consensus rounds
+	LogDebug LogLevel = iota
+	// LogInfo logs lock requests, state changes, and errors
+	LogInfo
+	// LogQuiet logs nothing at all
+	LogQuiet
+)
+
+var (
+	// ErrInvalidLogLevel is returned when a log level name is unknown
+	ErrInvalidLogLevel = config.ErrInvalidLogLevel
+)
+
+// ParseLogLevel returns the log level of the given name. The names are those of config.LogLevels, which lists them in
+// the order of the log levels.
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i, l := range config.LogLevels {
+		if name == l {
+			return LogLevel(i), nil
+		}
+	}
+	return LogDebug, ErrInvalidLogLevel
+}
+
+// logger prints messages of an instance, to stdout unless told otherwise. Its zero value logs everything.
+type logger struct {
+	level int32
+	// out is where messages go, stdout if nothing was stored
+	out atomic.Value
+}
+
+// output wraps a writer, because atomic.Value requires all stored values to be of the same concrete type
+type output struct {
+	w io.Writer
+}
+
+func (l *logger) setLevel(level LogLevel) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+func (l *logger) setOutput(w io.Writer) {
+	l.out.Store(output{w: w})
+}
+
+func (l *logger) writer() io.Writer {
+	if out, ok := l.out.Load().(output); ok && out.w != nil {
+		return out.w
+	}
+	return os.Stdout
+}
+
+func (l *logger) enabled(level LogLevel) bool {
+	return LogLevel(atomic.LoadInt32(&l.level)) <= level
+}
+
+func (l *logger) debugf(format string, v ...interface{}) {
+	if l.enabled(LogDebug) {
+		fmt.Fprintf(l.writer(), format, v...)
+	}
+}
+
+func (l *logger) infof(format string, v ...interface{}) {
+	if l.enabled(LogInfo) {
+		fmt.Fprintf(l.writer(), format, v...)
+	}
+}
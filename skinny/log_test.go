@@ -0,0 +1,49 @@
+package skinny
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	for name, expected := range map[string]LogLevel{
+		"debug": LogDebug,
+		"info":  LogInfo,
+		"quiet": LogQuiet,
+	} {
+		got, err := ParseLogLevel(name)
+		if err != nil {
+			t.Errorf("%v: expected `%v`, got `%v`", name, nil, err)
+		}
+		if got != expected {
+			t.Errorf("%v: expected `%v`, got `%v`", name, expected, got)
+		}
+	}
+
+	_, err := ParseLogLevel("chatty")
+	if err != ErrInvalidLogLevel {
+		t.Errorf("expected `%v`, got `%v`", ErrInvalidLogLevel, err)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var l logger
+
+	// the zero value logs everything
+	if !l.enabled(LogDebug) || !l.enabled(LogInfo) {
+		t.Errorf("expected all levels to be enabled")
+	}
+
+	var buf bytes.Buffer
+	l.setOutput(&buf)
+	l.infof("hello %v\n", beaver)
+	if got := buf.String(); got != "hello beaver\n" {
+		t.Errorf("expected `%v`, got `%v`", "hello beaver\n", got)
+	}
+
+	l.setLevel(LogQuiet)
+	l.infof("hello %v\n", beaver)
+	if l.enabled(LogDebug) || l.enabled(LogInfo) || buf.Len() != len("hello beaver\n") {
+		t.Errorf("expected all levels to be disabled")
+	}
+}
@@ -0,0 +1,94 @@
+package skinny
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/danrl/skinny/proto/consensus"
+)
+
+// reply is a peer's answer to a request sent during a consensus phase
+type reply struct {
+	from string
+	// resp is a *pb.PromiseResponse or a *pb.CommitResponse, nil if err is set
+	resp interface{}
+	err  error
+	rtt  time.Duration
+}
+
+// replies are the answers of all peers to a request, in the order they arrive
+type replies interface {
+	// next returns the next reply. It returns false once every peer answered or failed.
+	next() (*reply, bool)
+}
+
+// network carries the requests of a consensus phase to the peers of an instance. Simulations replace the default
+// network to decide when requests and replies are delivered.
+type network interface {
+	// broadcast sends req, a *pb.PromiseRequest or a *pb.CommitRequest, to all peers. Requests still in flight fail
+	// once ctx is done.
+	broadcast(ctx context.Context, from string, peers []peer, req interface{}) replies
+}
+
+// broadcast sends a request to all peers concurrently. In step mode, the requests are held back until they are
+// stepped. Caller must hold a lock on in (Instance).
+func (in *Instance) broadcast(ctx context.Context, req interface{}, stepping bool) replies {
+	if in.network != nil {
+		return in.network.broadcast(ctx, in.name, in.peers, req)
+	}
+	if stepping {
+		return in.holdBack(ctx, in.peers, req, in.timeout)
+	}
+	return fanOut(ctx, in.peers, req)
+}
+
+// channelReplies are replies sent by goroutines
+type channelReplies chan *reply
+
+func (r channelReplies) next() (*reply, bool) {
+	rep, ok := <-r
+	return rep, ok
+}
+
+// fanOut calls every peer in its own goroutine using the peer's transport
+func fanOut(ctx context.Context, peers []peer, req interface{}) replies {
+	responses := make(channelReplies)
+
+	wg := sync.WaitGroup{}
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p peer) {
+			defer wg.Done()
+			responses <- send(ctx, p, req)
+		}(p)
+	}
+
+	// close responses channel once all responses have been received, failed, or canceled
+	go func() {
+		wg.Wait()
+		close(responses)
+	}()
+
+	return responses
+}
+
+// send sends a request to a peer using the peer's transport
+func send(ctx context.Context, p peer, req interface{}) *reply {
+	rep := reply{from: p.name}
+	start := time.Now()
+	switch req := req.(type) {
+	case *pb.PromiseRequest:
+		resp, err := p.transport.Promise(ctx, req)
+		if rep.err = err; err == nil {
+			rep.resp = resp
+		}
+	case *pb.CommitRequest:
+		resp, err := p.transport.Commit(ctx, req)
+		if rep.err = err; err == nil {
+			rep.resp = resp
+		}
+	}
+	rep.rtt = time.Since(start)
+	return &rep
+}
@@ -0,0 +1,89 @@
+package skinny
+
+import (
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// consensus phases
+const (
+	phasePromise = "promise"
+	phaseCommit  = "commit"
+)
+
+// vote outcomes
+const (
+	voteYea    = "yea"
+	voteNay    = "nay"
+	voteFailed = "failed"
+)
+
+// maxVotes is the number of recent votes remembered per peer
+const maxVotes = 8
+
+// rttWeight is the weight of a new sample in the smoothed round-trip time, as in TCP's SRTT (RFC 6298)
+const rttWeight = 8
+
+// Conn is a connection to a peer that knows its state, e.g. a *grpc.ClientConn
+type Conn interface {
+	GetState() connectivity.State
+}
+
+// vote is a peer's answer to a request made in a consensus phase
+type vote struct {
+	phase   string
+	id      uint64
+	outcome string
+}
+
+// SetPeerConn sets the connection to a peer, so that its state shows up in the instance's status. Peers added with a
+// transport that knows its connection, like the gRPC transport, need no call to SetPeerConn.
+func (in *Instance) SetPeerConn(name string, conn Conn) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	for i := range in.peers {
+		if in.peers[i].name == name {
+			in.peers[i].conn = conn
+			in.notify()
+			return nil
+		}
+	}
+	return ErrUnknownPeer
+}
+
+// outcome returns the outcome of a vote given whether the request failed and whether the peer granted it
+func outcome(failed, granted bool) string {
+	switch {
+	case failed:
+		return voteFailed
+	case granted:
+		return voteYea
+	}
+	return voteNay
+}
+
+// observe records a peer's vote and the round-trip time of the request. Caller must hold a lock on in (Instance).
+func (in *Instance) observe(name string, v vote, rtt time.Duration) {
+	for i := range in.peers {
+		p := &in.peers[i]
+		if p.name != name {
+			continue
+		}
+		p.reachable = v.outcome != voteFailed
+		if p.reachable {
+			p.lastSuccess = in.now()
+			if p.rtt == 0 {
+				p.rtt = rtt
+			} else {
+				p.rtt += (rtt - p.rtt) / rttWeight
+			}
+		}
+		p.votes = append(p.votes, v)
+		if len(p.votes) > maxVotes {
+			p.votes = p.votes[len(p.votes)-maxVotes:]
+		}
+		in.notify()
+	}
+}
@@ -0,0 +1,143 @@
+package skinny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/connectivity"
+)
+
+// testConn is a connection that is always in the same state
+type testConn connectivity.State
+
+func (c testConn) GetState() connectivity.State {
+	return connectivity.State(c)
+}
+
+func TestInstanceSetPeerConn(t *testing.T) {
+	in := Instance{
+		peers: []peer{
+			{
+				name: "peer-1",
+			},
+		},
+	}
+
+	err := in.SetPeerConn("peer-1", testConn(connectivity.Connecting))
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if in.peers[0].conn.GetState() != connectivity.Connecting {
+		t.Errorf("expected `%v`, got `%v`", connectivity.Connecting, in.peers[0].conn.GetState())
+	}
+
+	err = in.SetPeerConn("peer-2", testConn(connectivity.Ready))
+	if err != ErrUnknownPeer {
+		t.Errorf("expected `%v`, got `%v`", ErrUnknownPeer, err)
+	}
+}
+
+func TestInstanceObserve(t *testing.T) {
+	in := Instance{
+		peers: []peer{
+			{
+				name:      "peer-1",
+				reachable: true,
+			},
+		},
+	}
+	p := &in.peers[0]
+
+	t.Run("first sample", func(t *testing.T) {
+		in.observe("peer-1", vote{phase: phasePromise, id: 1, outcome: voteYea}, 80*time.Millisecond)
+		if p.rtt != 80*time.Millisecond {
+			t.Errorf("expected `%v`, got `%v`", 80*time.Millisecond, p.rtt)
+		}
+		if p.lastSuccess.IsZero() {
+			t.Errorf("expected last success to be set")
+		}
+	})
+
+	t.Run("smoothing", func(t *testing.T) {
+		in.observe("peer-1", vote{phase: phaseCommit, id: 1, outcome: voteNay}, 160*time.Millisecond)
+		if p.rtt != 90*time.Millisecond {
+			t.Errorf("expected `%v`, got `%v`", 90*time.Millisecond, p.rtt)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		lastSuccess := p.lastSuccess
+		in.observe("peer-1", vote{phase: phasePromise, id: 2, outcome: voteFailed}, time.Second)
+		if p.reachable {
+			t.Errorf("expected `%v`, got `%v`", false, p.reachable)
+		}
+		if p.rtt != 90*time.Millisecond {
+			t.Errorf("expected failed requests not to count, got `%v`", p.rtt)
+		}
+		if p.lastSuccess != lastSuccess {
+			t.Errorf("expected `%v`, got `%v`", lastSuccess, p.lastSuccess)
+		}
+	})
+
+	t.Run("recent votes", func(t *testing.T) {
+		for id := uint64(3); id < 3+2*maxVotes; id++ {
+			in.observe("peer-1", vote{phase: phasePromise, id: id, outcome: voteYea}, time.Millisecond)
+		}
+		if len(p.votes) != maxVotes {
+			t.Fatalf("expected `%v`, got `%v`", maxVotes, len(p.votes))
+		}
+		if p.votes[maxVotes-1].id != 2+2*maxVotes {
+			t.Errorf("expected `%v`, got `%v`", 2+2*maxVotes, p.votes[maxVotes-1].id)
+		}
+		if !p.reachable {
+			t.Errorf("expected `%v`, got `%v`", true, p.reachable)
+		}
+	})
+}
+
+func TestInstanceVotes(t *testing.T) {
+	leader := newMockInstance(t, "leader", 1, time.Second)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+	for _, peer := range []*mockInstance{peer1, peer2} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+	}
+
+	peer2.fail = true
+	_, err := leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	expected := []vote{
+		{phase: phasePromise, id: 1, outcome: voteYea},
+		{phase: phaseCommit, id: 1, outcome: voteYea},
+	}
+	votes := leader.in.peers[0].votes
+	if len(votes) != len(expected) {
+		t.Fatalf("expected `%v`, got `%v`", expected, votes)
+	}
+	for i := range expected {
+		if votes[i] != expected[i] {
+			t.Errorf("expected `%v`, got `%v`", expected[i], votes[i])
+		}
+	}
+	if leader.in.peers[0].rtt == 0 {
+		t.Errorf("expected round-trip time to be measured")
+	}
+	for _, v := range leader.in.peers[1].votes {
+		if v.outcome != voteFailed {
+			t.Errorf("expected `%v`, got `%v`", voteFailed, v.outcome)
+		}
+	}
+	if leader.in.proposing != 0 {
+		t.Errorf("expected `%v`, got `%v`", 0, leader.in.proposing)
+	}
+}
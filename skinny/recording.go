@@ -0,0 +1,176 @@
+package skinny
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// record types
+const (
+	// RecordInstance starts the recording of an instance. A recording may hold several instances, e.g. one per
+	// restart, and each of them is replayed into a fresh instance.
+	RecordInstance = "instance"
+	// RecordConfig is recorded when the peers, the timeout, or the retry policy of the instance changed
+	RecordConfig = "config"
+	// RecordPromise and RecordCommit are recorded when the instance answered a request of a peer
+	RecordPromise = "promise"
+	RecordCommit  = "commit"
+	// RecordAcquire and RecordRelease are recorded when the instance starts serving a lock request
+	RecordAcquire = "acquire"
+	RecordRelease = "release"
+	// RecordSentPromise and RecordSentCommit are recorded when a peer answered a request the instance sent while
+	// serving a lock request, in the order the answers arrived
+	RecordSentPromise = "sent-promise"
+	RecordSentCommit  = "sent-commit"
+	// RecordDone is recorded when the instance answered a lock request
+	RecordDone = "done"
+)
+
+// Record is an entry of a recording of an instance's consensus traffic. Records are written in the order the instance
+// processed them, so that a replay runs through the same state transitions.
+type Record struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	// Request numbers the lock requests the instance served. Records of the same lock request share the number.
+	Request uint64 `json:"request,omitempty"`
+	// Peer sent a request to the instance, or answered a request of the instance. Peers tell who they are only if they
+	// connect with the fault interceptor, like skinnyd does.
+	Peer string `json:"peer,omitempty"`
+	// ID and Holder are the values requested
+	ID     uint64 `json:"id,omitempty"`
+	Holder string `json:"holder,omitempty"`
+	// Granted tells whether the request was promised, committed, or the lock was acquired or released. A promise may
+	// come with a previously committed ID and holder attached.
+	Granted        bool          `json:"granted,omitempty"`
+	AttachedID     uint64        `json:"attachedID,omitempty"`
+	AttachedHolder string        `json:"attachedHolder,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	RTT            time.Duration `json:"rtt,omitempty"`
+	// State is the state of the instance once it answered
+	State *RecordedState `json:"state,omitempty"`
+	// Config is the configuration of the instance
+	Config *RecordedConfig `json:"config,omitempty"`
+}
+
+// RecordedState is the consensus state of an instance
+type RecordedState struct {
+	Promised uint64 `json:"promised"`
+	ID       uint64 `json:"id"`
+	Holder   string `json:"holder"`
+}
+
+// RecordedConfig is the configuration of an instance that matters for consensus
+type RecordedConfig struct {
+	Name      string        `json:"name"`
+	Increment uint64        `json:"increment"`
+	Timeout   time.Duration `json:"timeout"`
+	Retries   int           `json:"retries"`
+	Backoff   time.Duration `json:"backoff"`
+	Peers     []string      `json:"peers"`
+}
+
+// recording writes the consensus traffic of an instance
+type recording struct {
+	enc *json.Encoder
+	// requests is the number of lock requests recorded so far, request is the one the instance is serving
+	requests uint64
+	request  uint64
+}
+
+// SetRecording makes the instance record its consensus traffic to w, one JSON record per line, so that it can be
+// replayed later. A nil writer stops recording. The instance stops recording on the first error writing to w. Set the
+// recording before the instance serves requests, lock requests already in progress would be recorded in part only.
+func (in *Instance) SetRecording(w io.Writer) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if w == nil {
+		in.recording = nil
+		return
+	}
+	in.recording = &recording{enc: json.NewEncoder(w)}
+	in.recordTraffic(Record{Type: RecordInstance, Config: in.recordedConfig()})
+}
+
+// recordTraffic writes a record if the instance is recording. It adds the time and, where it matters, the lock request
+// and the state of the instance. Caller must hold a lock on in (Instance).
+func (in *Instance) recordTraffic(r Record) {
+	if in.recording == nil {
+		return
+	}
+	r.Time = in.now()
+	switch r.Type {
+	case RecordSentPromise, RecordSentCommit, RecordDone:
+		r.Request = in.recording.request
+	}
+	switch r.Type {
+	case RecordInstance, RecordPromise, RecordCommit, RecordDone:
+		r.State = &RecordedState{
+			Promised: in.promised,
+			ID:       in.id,
+			Holder:   in.holder,
+		}
+	}
+	if err := in.recording.enc.Encode(r); err != nil {
+		in.log.infof("recording: %v, stopped recording\n", err)
+		in.recording = nil
+	}
+}
+
+// recordRequest records the start of a lock request and returns its number. Caller must hold a lock on in (Instance).
+func (in *Instance) recordRequest(typ, holder string) uint64 {
+	if in.recording == nil {
+		return 0
+	}
+	in.recording.requests++
+	in.recording.request = in.recording.requests
+	in.recordTraffic(Record{Type: typ, Request: in.recording.request, Holder: holder})
+	return in.recording.request
+}
+
+// resumeRequest continues to record a lock request after the instance waited for a retry, other lock requests may have
+// been served in the meantime. Caller must hold a lock on in (Instance).
+func (in *Instance) resumeRequest(request uint64) {
+	if in.recording != nil {
+		in.recording.request = request
+	}
+}
+
+// recordedConfig returns the configuration of the instance. Caller must hold a lock on in (Instance).
+func (in *Instance) recordedConfig() *RecordedConfig {
+	cfg := RecordedConfig{
+		Name:      in.name,
+		Increment: in.increment,
+		Timeout:   in.timeout,
+		Retries:   in.retries,
+		Backoff:   in.backoff,
+		Peers:     []string{},
+	}
+	for _, p := range in.peers {
+		cfg.Peers = append(cfg.Peers, p.name)
+	}
+	return &cfg
+}
+
+// ReadRecording reads a recording as written by an instance
+func ReadRecording(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,127 @@
+package skinny
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInstanceRecording(t *testing.T) {
+	records := recordTestSession(t)
+
+	t.Run("order", func(t *testing.T) {
+		expected := []string{
+			RecordInstance, RecordConfig, RecordConfig, RecordConfig,
+			// the first proposal fails, a peer asks for a promise while the leader waits for the retry
+			RecordAcquire, RecordSentPromise, RecordSentPromise, RecordPromise,
+			RecordSentPromise, RecordSentPromise,
+			RecordSentPromise, RecordSentPromise, RecordSentCommit, RecordSentCommit, RecordDone,
+		}
+		got := []string{}
+		for _, rec := range records {
+			got = append(got, rec.Type)
+		}
+		if strings.Join(got, " ") != strings.Join(expected, " ") {
+			t.Fatalf("expected `%v`, got `%v`", expected, got)
+		}
+	})
+
+	t.Run("inbound request", func(t *testing.T) {
+		rec := records[7]
+		if rec.Peer != "peer-1" || rec.ID != 5 || !rec.Granted || rec.Request != 0 {
+			t.Errorf("expected a granted promise of ID 5 to peer-1, got `%+v`", rec)
+		}
+		if rec.State == nil || rec.State.Promised != 5 {
+			t.Errorf("expected promised ID `%v`, got `%+v`", 5, rec.State)
+		}
+	})
+
+	t.Run("lock request", func(t *testing.T) {
+		for _, rec := range records[4:] {
+			if rec.Type != RecordPromise && rec.Request != 1 {
+				t.Errorf("expected lock request `%v`, got `%+v`", 1, rec)
+			}
+		}
+		done := records[len(records)-1]
+		if !done.Granted || done.State == nil || done.State.Holder != beaver {
+			t.Errorf("expected the lock to be acquired by `%v`, got `%+v`", beaver, done)
+		}
+	})
+
+	t.Run("read", func(t *testing.T) {
+		_, err := ReadRecording(strings.NewReader("{\"type\":\"instance\"}\n\n{"))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+			t.Errorf("expected an error in line 3, got `%v`", err)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		in := newInstance("instance-1", 1, time.Second)
+		in.log.setLevel(LogQuiet)
+		in.SetRecording(failingWriter{})
+		if in.recording != nil {
+			t.Errorf("expected the instance to stop recording")
+		}
+	})
+}
+
+/* --- begin: test helper: recording -------------------------------------------------------------------------------- */
+
+// recordTestSession records a leader that acquires a lock on the third attempt. A peer asks the leader for a promise
+// while it waits for the second attempt.
+func recordTestSession(t *testing.T) []Record {
+	leader := newMockInstance(t, "leader", 1, time.Second)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+
+	var buf bytes.Buffer
+	leader.in.SetRecording(&buf)
+	for _, peer := range []*mockInstance{peer1, peer2} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+		// the peers promised an ID higher than the first two proposals of the leader
+		peer.in.promised = 6
+	}
+	leader.in.SetRetryPolicy(3, 100*time.Millisecond)
+
+	acquired := make(chan *lock.AcquireResponse, 1)
+	go func() {
+		resp, _ := leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+		acquired <- resp
+	}()
+	time.Sleep(50 * time.Millisecond)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(faultPeerKey, "peer-1"))
+	if _, err := leader.in.Promise(ctx, &consensus.PromiseRequest{ID: 5}); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if resp := <-acquired; !resp.Acquired {
+		t.Fatalf("expected the lock to be acquired, got `%v`", resp)
+	}
+	leader.in.SetRecording(nil)
+
+	records, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	return records
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+/* --- end: test helper: recording ---------------------------------------------------------------------------------- */
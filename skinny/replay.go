@@ -0,0 +1,404 @@
+package skinny
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	pbcs "github.com/danrl/skinny/proto/consensus"
+	pbl "github.com/danrl/skinny/proto/lock"
+)
+
+// errNotRecorded is the answer of a peer to a request the recording has no answer for
+var errNotRecorded = errors.New("not in recording")
+
+// ReplayReport describes the replay of a recorded instance
+type ReplayReport struct {
+	Instance string
+	// Records is the number of records replayed
+	Records int
+	// LockRequests is the number of lock requests served, Requests the number of requests of peers answered
+	LockRequests int
+	Requests     int
+	Divergences  []Divergence
+}
+
+// Divergence is a record the replay did not reproduce
+type Divergence struct {
+	// Record is the number of the record in the recording, counting from 1. It is one past the last record if the
+	// recording ended early.
+	Record   int
+	Type     string
+	Recorded string
+	Replayed string
+}
+
+// Replay feeds a recording into fresh instances, one for every recorded instance, and reports where they did not do
+// what the recorded instances did. It answers the recorded requests of peers, serves the recorded lock requests, and
+// hands over the recorded answers of peers in the order they arrived. Everything happens in a single goroutine on a
+// virtual clock, so a replay runs through the same state transitions every time. The instances log to w, if it is not
+// nil.
+func Replay(records []Record, w io.Writer) ([]*ReplayReport, error) {
+	if len(records) > 0 && records[0].Type != RecordInstance {
+		return nil, fmt.Errorf("record 1: expected type `%v`, got `%v`", RecordInstance, records[0].Type)
+	}
+	r := replayer{
+		records: records,
+		log:     w,
+	}
+	for r.err == nil && r.next < len(r.records) {
+		r.step()
+	}
+	return r.reports, r.err
+}
+
+// replayer replays a recording. It is the clock and the network of the instances it replays into.
+type replayer struct {
+	records []Record
+	// next is the index of the next record to replay
+	next int
+	now  time.Time
+	log  io.Writer
+	err  error
+
+	in      *Instance
+	report  *ReplayReport
+	reports []*ReplayReport
+	// requests are the lock requests being served, the innermost last
+	requests []uint64
+}
+
+// step replays the next record
+func (r *replayer) step() {
+	i := r.next
+	rec := r.records[i]
+	r.consume(rec)
+
+	switch rec.Type {
+	case RecordInstance:
+		r.start(rec)
+	case RecordConfig:
+		r.configure(rec.Config)
+	case RecordPromise:
+		r.report.Requests++
+		resp, err := r.in.Promise(context.Background(), &pbcs.PromiseRequest{ID: rec.ID})
+		if err != nil {
+			r.compare(i, rec, r.failed(rec.Type, err))
+			return
+		}
+		r.compare(i, rec, Record{
+			Type:           rec.Type,
+			Granted:        resp.Promised,
+			AttachedID:     resp.ID,
+			AttachedHolder: resp.Holder,
+			State:          r.state(),
+		})
+	case RecordCommit:
+		r.report.Requests++
+		resp, err := r.in.Commit(context.Background(), &pbcs.CommitRequest{ID: rec.ID, Holder: rec.Holder})
+		if err != nil {
+			r.compare(i, rec, r.failed(rec.Type, err))
+			return
+		}
+		r.compare(i, rec, Record{Type: rec.Type, Granted: resp.Committed, State: r.state()})
+	case RecordAcquire:
+		r.report.LockRequests++
+		r.requests = append(r.requests, rec.Request)
+		resp, err := r.in.Acquire(context.Background(), &pbl.AcquireRequest{Holder: rec.Holder})
+		if err != nil {
+			r.finish(rec.Request, r.failed(RecordDone, err))
+			return
+		}
+		r.finish(rec.Request, Record{Type: RecordDone, Granted: resp.Acquired, State: r.state()})
+	case RecordRelease:
+		r.report.LockRequests++
+		r.requests = append(r.requests, rec.Request)
+		resp, err := r.in.Release(context.Background(), &pbl.ReleaseRequest{Holder: rec.Holder})
+		if err != nil {
+			r.finish(rec.Request, r.failed(RecordDone, err))
+			return
+		}
+		r.finish(rec.Request, Record{Type: RecordDone, Granted: resp.Released, State: r.state()})
+	case RecordSentPromise, RecordSentCommit, RecordDone:
+		// the replayed instance is not serving the lock request the record belongs to
+		r.diverge(i, rec.Type, describeRecord(rec), "nothing")
+	default:
+		r.err = fmt.Errorf("record %v: unknown type `%v`", i+1, rec.Type)
+	}
+}
+
+// consume moves past the next record
+func (r *replayer) consume(rec Record) {
+	r.next++
+	r.now = rec.Time
+	// a record of an instance starts a new report
+	if r.report != nil && rec.Type != RecordInstance {
+		r.report.Records++
+	}
+}
+
+// start replaces the instance with a fresh one
+func (r *replayer) start(rec Record) {
+	if rec.Config == nil {
+		r.err = fmt.Errorf("record %v: instance without configuration", r.next)
+		return
+	}
+	in := newInstance(rec.Config.Name, rec.Config.Increment, rec.Config.Timeout)
+	in.log.setLevel(LogQuiet)
+	if r.log != nil {
+		in.log.setLevel(LogInfo)
+		in.log.setOutput(r.log)
+	}
+	in.clock = r
+	in.network = r
+	in.started = rec.Time
+	if rec.State != nil {
+		in.promised = rec.State.Promised
+		in.id = rec.State.ID
+		in.holder = rec.State.Holder
+	}
+	r.in = in
+	r.report = &ReplayReport{Instance: rec.Config.Name, Records: 1}
+	r.reports = append(r.reports, r.report)
+	r.configure(rec.Config)
+}
+
+// configure changes the configuration of the instance. Peers keep what the instance knows about them.
+func (r *replayer) configure(cfg *RecordedConfig) {
+	if cfg == nil {
+		return
+	}
+	r.in.mu.Lock()
+	defer r.in.mu.Unlock()
+
+	r.in.timeout = cfg.Timeout
+	r.in.retries = cfg.Retries
+	r.in.backoff = cfg.Backoff
+	peers := []peer{}
+	for _, name := range cfg.Peers {
+		p := peer{name: name, reachable: true}
+		for _, known := range r.in.peers {
+			if known.name == name {
+				p = known
+			}
+		}
+		peers = append(peers, p)
+	}
+	r.in.peers = peers
+}
+
+// state returns the state of the instance
+func (r *replayer) state() *RecordedState {
+	r.in.mu.Lock()
+	defer r.in.mu.Unlock()
+
+	return &RecordedState{
+		Promised: r.in.promised,
+		ID:       r.in.id,
+		Holder:   r.in.holder,
+	}
+}
+
+// failed returns the replayed record of a request the instance failed to serve
+func (r *replayer) failed(typ string, err error) Record {
+	return Record{Type: typ, Error: err.Error(), State: r.state()}
+}
+
+// request returns the lock request being served, 0 if there is none
+func (r *replayer) request() uint64 {
+	if len(r.requests) == 0 {
+		return 0
+	}
+	return r.requests[len(r.requests)-1]
+}
+
+// finish compares the answer to a lock request with the recorded one. Records of the lock request the replay did not
+// need are divergences.
+func (r *replayer) finish(request uint64, replayed Record) {
+	defer func() {
+		r.requests = r.requests[:len(r.requests)-1]
+	}()
+	for r.err == nil && r.next < len(r.records) && r.records[r.next].Type != RecordInstance {
+		i := r.next
+		rec := r.records[i]
+		if rec.Request != request {
+			r.step()
+			continue
+		}
+		r.consume(rec)
+		if rec.Type == RecordDone {
+			r.compare(i, rec, replayed)
+			return
+		}
+		r.diverge(i, rec.Type, describeRecord(rec), "nothing")
+	}
+	if r.err == nil {
+		r.diverge(r.next, RecordDone, "nothing", describeRecord(replayed))
+	}
+}
+
+// compare reports a divergence if the replayed answer, error, or state differs from the recorded one
+func (r *replayer) compare(i int, recorded, replayed Record) {
+	if recorded.Error == replayed.Error &&
+		recorded.Granted == replayed.Granted &&
+		recorded.AttachedID == replayed.AttachedID &&
+		recorded.AttachedHolder == replayed.AttachedHolder &&
+		(recorded.State == nil || *recorded.State == *replayed.State) {
+		return
+	}
+	if recorded.State == nil {
+		replayed.State = nil
+	}
+	r.diverge(i, recorded.Type, describeRecord(recorded), describeRecord(replayed))
+}
+
+// diverge adds a divergence at the record of index i to the report
+func (r *replayer) diverge(i int, typ, recorded, replayed string) {
+	r.report.Divergences = append(r.report.Divergences, Divergence{
+		Record:   i + 1,
+		Type:     typ,
+		Recorded: recorded,
+		Replayed: replayed,
+	})
+}
+
+// Now returns the time of the record replayed last
+func (r *replayer) Now() time.Time {
+	return r.now
+}
+
+// Sleep replays what happened while a lock request waited for a retry, i.e. everything recorded until the lock request
+// continues
+func (r *replayer) Sleep(time.Duration) {
+	request := r.request()
+	for r.err == nil && r.next < len(r.records) {
+		rec := r.records[r.next]
+		if rec.Request == request || rec.Type == RecordInstance {
+			return
+		}
+		r.step()
+	}
+}
+
+// WithTimeout never times out, requests are answered from the recording right away
+func (r *replayer) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithCancel(parent)
+}
+
+// broadcast answers requests with the answers recorded for them
+func (r *replayer) broadcast(ctx context.Context, from string, peers []peer, req interface{}) replies {
+	round := replayRound{r: r}
+	for _, p := range peers {
+		round.outstanding = append(round.outstanding, p.name)
+	}
+	switch req := req.(type) {
+	case *pbcs.PromiseRequest:
+		round.typ = RecordSentPromise
+		round.id = req.ID
+	case *pbcs.CommitRequest:
+		round.typ = RecordSentCommit
+		round.id = req.ID
+		round.holder = req.Holder
+	}
+	return &round
+}
+
+// replayRound are the recorded answers to a request
+type replayRound struct {
+	r      *replayer
+	typ    string
+	id     uint64
+	holder string
+	// outstanding are the peers that did not answer yet
+	outstanding []string
+}
+
+func (round *replayRound) next() (*reply, bool) {
+	if len(round.outstanding) == 0 {
+		return nil, false
+	}
+	r := round.r
+	if r.err == nil && r.next < len(r.records) {
+		rec := r.records[r.next]
+		if rec.Type == round.typ && rec.Request == r.request() && rec.ID == round.id && rec.Holder == round.holder &&
+			round.answered(rec.Peer) {
+			r.consume(rec)
+			rep := reply{from: rec.Peer, rtt: rec.RTT}
+			switch {
+			case rec.Error != "":
+				rep.err = errors.New(rec.Error)
+			case rec.Type == RecordSentPromise:
+				rep.resp = &pbcs.PromiseResponse{
+					Promised: rec.Granted,
+					ID:       rec.AttachedID,
+					Holder:   rec.AttachedHolder,
+				}
+			default:
+				rep.resp = &pbcs.CommitResponse{Committed: rec.Granted}
+			}
+			return &rep, true
+		}
+	}
+
+	// the recording has no answer for the request
+	to := round.outstanding[0]
+	round.answered(to)
+	sent := Record{Type: round.typ, Peer: to, ID: round.id, Holder: round.holder}
+	r.diverge(r.next, round.typ, "nothing", describeRequest(sent))
+	return &reply{from: to, err: errNotRecorded}, true
+}
+
+// answered removes a peer from the outstanding peers. It returns false if the peer was not outstanding.
+func (round *replayRound) answered(name string) bool {
+	for i, p := range round.outstanding {
+		if p == name {
+			round.outstanding = append(round.outstanding[:i], round.outstanding[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// describeRecord describes a record, e.g. "promise ID 4 to oregon: promised" or "granted (promised 4, ID 4, holder
+// `beaver`)"
+func describeRecord(rec Record) string {
+	text := ""
+	switch {
+	case rec.Error != "":
+		text = fmt.Sprintf("failed: %v", rec.Error)
+	case rec.Type == RecordPromise || rec.Type == RecordSentPromise:
+		text = describeGranted(rec.Granted, "promised")
+	case rec.Type == RecordCommit || rec.Type == RecordSentCommit:
+		text = describeGranted(rec.Granted, "committed")
+	default:
+		text = describeGranted(rec.Granted, "granted")
+	}
+	if rec.AttachedID > 0 {
+		text += fmt.Sprintf(", attached ID %v and holder `%v`", rec.AttachedID, rec.AttachedHolder)
+	}
+	if rec.State != nil {
+		text += fmt.Sprintf(" (promised %v, ID %v, holder `%v`)", rec.State.Promised, rec.State.ID, rec.State.Holder)
+	}
+	if rec.Type == RecordSentPromise || rec.Type == RecordSentCommit {
+		return fmt.Sprintf("%v: %v", describeRequest(rec), text)
+	}
+	return text
+}
+
+// describeRequest describes a request sent to a peer, e.g. "promise ID 4 to oregon"
+func describeRequest(rec Record) string {
+	if rec.Type == RecordSentCommit {
+		return fmt.Sprintf("commit ID %v and holder `%v` to %v", rec.ID, rec.Holder, rec.Peer)
+	}
+	return fmt.Sprintf("promise ID %v to %v", rec.ID, rec.Peer)
+}
+
+// describeGranted returns what was granted, or that it was not
+func describeGranted(granted bool, what string) string {
+	if granted {
+		return what
+	}
+	return "not " + what
+}
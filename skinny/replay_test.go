@@ -0,0 +1,110 @@
+package skinny
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	records := recordTestSession(t)
+
+	t.Run("reproduce", func(t *testing.T) {
+		var log bytes.Buffer
+		reports, err := Replay(records, &log)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(reports) != 1 {
+			t.Fatalf("expected `%v` report, got `%v`", 1, len(reports))
+		}
+		r := reports[0]
+		if r.Instance != "leader" || r.Records != len(records) || r.LockRequests != 1 || r.Requests != 1 {
+			t.Errorf("expected all records of the leader to be replayed, got `%+v`", r)
+		}
+		if len(r.Divergences) != 0 {
+			t.Errorf("expected no divergences, got `%+v`", r.Divergences)
+		}
+		for _, expected := range []string{"promised ID 5", "retry #2", "committing ID 7 and holder `beaver`"} {
+			if !strings.Contains(log.String(), expected) {
+				t.Errorf("expected log to contain `%v`, got `%v`", expected, log.String())
+			}
+		}
+	})
+
+	t.Run("restarts", func(t *testing.T) {
+		reports, err := Replay(append(append([]Record{}, records...), records...), nil)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if len(reports) != 2 || len(reports[0].Divergences)+len(reports[1].Divergences) != 0 {
+			t.Errorf("expected two instances without divergences, got `%+v`", reports)
+		}
+	})
+
+	t.Run("answer differs", func(t *testing.T) {
+		tampered := append([]Record{}, records...)
+		tampered[7].Granted = false
+		reports, err := Replay(tampered, nil)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		d := reports[0].Divergences
+		if len(d) != 1 || d[0].Record != 8 || d[0].Type != RecordPromise {
+			t.Fatalf("expected a divergence at record `%v`, got `%+v`", 8, d)
+		}
+		if d[0].Recorded != "not promised (promised 5, ID 0, holder ``)" ||
+			d[0].Replayed != "promised (promised 5, ID 0, holder ``)" {
+			t.Errorf("expected the answers to differ, got `%+v`", d[0])
+		}
+	})
+
+	t.Run("rounds differ", func(t *testing.T) {
+		// the peers promise the first proposal, so the replayed leader commits where the recorded one retried
+		tampered := append([]Record{}, records...)
+		tampered[5].Granted = true
+		tampered[6].Granted = true
+		reports, err := Replay(tampered, nil)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		d := reports[0].Divergences
+		if len(d) == 0 || d[0].Type != RecordSentCommit || d[0].Recorded != "nothing" {
+			t.Errorf("expected commits missing from the recording, got `%+v`", d)
+		}
+	})
+
+	t.Run("request fails", func(t *testing.T) {
+		// a draining instance refuses the recorded lock request
+		r := replayer{records: records}
+		r.step()
+		r.in.draining = true
+		for r.err == nil && r.next < len(r.records) {
+			r.step()
+		}
+		if r.err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, r.err)
+		}
+		d := r.report.Divergences
+		if len(d) == 0 {
+			t.Fatalf("expected divergences, got none")
+		}
+		last := d[len(d)-1]
+		if last.Record != len(records) || last.Type != RecordDone ||
+			last.Replayed != fmt.Sprintf("failed: %v (promised 0, ID 0, holder ``)", ErrDraining) {
+			t.Errorf("expected the lock request to fail, got `%+v`", last)
+		}
+	})
+
+	t.Run("invalid recordings", func(t *testing.T) {
+		if _, err := Replay(records[1:], nil); err == nil {
+			t.Errorf("expected an error for a recording without an instance")
+		}
+		tampered := append([]Record{}, records...)
+		tampered[3].Type = "teleport"
+		if _, err := Replay(tampered, nil); err == nil || err.Error() != "record 4: unknown type `teleport`" {
+			t.Errorf("expected an error for an unknown record type, got `%v`", err)
+		}
+	})
+}
@@ -0,0 +1,375 @@
+package skinny
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultTimeout is the timeout for RPCs made to peers if a server is not told otherwise
+const DefaultTimeout = 500 * time.Millisecond
+
+var (
+	// ErrNoListener is returned when a server is started without a listening address or listener
+	ErrNoListener = errors.New("no listening address")
+
+	// ErrServerStarted is returned when a server is started more than once
+	ErrServerStarted = errors.New("server already started")
+
+	// ErrServerNotStarted is returned when a server is stopped that is not running
+	ErrServerNotStarted = errors.New("server not started")
+)
+
+// Server runs an instance and serves its Lock, Control, and Consensus APIs over gRPC. It does what skinnyd does, so
+// that other Go programs can embed an instance. A server is started once; a stopped server stays stopped.
+type Server struct {
+	mu sync.Mutex
+	// begin protected fields
+	in           *Instance
+	listen       string
+	listener     net.Listener
+	peers        []serverPeer
+	conns        map[string]*grpc.ClientConn
+	tls          *tls.Config
+	dialOptions  []grpc.DialOption
+	interceptors []grpc.UnaryServerInterceptor
+	recording    io.Writer
+	grpcServer   *grpc.Server
+	stopped      bool
+	// end protected fields
+
+	// done receives the result of serving
+	done chan error
+}
+
+// serverPeer is a peer as configured, in the order it was configured
+type serverPeer struct {
+	name    string
+	address string
+}
+
+// ServerOption configures a server
+type ServerOption func(*Server)
+
+// WithListen sets the address the server listens on, e.g. `localhost:9000`
+func WithListen(address string) ServerOption {
+	return func(s *Server) {
+		s.listen = address
+	}
+}
+
+// WithListener makes the server serve on an existing listener instead of listening by itself. The server closes the
+// listener when it stops.
+func WithListener(listener net.Listener) ServerOption {
+	return func(s *Server) {
+		s.listener = listener
+	}
+}
+
+// WithPeer adds a peer to the instance. It is dialed when the server starts.
+func WithPeer(name, address string) ServerOption {
+	return func(s *Server) {
+		s.peers = append(s.peers, serverPeer{name: name, address: address})
+	}
+}
+
+// WithTimeout sets the timeout for RPCs made to peers
+func WithTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.in.timeout = timeout
+	}
+}
+
+// WithRetryPolicy sets how often and how patiently the instance retries lock requests, see Instance.SetRetryPolicy
+func WithRetryPolicy(retries int, backoff time.Duration) ServerOption {
+	return func(s *Server) {
+		s.in.retries = retries
+		s.in.backoff = backoff
+	}
+}
+
+// WithLogLevel sets how much the instance logs
+func WithLogLevel(level LogLevel) ServerOption {
+	return func(s *Server) {
+		s.in.log.setLevel(level)
+	}
+}
+
+// WithLogOutput sets where the instance writes its log messages, stdout by default
+func WithLogOutput(w io.Writer) ServerOption {
+	return func(s *Server) {
+		s.in.log.setOutput(w)
+	}
+}
+
+// WithTLS makes the server serve TLS and connect to its peers over TLS. The same configuration is used for both, so it
+// needs the certificate of the instance as well as the CAs that signed the certificates of the peers.
+func WithTLS(config *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tls = config
+	}
+}
+
+// WithDialOptions adds options to the connections to peers, e.g. to tune reconnects. The server sets the transport
+// credentials and a unary interceptor by itself.
+func WithDialOptions(opts ...grpc.DialOption) ServerOption {
+	return func(s *Server) {
+		s.dialOptions = append(s.dialOptions, opts...)
+	}
+}
+
+// WithUnaryInterceptor adds an interceptor to all unary RPCs the server serves, e.g. to collect metrics. Interceptors
+// run in the order they were added and before injected faults take effect.
+func WithUnaryInterceptor(interceptor grpc.UnaryServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.interceptors = append(s.interceptors, interceptor)
+	}
+}
+
+// WithRecording makes the instance record its consensus traffic to w from the moment the server starts, see
+// Instance.SetRecording
+func WithRecording(w io.Writer) ServerOption {
+	return func(s *Server) {
+		s.recording = w
+	}
+}
+
+// NewServer returns a new server for an instance of the given name and increment. Start starts it.
+func NewServer(name string, increment uint64, opts ...ServerOption) *Server {
+	s := Server{
+		in:    newInstance(name, increment, DefaultTimeout),
+		conns: make(map[string]*grpc.ClientConn),
+		done:  make(chan error, 1),
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	s.in.log.infof("initialized\n")
+	return &s
+}
+
+// Instance returns the instance of the server, e.g. to configure it further or to ask it for its status
+func (s *Server) Instance() *Instance {
+	return s.in
+}
+
+// Addr returns the address the server listens on, nil if it is not serving
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.grpcServer == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Done returns a channel that receives the result of serving once the server stopped serving: nil after Stop, the
+// error otherwise
+func (s *Server) Done() <-chan error {
+	return s.done
+}
+
+// Start listens, connects to all peers, and serves in the background. The context is used for listening only.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.grpcServer != nil || s.stopped {
+		return ErrServerStarted
+	}
+	listener := s.listener
+	if listener == nil {
+		if s.listen == "" {
+			return ErrNoListener
+		}
+		var lc net.ListenConfig
+		var err error
+		listener, err = lc.Listen(ctx, "tcp", s.listen)
+		if err != nil {
+			return fmt.Errorf("listen: %v", err)
+		}
+	}
+
+	// dial all peers first, so that a failure leaves the instance without peers
+	for _, p := range s.peers {
+		conn, err := s.dial(p)
+		if err != nil {
+			s.disconnect()
+			listener.Close()
+			return err
+		}
+		s.conns[p.name] = conn
+	}
+	if s.recording != nil {
+		s.in.SetRecording(s.recording)
+	}
+	for _, p := range s.peers {
+		if err := s.in.AddPeer(p.name, NewGRPCTransport(s.conns[p.name])); err != nil {
+			s.disconnect()
+			listener.Close()
+			return fmt.Errorf("add peer `%v`: %v", p.name, err)
+		}
+	}
+
+	var opts []grpc.ServerOption
+	if s.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tls)))
+	}
+	interceptors := append(append([]grpc.UnaryServerInterceptor{}, s.interceptors...), s.in.FaultServerInterceptor())
+	opts = append(opts, grpc.UnaryInterceptor(chainUnaryServer(interceptors)))
+	s.grpcServer = grpc.NewServer(opts...)
+	consensus.RegisterConsensusServer(s.grpcServer, s.in)
+	lock.RegisterLockServer(s.grpcServer, s.in)
+	control.RegisterControlServer(s.grpcServer, s.in)
+	s.listener = listener
+
+	go func(grpcServer *grpc.Server) {
+		s.done <- grpcServer.Serve(listener)
+	}(s.grpcServer)
+	s.in.log.infof("serving on %v\n", listener.Addr())
+	return nil
+}
+
+// Stop shuts the server down gracefully: It stops accepting lock requests, waits for in-flight rounds to finish, hangs
+// up on its peers, and stops serving. Once the context is done, the server stops immediately instead. The returned
+// error tells whether draining the instance succeeded.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	grpcServer := s.grpcServer
+	if grpcServer == nil {
+		s.mu.Unlock()
+		return ErrServerNotStarted
+	}
+	s.grpcServer = nil
+	s.stopped = true
+	s.mu.Unlock()
+
+	// The instance's state lives in memory only, so there is no storage to flush after draining.
+	_, err := s.in.Drain(ctx, &control.DrainRequest{})
+	if err != nil {
+		err = fmt.Errorf("drain: %v", err)
+	}
+
+	// we will not propose anymore, so we can hang up on our peers
+	s.mu.Lock()
+	s.disconnect()
+	s.mu.Unlock()
+	// status streams never end on their own
+	s.in.StopWatchers()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		<-stopped
+	}
+	return err
+}
+
+// ReplacePeers points peers of the instance to new addresses, given by name. All peers are dialed first, so that a
+// failure leaves the instance untouched.
+func (s *Server) ReplacePeers(addresses map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := []string{}
+	for name := range addresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int)
+	for i, p := range s.peers {
+		index[p.name] = i
+	}
+	dialed := make(map[string]*grpc.ClientConn)
+	closeDialed := func() {
+		for _, conn := range dialed {
+			conn.Close()
+		}
+	}
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			closeDialed()
+			return ErrUnknownPeer
+		}
+		conn, err := s.dial(serverPeer{name: name, address: addresses[name]})
+		if err != nil {
+			closeDialed()
+			return err
+		}
+		dialed[name] = conn
+	}
+
+	for _, name := range names {
+		conn := dialed[name]
+		s.peers[index[name]].address = addresses[name]
+		if old, ok := s.conns[name]; ok {
+			// the peer is known to the instance once the server is started
+			_ = s.in.ReplacePeer(name, NewGRPCTransport(conn))
+			old.Close()
+			s.conns[name] = conn
+			continue
+		}
+		// not started yet, the peer is dialed again on start
+		conn.Close()
+	}
+	return nil
+}
+
+// dial connects to a peer. Caller must hold a lock on s (Server).
+func (s *Server) dial(p serverPeer) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if s.tls != nil {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(s.tls))}
+	}
+	opts = append(opts, s.dialOptions...)
+	opts = append(opts, grpc.WithUnaryInterceptor(s.in.FaultClientInterceptor(p.name)))
+	conn, err := grpc.Dial(p.address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %v", err)
+	}
+	return conn, nil
+}
+
+// disconnect closes the connections to all peers. Caller must hold a lock on s (Server).
+func (s *Server) disconnect() {
+	for name, conn := range s.conns {
+		conn.Close()
+		delete(s.conns, name)
+	}
+}
+
+// chainUnaryServer returns an interceptor that runs the given interceptors in order, the last one calling the handler
+func chainUnaryServer(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
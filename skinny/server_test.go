@@ -0,0 +1,277 @@
+package skinny
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestServer(t *testing.T) {
+	var calls int32
+	count := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return handler(ctx, req)
+	}
+	var out, recording syncBuffer
+	servers := newTestServers(t, 3, func(i int) []ServerOption {
+		switch i {
+		case 0:
+			return []ServerOption{WithLogOutput(&out), WithLogLevel(LogInfo), WithUnaryInterceptor(count)}
+		case 1:
+			return []ServerOption{WithRecording(&recording)}
+		}
+		return nil
+	})
+	for _, s := range servers {
+		if err := s.Start(context.Background()); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+	}
+
+	t.Run("acquire", func(t *testing.T) {
+		conn := dialTestServer(t, servers[0], grpc.WithInsecure())
+		defer conn.Close()
+		resp, err := lock.NewLockClient(conn).Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+		if err != nil || !resp.Acquired {
+			t.Fatalf("expected the quorum to grant the lock, got `%v`, `%v`", resp, err)
+		}
+		for _, s := range servers {
+			if got := s.Instance().holder; got != beaver {
+				t.Errorf("%v: expected holder `%v`, got `%v`", s.Instance().name, beaver, got)
+			}
+		}
+		if got := atomic.LoadInt32(&calls); got != 1 {
+			t.Errorf("expected `%v` intercepted call, got `%v`", 1, got)
+		}
+	})
+
+	t.Run("log output", func(t *testing.T) {
+		got := out.String()
+		for _, expected := range []string{"initialized", "serving on", "added peer"} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected log output to contain `%v`, got `%v`", expected, got)
+			}
+		}
+	})
+
+	t.Run("recording", func(t *testing.T) {
+		records, err := ReadRecording(strings.NewReader(recording.String()))
+		if err != nil {
+			t.Fatalf("read recording: %v", err)
+		}
+		inbound := 0
+		for _, rec := range records {
+			if rec.Type == RecordPromise || rec.Type == RecordCommit {
+				inbound++
+				if rec.Peer != "instance-1" {
+					t.Errorf("expected request of `%v`, got `%+v`", "instance-1", rec)
+				}
+			}
+		}
+		if inbound != 2 {
+			t.Errorf("expected `%v` requests of peers, got `%v`", 2, inbound)
+		}
+		reports, err := Replay(records, nil)
+		if err != nil || len(reports) != 1 || len(reports[0].Divergences) != 0 {
+			t.Errorf("expected a replay without divergences, got `%+v`, `%v`", reports, err)
+		}
+	})
+
+	t.Run("start twice", func(t *testing.T) {
+		if err := servers[0].Start(context.Background()); err != ErrServerStarted {
+			t.Errorf("expected `%v`, got `%v`", ErrServerStarted, err)
+		}
+	})
+
+	t.Run("replace peers", func(t *testing.T) {
+		err := servers[0].ReplacePeers(map[string]string{"instance-9": "localhost:1"})
+		if err != ErrUnknownPeer {
+			t.Errorf("expected `%v`, got `%v`", ErrUnknownPeer, err)
+		}
+		err = servers[0].ReplacePeers(map[string]string{"instance-2": servers[1].Addr().String()})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		resp, err := servers[0].Instance().Release(context.Background(), &lock.ReleaseRequest{})
+		if err != nil || !resp.Released {
+			t.Errorf("expected release over the replaced peer, got `%v`, `%v`", resp, err)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		for _, s := range servers {
+			if err := s.Stop(context.Background()); err != nil {
+				t.Errorf("expected `%v`, got `%v`", nil, err)
+			}
+			select {
+			case err := <-s.Done():
+				if err != nil {
+					t.Errorf("expected `%v`, got `%v`", nil, err)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("expected the server to stop serving")
+			}
+			if s.Addr() != nil {
+				t.Errorf("expected no address once stopped, got `%v`", s.Addr())
+			}
+		}
+		if err := servers[0].Stop(context.Background()); err != ErrServerNotStarted {
+			t.Errorf("expected `%v`, got `%v`", ErrServerNotStarted, err)
+		}
+		if err := servers[0].Start(context.Background()); err != ErrServerStarted {
+			t.Errorf("expected `%v`, got `%v`", ErrServerStarted, err)
+		}
+	})
+}
+
+func TestServerNoListener(t *testing.T) {
+	s := NewServer("instance-1", 1, WithLogLevel(LogQuiet))
+	if err := s.Start(context.Background()); err != ErrNoListener {
+		t.Errorf("expected `%v`, got `%v`", ErrNoListener, err)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	config := newTestTLSConfig(t)
+	servers := newTestServers(t, 2, func(int) []ServerOption {
+		return []ServerOption{WithTLS(config)}
+	})
+	for _, s := range servers {
+		if err := s.Start(context.Background()); err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		defer s.Stop(context.Background())
+	}
+
+	conn := dialTestServer(t, servers[1], grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	defer conn.Close()
+	resp, err := lock.NewLockClient(conn).Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+	if err != nil || !resp.Acquired {
+		t.Fatalf("expected the quorum to grant the lock over TLS, got `%v`, `%v`", resp, err)
+	}
+	if got := servers[0].Instance().holder; got != beaver {
+		t.Errorf("expected holder `%v`, got `%v`", beaver, got)
+	}
+
+	// a client that does not speak TLS gets nowhere
+	plain := dialTestServer(t, servers[1], grpc.WithInsecure())
+	defer plain.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := lock.NewLockClient(plain).Release(ctx, &lock.ReleaseRequest{}); err == nil {
+		t.Errorf("expected a plain text request to fail")
+	}
+}
+
+/* --- begin: test helper: servers --- */
+
+// newTestServers returns size servers that know each other, listening on random local ports. The servers are quiet
+// unless the options for a server say otherwise.
+func newTestServers(t *testing.T, size int, options func(i int) []ServerOption) []*Server {
+	listeners := []net.Listener{}
+	for i := 0; i < size; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	servers := []*Server{}
+	for i, listener := range listeners {
+		opts := []ServerOption{
+			WithListener(listener),
+			WithTimeout(200 * time.Millisecond),
+			WithLogLevel(LogQuiet),
+		}
+		for j, peer := range listeners {
+			if i != j {
+				opts = append(opts, WithPeer(fmt.Sprintf("instance-%v", j+1), peer.Addr().String()))
+			}
+		}
+		opts = append(opts, options(i)...)
+		servers = append(servers, NewServer(fmt.Sprintf("instance-%v", i+1), uint64(i+1), opts...))
+	}
+	return servers
+}
+
+// dialTestServer connects to a running server
+func dialTestServer(t *testing.T, s *Server, opts ...grpc.DialOption) *grpc.ClientConn {
+	conn, err := grpc.Dial(s.Addr().String(), opts...)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn
+}
+
+// newTestTLSConfig returns a configuration with a self-signed certificate for 127.0.0.1 that trusts itself
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "skinny"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      pool,
+	}
+}
+
+// syncBuffer is a buffer that can be written to concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+/* --- end: test helper: servers --- */
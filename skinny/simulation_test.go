@@ -0,0 +1,639 @@
+package skinny
+
+import (
+	"container/heap"
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	flagSimSeed = flag.Int64("sim.seed", 0, "run a single simulation with this seed, e.g. to replay a failure")
+	flagSimRuns = flag.Int("sim.runs", 2000, "number of simulations to run, each with its own seed")
+)
+
+/* --- begin: test helper: simulation ------------------------------------------------------------------------------- */
+
+// A simulation runs instances against a virtual clock and a virtual network. Only one goroutine runs at a time: either
+// the simulation's event loop or a single actor, e.g. a client waiting for its lock request. Every decision is drawn
+// from a random source seeded with the simulation's seed, so a seed replays exactly.
+
+// errSimUnavailable is returned for requests to crashed instances
+var errSimUnavailable = status.Error(codes.Unavailable, "simulated crash")
+
+// simConfig describes the network and the workload of a simulation
+type simConfig struct {
+	instances int
+	clients   int
+	// ops is the number of lock acquisitions each client attempts
+	ops     int
+	timeout time.Duration
+	// minDelay and maxDelay bound the one-way delay of a message. Random delays reorder messages.
+	minDelay time.Duration
+	maxDelay time.Duration
+	// drop is the probability of a message getting lost
+	drop float64
+	// partitions and crashes are the number of network partitions and instance crashes during the simulation
+	partitions int
+	crashes    int
+}
+
+// randomSimConfig returns a random simulation configuration
+func randomSimConfig(r *rand.Rand) simConfig {
+	return simConfig{
+		instances:  3 + 2*r.Intn(2),
+		clients:    2 + r.Intn(3),
+		ops:        3 + r.Intn(4),
+		timeout:    time.Duration(50+r.Intn(100)) * time.Millisecond,
+		minDelay:   time.Millisecond,
+		maxDelay:   time.Duration(2+r.Intn(40)) * time.Millisecond,
+		drop:       []float64{0, 0.01, 0.05, 0.2}[r.Intn(4)],
+		partitions: r.Intn(3),
+		crashes:    r.Intn(3),
+	}
+}
+
+type simEvent struct {
+	at  time.Duration
+	seq uint64
+	// on is the instance whose lock the event needs, nil if it needs none
+	on *simInstance
+	fn func()
+}
+
+// simEvents is a priority queue of events ordered by time. Events at the same time keep the order they were scheduled
+// in.
+type simEvents []*simEvent
+
+func (e simEvents) Len() int { return len(e) }
+func (e simEvents) Less(i, j int) bool {
+	if e[i].at != e[j].at {
+		return e[i].at < e[j].at
+	}
+	return e[i].seq < e[j].seq
+}
+func (e simEvents) Swap(i, j int)       { e[i], e[j] = e[j], e[i] }
+func (e *simEvents) Push(x interface{}) { *e = append(*e, x.(*simEvent)) }
+func (e *simEvents) Pop() interface{} {
+	old := *e
+	ev := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return ev
+}
+
+// simActor is a goroutine that runs only when the simulation hands over control to it
+type simActor struct {
+	name   string
+	resume chan struct{}
+	done   bool
+}
+
+// simInstance is an instance taking part in a simulation
+type simInstance struct {
+	in      *Instance
+	crashed bool
+	// busy is the actor that holds the instance's lock, if any. Events that need the lock wait while it is held.
+	busy    *simActor
+	waiting []*simEvent
+}
+
+type simulation struct {
+	seed   int64
+	cfg    simConfig
+	rand   *rand.Rand
+	now    time.Duration
+	seq    uint64
+	events simEvents
+
+	instances []*simInstance
+	byName    map[string]*simInstance
+	// partition maps instance names to the side of the partition they are on. Instances on different sides can not
+	// talk to each other.
+	partition map[string]int
+
+	actors  []*simActor
+	current *simActor
+	yield   chan struct{}
+
+	// holders are the clients that currently believe to hold the lock
+	holders []string
+	// committed is the holder committed for every ID ever seen
+	committed  map[uint64]string
+	grants     int
+	violations []string
+	trace      []string
+}
+
+func newSimulation(seed int64) *simulation {
+	r := rand.New(rand.NewSource(seed))
+	s := simulation{
+		seed:      seed,
+		cfg:       randomSimConfig(r),
+		rand:      r,
+		byName:    make(map[string]*simInstance),
+		partition: make(map[string]int),
+		yield:     make(chan struct{}),
+		committed: make(map[uint64]string),
+	}
+
+	for i := 1; i <= s.cfg.instances; i++ {
+		si := &simInstance{
+			in: &Instance{
+				name:      fmt.Sprintf("in-%v", i),
+				increment: uint64(i),
+				timeout:   s.cfg.timeout,
+				retries:   DefaultRetries,
+				backoff:   DefaultBackoff,
+				random:    rand.New(rand.NewSource(r.Int63())),
+				network:   &s,
+			},
+		}
+		si.in.clock = &simClock{sim: &s, si: si}
+		si.in.log.setLevel(LogQuiet)
+		s.instances = append(s.instances, si)
+		s.byName[si.in.name] = si
+	}
+	for _, si := range s.instances {
+		for _, other := range s.instances {
+			if other != si {
+				si.in.peers = append(si.in.peers, peer{name: other.in.name, reachable: true})
+			}
+		}
+	}
+	return &s
+}
+
+// tracef records what happened for the report of a failed simulation
+func (s *simulation) tracef(format string, v ...interface{}) {
+	s.trace = append(s.trace, fmt.Sprintf("%9v  ", s.now)+fmt.Sprintf(format, v...))
+}
+
+// violationf records a breach of safety
+func (s *simulation) violationf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	s.tracef("🚨 %v", msg)
+	s.violations = append(s.violations, fmt.Sprintf("%v: %v", s.now, msg))
+}
+
+// schedule runs fn after d. If on is not nil, fn runs only once no actor holds the instance's lock.
+func (s *simulation) schedule(d time.Duration, on *simInstance, fn func()) {
+	s.seq++
+	heap.Push(&s.events, &simEvent{at: s.now + d, seq: s.seq, on: on, fn: fn})
+}
+
+// delay returns a random one-way delay of a message
+func (s *simulation) delay() time.Duration {
+	return s.cfg.minDelay + time.Duration(s.rand.Int63n(int64(s.cfg.maxDelay-s.cfg.minDelay)+1))
+}
+
+// lost decides whether a message from one instance to another gets lost
+func (s *simulation) lost(from, to string) bool {
+	return s.partition[from] != s.partition[to] || s.byName[from].crashed || s.rand.Float64() < s.cfg.drop
+}
+
+// spawn creates an actor that starts running after d
+func (s *simulation) spawn(name string, d time.Duration, fn func(a *simActor)) {
+	a := &simActor{name: name, resume: make(chan struct{})}
+	s.actors = append(s.actors, a)
+	go func() {
+		<-a.resume
+		fn(a)
+		a.done = true
+		s.yield <- struct{}{}
+	}()
+	s.schedule(d, nil, func() {
+		s.run(a)
+	})
+}
+
+// run hands control to an actor until it parks or finishes. It must be called by the event loop.
+func (s *simulation) run(a *simActor) {
+	s.current = a
+	a.resume <- struct{}{}
+	<-s.yield
+	s.current = nil
+}
+
+// park hands control back to the event loop until the actor is run again. It must be called by the running actor.
+func (s *simulation) park(a *simActor) {
+	s.yield <- struct{}{}
+	<-a.resume
+}
+
+// sleep parks an actor for d
+func (s *simulation) sleep(a *simActor, d time.Duration) {
+	s.schedule(d, nil, func() {
+		s.run(a)
+	})
+	s.park(a)
+}
+
+// enter parks an actor until it can take the instance's lock
+func (s *simulation) enter(a *simActor, si *simInstance) {
+	s.schedule(0, si, func() {
+		si.busy = a
+		s.run(a)
+	})
+	s.park(a)
+}
+
+// step runs the next event. It returns false once there are no events left.
+func (s *simulation) step() bool {
+	if len(s.events) == 0 {
+		return false
+	}
+	ev := heap.Pop(&s.events).(*simEvent)
+	s.now = ev.at
+	if ev.on != nil && ev.on.busy != nil {
+		ev.on.waiting = append(ev.on.waiting, ev)
+		return true
+	}
+	ev.fn()
+	s.unblock()
+	s.check()
+	return true
+}
+
+// unblock runs events that waited for an instance's lock to be released
+func (s *simulation) unblock() {
+	for progress := true; progress; {
+		progress = false
+		for _, si := range s.instances {
+			if si.busy == nil && len(si.waiting) > 0 {
+				ev := si.waiting[0]
+				si.waiting = si.waiting[1:]
+				ev.fn()
+				progress = true
+			}
+		}
+	}
+}
+
+// check verifies that instances never commit different holders for the same ID
+func (s *simulation) check() {
+	for _, si := range s.instances {
+		in := si.in
+		if in.id == 0 {
+			continue
+		}
+		holder, ok := s.committed[in.id]
+		if !ok {
+			s.committed[in.id] = in.holder
+			continue
+		}
+		if holder != in.holder {
+			s.violationf("%v committed holder `%v` for ID %v, but `%v` was committed before", in.name, in.holder,
+				in.id, holder)
+			s.committed[in.id] = in.holder
+		}
+	}
+}
+
+// grant records that a client believes to hold the lock
+func (s *simulation) grant(holder string) {
+	s.grants++
+	for _, h := range s.holders {
+		if h != holder {
+			s.violationf("lock granted to `%v` while `%v` holds it", holder, h)
+		}
+	}
+	s.holders = append(s.holders, holder)
+}
+
+// revoke records that a client gave up the lock
+func (s *simulation) revoke(holder string) {
+	holders := []string{}
+	for _, h := range s.holders {
+		if h != holder {
+			holders = append(holders, h)
+		}
+	}
+	s.holders = holders
+}
+
+// client runs the workload of a single client: acquire the lock on a random instance, hold it for a while, release it
+// on a random instance
+func (s *simulation) client(a *simActor) {
+	for op := 0; op < s.cfg.ops; op++ {
+		si := s.instances[s.rand.Intn(len(s.instances))]
+		if si.crashed {
+			s.tracef("%v: %v is down", a.name, si.in.name)
+			s.sleep(a, s.cfg.timeout)
+			continue
+		}
+		s.enter(a, si)
+		s.tracef("%v: acquire on %v", a.name, si.in.name)
+		resp, _ := si.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: a.name})
+		si.busy = nil
+		s.tracef("%v: acquired %v, holder `%v`", a.name, resp.Acquired, resp.Holder)
+		if !resp.Acquired {
+			s.sleep(a, time.Duration(s.rand.Int63n(int64(s.cfg.timeout))))
+			continue
+		}
+		s.grant(a.name)
+		s.sleep(a, time.Duration(s.rand.Int63n(int64(s.cfg.timeout))))
+
+		// give up the lock before asking the quorum to release it
+		s.revoke(a.name)
+		si = s.instances[s.rand.Intn(len(s.instances))]
+		if si.crashed {
+			s.tracef("%v: %v is down", a.name, si.in.name)
+			continue
+		}
+		s.enter(a, si)
+		s.tracef("%v: release on %v", a.name, si.in.name)
+		rel, _ := si.in.Release(context.Background(), &lock.ReleaseRequest{Holder: a.name})
+		si.busy = nil
+		s.tracef("%v: released %v", a.name, rel.Released)
+	}
+}
+
+// faults schedules partitions and crashes at random times
+func (s *simulation) faults(horizon time.Duration) {
+	for i := 0; i < s.cfg.partitions; i++ {
+		at := time.Duration(s.rand.Int63n(int64(horizon)))
+		length := time.Duration(s.rand.Int63n(int64(horizon / 2)))
+		sides := make(map[string]int)
+		for _, si := range s.instances {
+			sides[si.in.name] = s.rand.Intn(2)
+		}
+		s.schedule(at, nil, func() {
+			s.tracef("partition %v", sides)
+			s.partition = sides
+		})
+		s.schedule(at+length, nil, func() {
+			s.tracef("heal partition")
+			s.partition = make(map[string]int)
+		})
+	}
+	for i := 0; i < s.cfg.crashes; i++ {
+		si := s.instances[s.rand.Intn(len(s.instances))]
+		at := time.Duration(s.rand.Int63n(int64(horizon)))
+		length := time.Duration(s.rand.Int63n(int64(horizon / 2)))
+		s.schedule(at, nil, func() {
+			s.tracef("crash %v", si.in.name)
+			si.crashed = true
+		})
+		s.schedule(at+length, nil, func() {
+			s.tracef("recover %v", si.in.name)
+			si.crashed = false
+		})
+	}
+}
+
+// runSimulation runs the simulation to its end and returns an error if it got stuck
+func (s *simulation) runSimulation() error {
+	horizon := time.Duration(s.cfg.ops) * 4 * s.cfg.timeout
+	s.faults(horizon)
+	for i := 1; i <= s.cfg.clients; i++ {
+		s.spawn(fmt.Sprintf("client-%v", i), time.Duration(s.rand.Int63n(int64(s.cfg.timeout))), s.client)
+	}
+	for s.step() {
+	}
+	for _, a := range s.actors {
+		if !a.done {
+			return fmt.Errorf("%v is stuck", a.name)
+		}
+	}
+	return nil
+}
+
+// report describes a failed simulation
+func (s *simulation) report() string {
+	return fmt.Sprintf("seed %v, %+v\n%v\n\nreplay with: go test ./skinny -run TestSimulation -v -sim.seed=%v",
+		s.seed, s.cfg, strings.Join(s.trace, "\n"), s.seed)
+}
+
+// broadcast sends requests over the virtual network. Replies arrive as events.
+func (s *simulation) broadcast(ctx context.Context, from string, peers []peer, req interface{}) replies {
+	round := &simRound{sim: s, outstanding: make(map[string]bool)}
+	if ctx, ok := ctx.(*simContext); ok {
+		ctx.onDone = append(ctx.onDone, round.fail)
+	}
+	for _, p := range peers {
+		to := p.name
+		round.order = append(round.order, to)
+		round.outstanding[to] = true
+		start := s.now
+		s.tracef("%v → %v: %v", from, to, describe(req))
+		switch {
+		case s.lost(from, to):
+			s.tracef("%v → %v: lost", from, to)
+		case s.byName[to].crashed:
+			s.schedule(s.delay(), nil, func() {
+				round.deliver(&reply{from: to, err: errSimUnavailable, rtt: s.now - start})
+			})
+		default:
+			s.schedule(s.delay(), s.byName[to], func() {
+				resp := s.handle(to, req)
+				s.tracef("%v ← %v: %v", from, to, describe(resp))
+				if s.lost(to, from) {
+					s.tracef("%v ← %v: lost", from, to)
+					return
+				}
+				s.schedule(s.delay(), nil, func() {
+					round.deliver(&reply{from: to, resp: resp, rtt: s.now - start})
+				})
+			})
+		}
+	}
+	return round
+}
+
+// handle serves a request by an instance
+func (s *simulation) handle(name string, req interface{}) interface{} {
+	in := s.byName[name].in
+	switch req := req.(type) {
+	case *consensus.PromiseRequest:
+		resp, _ := in.Promise(context.Background(), req)
+		return resp
+	case *consensus.CommitRequest:
+		resp, _ := in.Commit(context.Background(), req)
+		return resp
+	}
+	return nil
+}
+
+// describe returns a short description of a request or response for the trace
+func describe(msg interface{}) string {
+	switch msg := msg.(type) {
+	case *consensus.PromiseRequest:
+		return fmt.Sprintf("promise ID %v?", msg.ID)
+	case *consensus.PromiseResponse:
+		return fmt.Sprintf("promised %v (ID %v, holder `%v`)", msg.Promised, msg.ID, msg.Holder)
+	case *consensus.CommitRequest:
+		return fmt.Sprintf("commit ID %v, holder `%v`?", msg.ID, msg.Holder)
+	case *consensus.CommitResponse:
+		return fmt.Sprintf("committed %v", msg.Committed)
+	}
+	return fmt.Sprint(msg)
+}
+
+// simRound are the replies to a request broadcast over the virtual network
+type simRound struct {
+	sim         *simulation
+	order       []string
+	outstanding map[string]bool
+	ready       []*reply
+	waiter      *simActor
+}
+
+// deliver hands a reply to the waiting actor, unless the request already failed
+func (r *simRound) deliver(rep *reply) {
+	if !r.outstanding[rep.from] {
+		return
+	}
+	delete(r.outstanding, rep.from)
+	r.ready = append(r.ready, rep)
+	if r.waiter != nil {
+		a := r.waiter
+		r.waiter = nil
+		r.sim.run(a)
+	}
+}
+
+// fail fails all requests still in flight, like gRPC does once a request's context is done
+func (r *simRound) fail(err error) {
+	for _, name := range r.order {
+		if r.outstanding[name] {
+			delete(r.outstanding, name)
+			r.ready = append(r.ready, &reply{from: name, err: err})
+		}
+	}
+	if r.waiter != nil && len(r.ready) > 0 {
+		a := r.waiter
+		r.waiter = nil
+		r.sim.run(a)
+	}
+}
+
+func (r *simRound) next() (*reply, bool) {
+	if len(r.ready) == 0 && len(r.outstanding) > 0 {
+		r.waiter = r.sim.current
+		r.sim.park(r.waiter)
+	}
+	if len(r.ready) == 0 {
+		return nil, false
+	}
+	rep := r.ready[0]
+	r.ready = r.ready[1:]
+	return rep, true
+}
+
+// simClock is the virtual clock of an instance
+type simClock struct {
+	sim *simulation
+	si  *simInstance
+}
+
+func (c *simClock) Now() time.Time {
+	return time.Unix(0, 0).Add(c.sim.now)
+}
+
+// Sleep releases the instance's lock while sleeping, just like lock requests do between retries
+func (c *simClock) Sleep(d time.Duration) {
+	a := c.sim.current
+	c.si.busy = nil
+	c.sim.schedule(d, c.si, func() {
+		c.si.busy = a
+		c.sim.run(a)
+	})
+	c.sim.park(a)
+}
+
+func (c *simClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx := &simContext{Context: parent, deadline: c.Now().Add(d), done: make(chan struct{})}
+	c.sim.schedule(d, nil, func() {
+		ctx.finish(context.DeadlineExceeded)
+	})
+	return ctx, func() {
+		ctx.finish(context.Canceled)
+	}
+}
+
+// simContext is a context that expires on virtual time
+type simContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	err      error
+	onDone   []func(error)
+}
+
+func (c *simContext) Deadline() (time.Time, bool) { return c.deadline, true }
+func (c *simContext) Done() <-chan struct{}       { return c.done }
+func (c *simContext) Err() error                  { return c.err }
+
+func (c *simContext) finish(err error) {
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	for _, fn := range c.onDone {
+		fn(err)
+	}
+}
+
+/* --- end: test helper: simulation --------------------------------------------------------------------------------- */
+
+func TestSimulation(t *testing.T) {
+	seeds := []int64{}
+	if *flagSimSeed != 0 {
+		seeds = append(seeds, *flagSimSeed)
+	} else {
+		runs := *flagSimRuns
+		if testing.Short() {
+			runs /= 10
+		}
+		for seed := int64(1); seed <= int64(runs); seed++ {
+			seeds = append(seeds, seed)
+		}
+	}
+
+	grants := 0
+	for _, seed := range seeds {
+		s := newSimulation(seed)
+		if err := s.runSimulation(); err != nil {
+			t.Fatalf("%v\n%v", err, s.report())
+		}
+		if len(s.violations) > 0 {
+			t.Fatalf("%v\n%v", strings.Join(s.violations, "\n"), s.report())
+		}
+		if len(seeds) == 1 {
+			t.Logf("%v", s.report())
+		}
+		grants += s.grants
+	}
+
+	// make sure the workload is not stuck in contention
+	if grants == 0 {
+		t.Errorf("expected locks to be granted, got none in %v simulations", len(seeds))
+	}
+	t.Logf("%v simulations, %v grants", len(seeds), grants)
+}
+
+func TestSimulationDeterminism(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		first := newSimulation(seed)
+		if err := first.runSimulation(); err != nil {
+			t.Fatalf("%v\n%v", err, first.report())
+		}
+		second := newSimulation(seed)
+		if err := second.runSimulation(); err != nil {
+			t.Fatalf("%v\n%v", err, second.report())
+		}
+		if strings.Join(first.trace, "\n") != strings.Join(second.trace, "\n") {
+			t.Fatalf("seed %v: expected identical traces", seed)
+		}
+	}
+}
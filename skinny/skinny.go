@@ -3,11 +3,15 @@ package skinny
 
 import (
 	"errors"
-	"fmt"
+	"io"
+	"math/rand"
 	"sync"
 	"time"
 
-	pb "github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/config"
+	pbc "github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Instance represents a skinny distributed lock management service instance
@@ -21,53 +25,192 @@ type Instance struct {
 	id        uint64
 	holder    string
 	peers     []peer
+	draining  bool
+	retries   int
+	backoff   time.Duration
+	reload    func() ([]string, error)
+	clock     Clock
+	network   network
+	// random is the source of backoff jitter, nil for the global source
+	random   *rand.Rand
+	watchers map[chan *pbc.StatusResponse]struct{}
+	// proposing is the number of lock requests the instance is serving
+	proposing int
+	// watchersStopped is set once the instance stopped serving status streams
+	watchersStopped bool
+	// messages are the most recent consensus messages sent to peers, oldest first
+	messages []message
+	// recording is where consensus traffic is recorded to, nil if it is not recorded
+	recording *recording
 	// end protected fields
+
+	// faults are failures injected into consensus requests. They have a lock of their own, because requests to peers
+	// are sent while mu is held.
+	faultsMu sync.Mutex
+	faults   []fault
+
+	// Messages held back in step mode have a lock of their own for the same reason.
+	stepMu   sync.Mutex
+	stepMode bool
+	stepSeq  uint64
+	held     []*heldMessage
+
+	// inflight tracks lock requests that are currently being served
+	inflight sync.WaitGroup
+	started  time.Time
+	log      logger
 }
 
 type peer struct {
-	name   string
-	client pb.ConsensusClient
+	name      string
+	transport Transport
+	// conn is the connection to the peer, if known
+	conn Conn
+	// reachable is false if the last request to the peer failed
+	reachable bool
+	// lastSuccess is when the last request to the peer succeeded
+	lastSuccess time.Time
+	// rtt is the smoothed round-trip time of successful requests to the peer
+	rtt time.Duration
+	// votes are the most recent votes of the peer, oldest first
+	votes []vote
 }
 
+const (
+	// DefaultRetries is the number of times a lock request is retried if the quorum did not promise an ID
+	DefaultRetries = config.DefaultRetries
+
+	// DefaultBackoff is the base delay between retries. Each retry waits one backoff longer than the previous one.
+	DefaultBackoff = config.DefaultBackoff
+)
+
+// roles an instance plays in the protocol
+const (
+	roleProposer = "proposer"
+	roleAcceptor = "acceptor"
+)
+
+// Version is the version of Skinny. It is set at build time.
+var Version = "dev"
+
+// Storage describes where an instance keeps its state. Instances keep their state in memory only.
+const Storage = "memory (lost on restart)"
+
 var (
 	// ErrDuplicatePeer is returned when peer already exists in the peer list
 	ErrDuplicatePeer = errors.New("duplicate peer")
+
+	// ErrUnknownPeer is returned when a peer does not exist in the peer list
+	ErrUnknownPeer = errors.New("unknown peer")
+
+	// ErrDraining is returned when a lock request reaches an instance that is draining. It carries the gRPC code
+	// Unavailable so that clients know they may try another instance.
+	ErrDraining = status.Error(codes.Unavailable, "instance is draining")
 )
 
 // New initializes a new skinny instance
 func New(name string, increment uint64, timeout time.Duration) *Instance {
-	in := Instance{
+	in := newInstance(name, increment, timeout)
+	in.log.infof("initialized\n")
+	return in
+}
+
+// newInstance returns a new instance without announcing it, so that callers can configure its logger first
+func newInstance(name string, increment uint64, timeout time.Duration) *Instance {
+	return &Instance{
 		name:      name,
 		increment: increment,
 		timeout:   timeout,
+		retries:   DefaultRetries,
+		backoff:   DefaultBackoff,
+		started:   time.Now(),
 	}
-
-	fmt.Println("initialized")
-	return &in
 }
 
-// AddPeer adds a new peer to the peer list
-func (in *Instance) AddPeer(name string, client pb.ConsensusClient) error {
+// AddPeer adds a new peer to the peer list. Requests to the peer are sent over the given transport.
+func (in *Instance) AddPeer(name string, transport Transport) error {
 	in.mu.Lock()
 	defer in.mu.Unlock()
 
 	// check for duplicate peers
 	for _, p := range in.peers {
-		if p.name == name || p.client == client {
+		if p.name == name || p.transport == transport {
 			return ErrDuplicatePeer
 		}
 	}
 
 	// add peer to the peer list
+	conn, _ := transport.(Conn)
 	in.peers = append(in.peers, peer{
-		name:   name,
-		client: client,
+		name:      name,
+		transport: transport,
+		conn:      conn,
+		reachable: true,
 	})
-	fmt.Printf("added peer %v\n", name)
+	in.log.infof("added peer %v\n", name)
+	in.notify()
+	in.recordTraffic(Record{Type: RecordConfig, Config: in.recordedConfig()})
 
 	return nil
 }
 
+// ReplacePeer replaces the transport used to talk to an existing peer, e.g. after the peer's address changed
+func (in *Instance) ReplacePeer(name string, transport Transport) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	for i := range in.peers {
+		if in.peers[i].name == name {
+			in.peers[i].transport = transport
+			in.peers[i].conn, _ = transport.(Conn)
+			in.peers[i].reachable = true
+			in.log.infof("replaced peer %v\n", name)
+			in.notify()
+			return nil
+		}
+	}
+	return ErrUnknownPeer
+}
+
+// SetTimeout changes the timeout for RPCs made to peers
+func (in *Instance) SetTimeout(timeout time.Duration) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.timeout = timeout
+	in.notify()
+	in.recordTraffic(Record{Type: RecordConfig, Config: in.recordedConfig()})
+}
+
+// SetRetryPolicy changes how often and how patiently lock requests are retried
+func (in *Instance) SetRetryPolicy(retries int, backoff time.Duration) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.retries = retries
+	in.backoff = backoff
+	in.recordTraffic(Record{Type: RecordConfig, Config: in.recordedConfig()})
+}
+
+// SetLogLevel changes how much the instance logs
+func (in *Instance) SetLogLevel(level LogLevel) {
+	in.log.setLevel(level)
+}
+
+// SetLogOutput sets where the instance writes its log messages, stdout by default. A nil writer restores the default.
+func (in *Instance) SetLogOutput(w io.Writer) {
+	in.log.setOutput(w)
+}
+
+// SetReloadFunc sets the function that is called when the instance is asked to reload its configuration. The
+// function returns a description of each change it applied.
+func (in *Instance) SetReloadFunc(fn func() ([]string, error)) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.reload = fn
+}
+
 // isMajority returns true if the n represents a majority in the configured
 // quorum. Caller must hold a (read) lock on i (Instance).
 func (in *Instance) isMajority(n int) bool {
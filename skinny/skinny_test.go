@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/control"
 	"github.com/danrl/skinny/proto/lock"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
@@ -36,6 +37,8 @@ func newMockInstance(t *testing.T, name string, increment uint64, timeout time.D
 			name:      name,
 			increment: increment,
 			timeout:   timeout,
+			retries:   DefaultRetries,
+			backoff:   DefaultBackoff,
 		},
 	}
 
@@ -67,6 +70,7 @@ func newMockInstance(t *testing.T, name string, increment uint64, timeout time.D
 	mi.server = grpc.NewServer()
 	lock.RegisterLockServer(mi.server, mi.in)
 	consensus.RegisterConsensusServer(mi.server, mi.in)
+	control.RegisterControlServer(mi.server, mi.in)
 	go func() {
 		if err := mi.server.Serve(mi.listener); err != nil {
 			t.Logf("serve: %v", err)
@@ -101,6 +105,12 @@ func TestNew(t *testing.T) {
 	if in.timeout != time.Second {
 		t.Errorf("expected timeout `%v`, got `%v`", time.Second, in.timeout)
 	}
+	if in.retries != DefaultRetries {
+		t.Errorf("expected retries `%v`, got `%v`", DefaultRetries, in.retries)
+	}
+	if in.backoff != DefaultBackoff {
+		t.Errorf("expected backoff `%v`, got `%v`", DefaultBackoff, in.backoff)
+	}
 }
 
 func TestInstanceAddPeer(t *testing.T) {
@@ -112,9 +122,9 @@ func TestInstanceAddPeer(t *testing.T) {
 	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
 	defer peer1.destroy()
 
-	client := consensus.NewConsensusClient(peer1.conn)
+	transport := NewGRPCTransport(peer1.conn)
 
-	err := leader.in.AddPeer(peer1.in.name, client)
+	err := leader.in.AddPeer(peer1.in.name, transport)
 	if err != nil {
 		t.Fatalf("expected `%v`, got `%v`", nil, err)
 	}
@@ -126,8 +136,11 @@ func TestInstanceAddPeer(t *testing.T) {
 		if leader.in.peers[0].name != peer1.in.name {
 			t.Errorf("expected peer name `%v`, got `%v`", peer1.in.name, leader.in.peers[0].name)
 		}
-		if leader.in.peers[0].client != client {
-			t.Errorf("expected peer client `%v`, got `%v`", client, leader.in.peers[0].client)
+		if leader.in.peers[0].transport != transport {
+			t.Errorf("expected peer transport `%v`, got `%v`", transport, leader.in.peers[0].transport)
+		}
+		if leader.in.peers[0].conn == nil {
+			t.Errorf("expected peer connection, got `%v`", nil)
 		}
 	})
 
@@ -138,14 +151,90 @@ func TestInstanceAddPeer(t *testing.T) {
 		}
 	})
 
-	t.Run("duplicate peer client", func(t *testing.T) {
-		err := leader.in.AddPeer("totally-different", client)
+	t.Run("duplicate peer transport", func(t *testing.T) {
+		err := leader.in.AddPeer("totally-different", transport)
 		if err != ErrDuplicatePeer {
 			t.Errorf("expected `%v`, got `%v`", ErrDuplicatePeer, err)
 		}
 	})
 }
 
+func TestInstanceReplacePeer(t *testing.T) {
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+
+	var in Instance
+	err := in.AddPeer("peer-1", NewGRPCTransport(peer1.conn))
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	t.Run("known peer", func(t *testing.T) {
+		transport := NewGRPCTransport(peer2.conn)
+		err := in.ReplacePeer("peer-1", transport)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if in.peers[0].transport != transport {
+			t.Errorf("expected peer transport `%v`, got `%v`", transport, in.peers[0].transport)
+		}
+	})
+
+	t.Run("unknown peer", func(t *testing.T) {
+		err := in.ReplacePeer("peer-2", NewGRPCTransport(peer2.conn))
+		if err != ErrUnknownPeer {
+			t.Errorf("expected `%v`, got `%v`", ErrUnknownPeer, err)
+		}
+	})
+}
+
+func TestInstanceSetters(t *testing.T) {
+	var in Instance
+
+	in.SetTimeout(3 * time.Second)
+	if in.timeout != 3*time.Second {
+		t.Errorf("expected timeout `%v`, got `%v`", 3*time.Second, in.timeout)
+	}
+
+	in.SetRetryPolicy(5, 10*time.Millisecond)
+	if in.retries != 5 {
+		t.Errorf("expected retries `%v`, got `%v`", 5, in.retries)
+	}
+	if in.backoff != 10*time.Millisecond {
+		t.Errorf("expected backoff `%v`, got `%v`", 10*time.Millisecond, in.backoff)
+	}
+
+	in.SetLogLevel(LogInfo)
+	if in.log.enabled(LogDebug) {
+		t.Errorf("expected debug logging to be disabled")
+	}
+	if !in.log.enabled(LogInfo) {
+		t.Errorf("expected info logging to be enabled")
+	}
+}
+
+func TestInstanceBackoffDuration(t *testing.T) {
+	in := Instance{
+		backoff: 2 * time.Millisecond,
+	}
+
+	for retry := 1; retry <= 3; retry++ {
+		got := in.backoffDuration(retry)
+		min := time.Duration(retry) * in.backoff
+		max := min + in.backoff/2
+		if got < min || got >= max {
+			t.Errorf("retry #%v: expected backoff in [%v, %v), got `%v`", retry, min, max, got)
+		}
+	}
+
+	in.backoff = 0
+	if got := in.backoffDuration(1); got != 0 {
+		t.Errorf("expected `%v`, got `%v`", 0, got)
+	}
+}
+
 func TestInstanceIsMajority(t *testing.T) {
 	t.Run("lonely instance", func(t *testing.T) {
 		var in Instance
@@ -0,0 +1,261 @@
+package skinny
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pbcs "github.com/danrl/skinny/proto/consensus"
+	pb "github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// step actions
+const (
+	stepDeliver = "deliver"
+	stepDelay   = "delay"
+	stepDrop    = "drop"
+)
+
+// heldMessage is a consensus request held back in step mode
+type heldMessage struct {
+	seq    uint64
+	phase  string
+	to     string
+	id     uint64
+	holder string
+	since  time.Time
+	// step receives the decision of the instructor
+	step chan stepAction
+}
+
+// stepAction is what happens to a held message
+type stepAction struct {
+	drop  bool
+	delay time.Duration
+}
+
+// Step turns step mode on and off, and delivers, delays, or drops consensus requests held back in step mode. In step
+// mode, every Promise and Commit request the instance sends to a peer waits until it is stepped, so that rounds can
+// be followed message by message. Held messages wait as long as it takes. Once delivered, a message times out after
+// the instance's timeout as usual. A dropped message is lost, the instance gives up on it once it timed out.
+func (in *Instance) Step(ctx context.Context, req *pb.StepRequest) (*pb.StepResponse, error) {
+	action := stepAction{}
+	switch req.Action {
+	case "", stepDeliver:
+	case stepDrop:
+		action.drop = true
+	case stepDelay:
+		delay, err := time.ParseDuration(req.Delay)
+		if err != nil || delay < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid delay: %v", req.Delay)
+		}
+		action.delay = delay
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid action: %v", req.Action)
+	}
+	if req.Enable && req.Disable {
+		return nil, status.Error(codes.InvalidArgument, "cannot enable and disable step mode at once")
+	}
+
+	in.stepMu.Lock()
+	defer in.stepMu.Unlock()
+
+	// check all messages before stepping anything, so that an invalid request changes nothing
+	stepped := []*heldMessage{}
+	if req.Action != "" {
+		if len(req.Messages) == 0 {
+			stepped = append(stepped, in.held...)
+		}
+		for _, seq := range req.Messages {
+			m := in.heldMessage(seq)
+			if m == nil {
+				return nil, status.Errorf(codes.NotFound, "no held message #%v", seq)
+			}
+			stepped = append(stepped, m)
+		}
+	}
+
+	if req.Enable && !in.stepMode {
+		in.stepMode = true
+		in.log.infof("step: enabled\n")
+	}
+	resp := pb.StepResponse{}
+	for _, m := range stepped {
+		in.stepMessage(m, action)
+		resp.Stepped = append(resp.Stepped, in.heldStatus(m))
+	}
+	if req.Disable && in.stepMode {
+		in.stepMode = false
+		for len(in.held) > 0 {
+			in.stepMessage(in.held[0], stepAction{})
+		}
+		in.log.infof("step: disabled\n")
+	}
+
+	resp.Enabled = in.stepMode
+	for _, m := range in.held {
+		resp.Held = append(resp.Held, in.heldStatus(m))
+	}
+	return &resp, nil
+}
+
+// stepping returns true if the instance is in step mode
+func (in *Instance) stepping() bool {
+	in.stepMu.Lock()
+	defer in.stepMu.Unlock()
+
+	return in.stepMode
+}
+
+// phaseContext returns the context of a consensus phase. It times out after the instance's timeout, except in step
+// mode where held messages wait for as long as it takes. Stepping tells whether the phase runs in step mode, it is read
+// once per phase, so that the context and the requests agree. Caller must hold a lock on in (Instance).
+func (in *Instance) phaseContext(stepping bool) (context.Context, context.CancelFunc) {
+	if in.network == nil && stepping {
+		return context.WithCancel(context.Background())
+	}
+	return in.withTimeout(context.Background(), in.timeout)
+}
+
+// hold holds back a request to a peer until it is stepped
+func (in *Instance) hold(to string, req interface{}) *heldMessage {
+	in.stepMu.Lock()
+	defer in.stepMu.Unlock()
+
+	in.stepSeq++
+	m := heldMessage{
+		seq:   in.stepSeq,
+		to:    to,
+		since: in.now(),
+		step:  make(chan stepAction, 1),
+	}
+	switch req := req.(type) {
+	case *pbcs.PromiseRequest:
+		m.phase = phasePromise
+		m.id = req.ID
+	case *pbcs.CommitRequest:
+		m.phase = phaseCommit
+		m.id = req.ID
+		m.holder = req.Holder
+	}
+	in.held = append(in.held, &m)
+	in.log.infof("step: holding %v\n", describeHeld(&m))
+	return &m
+}
+
+// unhold forgets a held message, e.g. because the round no longer needs it
+func (in *Instance) unhold(m *heldMessage) {
+	in.stepMu.Lock()
+	defer in.stepMu.Unlock()
+
+	in.removeHeld(m)
+}
+
+// heldMessage returns the held message with the given sequence number, nil if there is none. Caller must hold a lock
+// on in.stepMu.
+func (in *Instance) heldMessage(seq uint64) *heldMessage {
+	for _, m := range in.held {
+		if m.seq == seq {
+			return m
+		}
+	}
+	return nil
+}
+
+// removeHeld removes a message from the held messages. Caller must hold a lock on in.stepMu.
+func (in *Instance) removeHeld(m *heldMessage) {
+	for i := range in.held {
+		if in.held[i] == m {
+			in.held = append(in.held[:i], in.held[i+1:]...)
+			return
+		}
+	}
+}
+
+// stepMessage releases a held message. Caller must hold a lock on in.stepMu.
+func (in *Instance) stepMessage(m *heldMessage, action stepAction) {
+	in.removeHeld(m)
+	m.step <- action
+	switch {
+	case action.drop:
+		in.log.infof("step: dropped %v\n", describeHeld(m))
+	case action.delay > 0:
+		in.log.infof("step: delayed %v by %v\n", describeHeld(m), action.delay)
+	default:
+		in.log.infof("step: delivered %v\n", describeHeld(m))
+	}
+}
+
+// heldStatus converts a held message for responses. Caller must hold a lock on in.stepMu.
+func (in *Instance) heldStatus(m *heldMessage) *pb.HeldMessage {
+	return &pb.HeldMessage{
+		Seq:    m.seq,
+		Phase:  m.phase,
+		To:     m.to,
+		ID:     m.id,
+		Holder: m.holder,
+		Held:   in.now().Sub(m.since).Round(time.Millisecond).String(),
+	}
+}
+
+// describeHeld describes a held message, e.g. "#3 promise ID 4 to peer-1"
+func describeHeld(m *heldMessage) string {
+	if m.phase == phaseCommit {
+		return fmt.Sprintf("#%v commit ID %v and holder `%v` to %v", m.seq, m.id, m.holder, m.to)
+	}
+	return fmt.Sprintf("#%v %v ID %v to %v", m.seq, m.phase, m.id, m.to)
+}
+
+// holdBack sends a request to all peers like fanOut, but holds back every request until it is stepped. Requests time
+// out after timeout once they are delivered.
+func (in *Instance) holdBack(ctx context.Context, peers []peer, req interface{}, timeout time.Duration) replies {
+	responses := make(channelReplies)
+
+	wg := sync.WaitGroup{}
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p peer, m *heldMessage) {
+			defer wg.Done()
+
+			var action stepAction
+			select {
+			case action = <-m.step:
+			case <-ctx.Done():
+				// the round is over, e.g. because the other peers already formed a majority
+				in.unhold(m)
+				responses <- &reply{from: p.name, err: contextError(ctx.Err())}
+				return
+			}
+			if action.delay > 0 {
+				select {
+				case <-time.After(action.delay):
+				case <-ctx.Done():
+					responses <- &reply{from: p.name, err: contextError(ctx.Err())}
+					return
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if action.drop {
+				// a lost request looks like a peer that never answers
+				start := time.Now()
+				<-ctx.Done()
+				responses <- &reply{from: p.name, err: contextError(ctx.Err()), rtt: time.Since(start)}
+				return
+			}
+			responses <- send(ctx, p, req)
+		}(p, in.hold(p.name, req))
+	}
+
+	// close responses channel once all responses have been received, failed, or canceled
+	go func() {
+		wg.Wait()
+		close(responses)
+	}()
+
+	return responses
+}
@@ -0,0 +1,133 @@
+package skinny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInstanceStepRPC(t *testing.T) {
+	leader := newMockInstance(t, "leader", 1, 100*time.Millisecond)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+	for _, peer := range []*mockInstance{peer1, peer2} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+	}
+	step := func(req *control.StepRequest) *control.StepResponse {
+		t.Helper()
+		resp, err := leader.in.Step(context.Background(), req)
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		return resp
+	}
+	// waitHeld waits until the leader holds back the given number of messages
+	waitHeld := func(n int) []*control.HeldMessage {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if resp := step(&control.StepRequest{}); len(resp.Held) == n {
+				return resp.Held
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("expected `%v` held messages, got `%v`", n, step(&control.StepRequest{}).Held)
+		return nil
+	}
+
+	t.Run("enable", func(t *testing.T) {
+		resp := step(&control.StepRequest{Enable: true})
+		if !resp.Enabled || len(resp.Held) != 0 {
+			t.Errorf("expected step mode without held messages, got `%v`", resp)
+		}
+	})
+
+	t.Run("round", func(t *testing.T) {
+		acquired := make(chan *lock.AcquireResponse, 1)
+		go func() {
+			resp, _ := leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+			acquired <- resp
+		}()
+
+		// messages wait longer than the timeout
+		held := waitHeld(2)
+		time.Sleep(2 * leader.in.timeout)
+		if held[0].Phase != phasePromise || held[0].To != "peer-1" || held[1].To != "peer-2" {
+			t.Fatalf("expected promises to both peers, got `%v`", held)
+		}
+
+		// peer-1 completes the majority, so the promise to peer-2 is not needed anymore
+		resp := step(&control.StepRequest{Action: stepDeliver, Messages: []uint64{held[0].Seq}})
+		if len(resp.Stepped) != 1 || resp.Stepped[0].Seq != held[0].Seq {
+			t.Errorf("expected message #%v to be stepped, got `%v`", held[0].Seq, resp.Stepped)
+		}
+
+		held = waitHeld(2)
+		if held[0].Phase != phaseCommit || held[0].Holder != beaver {
+			t.Fatalf("expected commits of holder `%v`, got `%v`", beaver, held)
+		}
+		step(&control.StepRequest{Action: stepDrop, Messages: []uint64{held[1].Seq}})
+		step(&control.StepRequest{Action: stepDelay, Delay: "10ms"})
+
+		select {
+		case resp := <-acquired:
+			if !resp.Acquired {
+				t.Errorf("expected the lock to be acquired, got `%v`", resp)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the round to finish")
+		}
+		if peer1.in.holder != beaver || peer2.in.holder != "" {
+			t.Errorf("expected only peer-1 to commit, got `%v` and `%v`", peer1.in.holder, peer2.in.holder)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, req := range []*control.StepRequest{
+			{Action: "teleport"},
+			{Action: stepDelay, Delay: "soon"},
+			{Enable: true, Disable: true},
+		} {
+			_, err := leader.in.Step(context.Background(), req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%v: expected `%v`, got `%v`", req, codes.InvalidArgument, err)
+			}
+		}
+		_, err := leader.in.Step(context.Background(), &control.StepRequest{Action: stepDeliver, Messages: []uint64{42}})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("expected `%v`, got `%v`", codes.NotFound, err)
+		}
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		released := make(chan *lock.ReleaseResponse, 1)
+		go func() {
+			resp, _ := leader.in.Release(context.Background(), &lock.ReleaseRequest{})
+			released <- resp
+		}()
+		waitHeld(2)
+
+		// all held messages are delivered
+		resp := step(&control.StepRequest{Disable: true})
+		if resp.Enabled || len(resp.Held) != 0 {
+			t.Errorf("expected step mode off without held messages, got `%v`", resp)
+		}
+		select {
+		case resp := <-released:
+			if !resp.Released {
+				t.Errorf("expected the lock to be released, got `%v`", resp)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the round to finish")
+		}
+	})
+}
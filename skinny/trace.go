@@ -0,0 +1,86 @@
+package skinny
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/danrl/skinny/proto/control"
+)
+
+// maxMessages is the number of recent consensus messages an instance remembers
+const maxMessages = 256
+
+// messageCanceled is the outcome of a request that was canceled because the proposer already had a majority
+const messageCanceled = "canceled"
+
+// message is a consensus request an instance sent to a peer while proposing, together with the peer's answer
+type message struct {
+	phase          string
+	to             string
+	id             uint64
+	holder         string
+	attachedID     uint64
+	attachedHolder string
+	outcome        string
+	err            error
+	sent           time.Time
+	rtt            time.Duration
+}
+
+// Trace returns the most recent consensus messages the instance sent to its peers, so that rounds can be followed
+// across the quorum
+func (in *Instance) Trace(ctx context.Context, req *pb.TraceRequest) (*pb.TraceResponse, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	resp := pb.TraceResponse{}
+	for _, m := range in.messages {
+		msg := pb.Message{
+			Phase:          m.phase,
+			From:           in.name,
+			To:             m.to,
+			ID:             m.id,
+			Holder:         m.holder,
+			AttachedID:     m.attachedID,
+			AttachedHolder: m.attachedHolder,
+			Outcome:        m.outcome,
+			Sent:           m.sent.Format(time.RFC3339Nano),
+			RTT:            m.rtt.String(),
+		}
+		if m.err != nil {
+			msg.Error = m.err.Error()
+		}
+		resp.Messages = append(resp.Messages, &msg)
+	}
+	return &resp, nil
+}
+
+// record remembers a message sent to a peer once its reply arrived. Caller must hold a lock on in (Instance).
+func (in *Instance) record(m message, rep *reply) {
+	m.to = rep.from
+	m.err = rep.err
+	m.rtt = rep.rtt
+	m.sent = in.now().Add(-rep.rtt)
+	in.messages = append(in.messages, m)
+	if len(in.messages) > maxMessages {
+		in.messages = in.messages[len(in.messages)-maxMessages:]
+	}
+
+	r := Record{
+		Type:           RecordSentPromise,
+		Peer:           m.to,
+		ID:             m.id,
+		Holder:         m.holder,
+		Granted:        m.outcome == voteYea,
+		AttachedID:     m.attachedID,
+		AttachedHolder: m.attachedHolder,
+		RTT:            m.rtt,
+	}
+	if m.phase == phaseCommit {
+		r.Type = RecordSentCommit
+	}
+	if m.err != nil {
+		r.Error = m.err.Error()
+	}
+	in.recordTraffic(r)
+}
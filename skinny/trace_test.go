@@ -0,0 +1,90 @@
+package skinny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+)
+
+func TestInstanceTraceRPC(t *testing.T) {
+	leader := newMockInstance(t, "leader", 1, 100*time.Millisecond)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+	for _, peer := range []*mockInstance{peer1, peer2} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+	}
+	// peer-2 answers too late for both phases
+	peer2.latency = 200 * time.Millisecond
+
+	for _, holder := range []string{beaver, alien} {
+		_, err := leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: holder})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+	}
+	resp, err := leader.in.Trace(context.Background(), &control.TraceRequest{})
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if len(resp.Messages) != 8 {
+		t.Fatalf("expected `%v` messages, got `%v`", 8, len(resp.Messages))
+	}
+
+	t.Run("promise", func(t *testing.T) {
+		m := resp.Messages[0]
+		if m.Phase != phasePromise || m.From != "leader" || m.To != "peer-1" || m.ID != 1 || m.Outcome != voteYea {
+			t.Errorf("expected promise of ID 1 granted by peer-1, got `%v`", m)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, m.Sent); err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+		if _, err := time.ParseDuration(m.RTT); err != nil {
+			t.Errorf("expected `%v`, got `%v`", nil, err)
+		}
+
+		// the majority was reached without peer-2
+		m = resp.Messages[1]
+		if m.To != "peer-2" || m.Outcome != messageCanceled || m.Error == "" {
+			t.Errorf("expected canceled promise to peer-2, got `%v`", m)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		m := resp.Messages[2]
+		if m.Phase != phaseCommit || m.To != "peer-1" || m.ID != 1 || m.Holder != beaver || m.Outcome != voteYea {
+			t.Errorf("expected commit of ID 1 and holder `%v` granted by peer-1, got `%v`", beaver, m)
+		}
+		m = resp.Messages[3]
+		if m.To != "peer-2" || m.Outcome != voteFailed || m.Error == "" {
+			t.Errorf("expected failed commit to peer-2, got `%v`", m)
+		}
+	})
+
+	t.Run("attached values", func(t *testing.T) {
+		m := resp.Messages[4]
+		if m.Phase != phasePromise || m.ID != 2 || m.AttachedID != 1 || m.AttachedHolder != beaver {
+			t.Errorf("expected promise of ID 2 with attached ID 1 and holder `%v`, got `%v`", beaver, m)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		var in Instance
+		for i := 1; i <= maxMessages+10; i++ {
+			in.record(message{phase: phasePromise, id: uint64(i)}, &reply{from: "peer-1"})
+		}
+		if len(in.messages) != maxMessages {
+			t.Fatalf("expected `%v` messages, got `%v`", maxMessages, len(in.messages))
+		}
+		if in.messages[0].id != 11 {
+			t.Errorf("expected the oldest messages to be dropped, got ID `%v` first", in.messages[0].id)
+		}
+	})
+}
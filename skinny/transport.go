@@ -0,0 +1,128 @@
+package skinny
+
+import (
+	"context"
+
+	pb "github.com/danrl/skinny/proto/consensus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// Transport carries consensus requests from an instance to one of its peers. The protocol does not care how requests
+// travel, e.g. over gRPC or within the same process. A transport also implementing Conn has its state show up in the
+// instance's status.
+type Transport interface {
+	Promise(ctx context.Context, req *pb.PromiseRequest) (*pb.PromiseResponse, error)
+	Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error)
+	Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error)
+}
+
+// grpcTransport sends requests over a gRPC connection
+type grpcTransport struct {
+	conn   *grpc.ClientConn
+	client pb.ConsensusClient
+}
+
+// NewGRPCTransport returns a transport that sends requests to a peer over a gRPC connection
+func NewGRPCTransport(conn *grpc.ClientConn) Transport {
+	return &grpcTransport{
+		conn:   conn,
+		client: pb.NewConsensusClient(conn),
+	}
+}
+
+func (t *grpcTransport) Promise(ctx context.Context, req *pb.PromiseRequest) (*pb.PromiseResponse, error) {
+	return t.client.Promise(ctx, req)
+}
+
+func (t *grpcTransport) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	return t.client.Commit(ctx, req)
+}
+
+func (t *grpcTransport) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return t.client.Ping(ctx, req)
+}
+
+// GetState returns the state of the gRPC connection
+func (t *grpcTransport) GetState() connectivity.State {
+	return t.conn.GetState()
+}
+
+// memoryTransport calls the handlers of an instance in the same process
+type memoryTransport struct {
+	in *Instance
+}
+
+// NewMemoryTransport returns a transport that calls the handlers of a peer in the same process, e.g. for tests or to
+// embed a whole quorum into a program. Like requests over the network, requests fail once their context is done, even
+// if the peer is still busy.
+func NewMemoryTransport(in *Instance) Transport {
+	return &memoryTransport{
+		in: in,
+	}
+}
+
+func (t *memoryTransport) Promise(ctx context.Context, req *pb.PromiseRequest) (*pb.PromiseResponse, error) {
+	resp, err := t.call(ctx, func() (interface{}, error) {
+		return t.in.Promise(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PromiseResponse), nil
+}
+
+func (t *memoryTransport) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	resp, err := t.call(ctx, func() (interface{}, error) {
+		return t.in.Commit(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CommitResponse), nil
+}
+
+func (t *memoryTransport) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	resp, err := t.call(ctx, func() (interface{}, error) {
+		return t.in.Ping(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PingResponse), nil
+}
+
+// call runs fn in its own goroutine and gives up waiting for it once ctx is done. A peer busy with a lock request of
+// its own would otherwise block the caller, and two instances proposing to each other would deadlock.
+func (t *memoryTransport) call(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+
+	type result struct {
+		resp interface{}
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := fn()
+		done <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, contextError(ctx.Err())
+	}
+}
+
+// contextError converts the error of a done context into the status error gRPC would return
+func contextError(err error) error {
+	if err == context.DeadlineExceeded {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Canceled, err.Error())
+}
@@ -0,0 +1,128 @@
+package skinny
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	t.Run("quorum", func(t *testing.T) {
+		instances := []*Instance{}
+		for i := 1; i <= 3; i++ {
+			in := New(fmt.Sprintf("instance-%v", i), uint64(i), time.Second)
+			in.SetLogLevel(LogQuiet)
+			instances = append(instances, in)
+		}
+		for _, in := range instances {
+			for _, peer := range instances {
+				if peer == in {
+					continue
+				}
+				if err := in.AddPeer(peer.name, NewMemoryTransport(peer)); err != nil {
+					t.Fatalf("add peer: %v", err)
+				}
+			}
+		}
+
+		resp, err := instances[0].Acquire(context.Background(), &lock.AcquireRequest{
+			Holder: "beaver",
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if !resp.Acquired {
+			t.Errorf("expected `%v`, got `%v`", true, resp.Acquired)
+		}
+		for _, in := range instances {
+			if in.holder != "beaver" {
+				t.Errorf("%v: expected `%v`, got `%v`", in.name, "beaver", in.holder)
+			}
+		}
+
+		// a different instance learns the lock is taken
+		resp, err = instances[2].Acquire(context.Background(), &lock.AcquireRequest{
+			Holder: "alien",
+		})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Acquired {
+			t.Errorf("expected `%v`, got `%v`", false, resp.Acquired)
+		}
+		if resp.Holder != "beaver" {
+			t.Errorf("expected `%v`, got `%v`", "beaver", resp.Holder)
+		}
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		in := New("instance-1", 1, time.Second)
+		in.SetLogLevel(LogQuiet)
+
+		resp, err := NewMemoryTransport(in).Ping(context.Background(), &consensus.PingRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Name != "instance-1" {
+			t.Errorf("expected `%v`, got `%v`", "instance-1", resp.Name)
+		}
+	})
+
+	t.Run("busy peer", func(t *testing.T) {
+		in := New("instance-1", 1, time.Second)
+		in.SetLogLevel(LogQuiet)
+		in.mu.Lock()
+		defer in.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := NewMemoryTransport(in).Promise(ctx, &consensus.PromiseRequest{ID: 1})
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("expected `%v`, got `%v`", codes.DeadlineExceeded, status.Code(err))
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		in := New("instance-1", 1, time.Second)
+		in.SetLogLevel(LogQuiet)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewMemoryTransport(in).Commit(ctx, &consensus.CommitRequest{ID: 1, Holder: "beaver"})
+		if status.Code(err) != codes.Canceled {
+			t.Errorf("expected `%v`, got `%v`", codes.Canceled, status.Code(err))
+		}
+		if in.holder != "" {
+			t.Errorf("expected `%v`, got `%v`", "", in.holder)
+		}
+	})
+}
+
+func TestGRPCTransport(t *testing.T) {
+	peer := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer.destroy()
+
+	transport := NewGRPCTransport(peer.conn)
+	resp, err := transport.Ping(context.Background(), &consensus.PingRequest{})
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	if resp.Name != "peer-1" {
+		t.Errorf("expected `%v`, got `%v`", "peer-1", resp.Name)
+	}
+
+	conn, ok := transport.(Conn)
+	if !ok {
+		t.Fatalf("expected transport to implement Conn")
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Errorf("expected `%v`, got `%v`", connectivity.Ready, state)
+	}
+}
@@ -0,0 +1,138 @@
+package skinny
+
+import (
+	"time"
+
+	pb "github.com/danrl/skinny/proto/control"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotWatching is returned when a status stream is requested from an instance that stopped serving them
+	ErrNotWatching = status.Error(codes.Unavailable, "instance stopped serving status streams")
+)
+
+// watchBuffer is the number of status updates buffered per watcher. Slow watchers miss intermediate updates, but always
+// receive the latest one.
+const watchBuffer = 64
+
+// WatchStatus streams the status of the instance. The current status is sent right away, followed by every change as
+// it happens.
+func (in *Instance) WatchStatus(req *pb.WatchStatusRequest, stream pb.Control_WatchStatusServer) error {
+	in.mu.Lock()
+	if in.watchersStopped {
+		in.mu.Unlock()
+		return ErrNotWatching
+	}
+	updates := make(chan *pb.StatusResponse, watchBuffer)
+	if in.watchers == nil {
+		in.watchers = make(map[chan *pb.StatusResponse]struct{})
+	}
+	in.watchers[updates] = struct{}{}
+	updates <- in.status()
+	in.mu.Unlock()
+
+	defer func() {
+		in.mu.Lock()
+		delete(in.watchers, updates)
+		in.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return ErrNotWatching
+			}
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// StopWatchers ends all status streams and refuses new ones. Open streams keep a server from stopping gracefully.
+func (in *Instance) StopWatchers() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.watchersStopped = true
+	for updates := range in.watchers {
+		close(updates)
+		delete(in.watchers, updates)
+	}
+}
+
+// notify sends the current status to all watchers. If a watcher's buffer is full, its oldest update is dropped. Caller
+// must hold a lock on in (Instance).
+func (in *Instance) notify() {
+	if len(in.watchers) == 0 {
+		return
+	}
+	status := in.status()
+	for updates := range in.watchers {
+		for {
+			select {
+			case updates <- status:
+			default:
+				// drop the oldest update to make room
+				select {
+				case <-updates:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// status returns the current status of the instance. Caller must hold a lock on in (Instance).
+func (in *Instance) status() *pb.StatusResponse {
+	status := pb.StatusResponse{
+		Name:      in.name,
+		Increment: in.increment,
+		Timeout:   in.timeout.String(),
+		Promised:  in.promised,
+		ID:        in.id,
+		Holder:    in.holder,
+		Draining:  in.draining,
+		Version:   Version,
+		Storage:   Storage,
+		Role:      roleAcceptor,
+		Faults:    in.faultStatus(),
+	}
+	if !in.started.IsZero() {
+		status.Uptime = in.now().Sub(in.started).Round(time.Second).String()
+	}
+	if in.proposing > 0 {
+		status.Role = roleProposer
+	}
+
+	for _, peer := range in.peers {
+		p := pb.StatusResponse_Peer{
+			Name:      peer.name,
+			Reachable: peer.reachable,
+			RTT:       peer.rtt.String(),
+		}
+		if peer.conn != nil {
+			p.Connection = peer.conn.GetState().String()
+		}
+		if !peer.lastSuccess.IsZero() {
+			p.LastSuccess = peer.lastSuccess.Format(time.RFC3339Nano)
+		}
+		for _, v := range peer.votes {
+			p.Votes = append(p.Votes, &pb.StatusResponse_Vote{
+				Phase:   v.phase,
+				ID:      v.id,
+				Outcome: v.outcome,
+			})
+		}
+		status.Peers = append(status.Peers, &p)
+	}
+
+	return &status
+}
@@ -0,0 +1,129 @@
+package skinny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInstanceWatchStatusRPC(t *testing.T) {
+	leader := newMockInstance(t, "leader", 1, time.Second)
+	defer leader.destroy()
+	peer1 := newMockInstance(t, "peer-1", 2, time.Second)
+	defer peer1.destroy()
+	peer2 := newMockInstance(t, "peer-2", 3, time.Second)
+	defer peer2.destroy()
+	for _, peer := range []*mockInstance{peer1, peer2} {
+		if err := leader.in.AddPeer(peer.in.name, NewGRPCTransport(peer.conn)); err != nil {
+			t.Fatalf("add peer: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := control.NewControlClient(leader.conn).WatchStatus(ctx, &control.WatchStatusRequest{})
+	if err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	t.Run("initial status", func(t *testing.T) {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Name != "leader" || resp.Promised != 0 || len(resp.Peers) != 2 {
+			t.Errorf("expected initial status of leader, got `%v`", resp)
+		}
+		if !resp.Peers[0].Reachable {
+			t.Errorf("expected `%v`, got `%v`", true, resp.Peers[0].Reachable)
+		}
+	})
+
+	t.Run("transitions", func(t *testing.T) {
+		peer2.fail = true
+		_, err := leader.in.Acquire(context.Background(), &lock.AcquireRequest{Holder: beaver})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+
+		// the instance becomes a proposer before it proposes
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Role != roleProposer || resp.Promised != 0 {
+			t.Errorf("expected proposer without promise, got `%v`", resp)
+		}
+
+		// the proposal is visible before the commit
+		resp, err = stream.Recv()
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Promised != 1 || resp.Holder != "" {
+			t.Errorf("expected promised ID 1 without holder, got `%v`", resp)
+		}
+
+		// the last update carries the committed holder and the unreachable peer
+		for resp.Holder != beaver || resp.Peers[1].Reachable {
+			resp, err = stream.Recv()
+			if err != nil {
+				t.Fatalf("expected `%v`, got `%v`", nil, err)
+			}
+		}
+		if resp.ID != 1 {
+			t.Errorf("expected `%v`, got `%v`", 1, resp.ID)
+		}
+		if !resp.Peers[0].Reachable {
+			t.Errorf("expected `%v`, got `%v`", true, resp.Peers[0].Reachable)
+		}
+	})
+
+	t.Run("stop watchers", func(t *testing.T) {
+		leader.in.StopWatchers()
+		// pending updates are delivered before the stream ends
+		var err error
+		for err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("expected `%v`, got `%v`", codes.Unavailable, err)
+		}
+
+		stream, err := control.NewControlClient(leader.conn).WatchStatus(ctx, &control.WatchStatusRequest{})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		_, err = stream.Recv()
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("expected `%v`, got `%v`", codes.Unavailable, err)
+		}
+	})
+}
+
+func TestInstanceNotify(t *testing.T) {
+	in := Instance{
+		watchers: make(map[chan *control.StatusResponse]struct{}),
+	}
+	updates := make(chan *control.StatusResponse, 2)
+	in.watchers[updates] = struct{}{}
+
+	// a slow watcher misses intermediate updates, but gets the latest one
+	for id := uint64(1); id <= 5; id++ {
+		in.id = id
+		in.notify()
+	}
+	if len(updates) != 2 {
+		t.Fatalf("expected `%v`, got `%v`", 2, len(updates))
+	}
+	<-updates
+	latest := <-updates
+	if latest.ID != 5 {
+		t.Errorf("expected `%v`, got `%v`", 5, latest.ID)
+	}
+}
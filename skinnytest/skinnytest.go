@@ -0,0 +1,418 @@
+// Package skinnytest runs a quorum of Skinny instances within the current process, e.g. for integration tests of code
+// that uses the lock. Instances talk over in-memory connections using the real Lock, Control, and Consensus servers.
+//
+//	cluster := skinnytest.NewCluster(t, 3)
+//	defer cluster.Close()
+//
+//	c := cluster.Client()
+//	if _, err := c.TryAcquire(ctx, "beaver"); err != nil {
+//		t.Fatal(err)
+//	}
+//	cluster.Partition("instance-1")
+//	defer cluster.Heal()
+package skinnytest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danrl/skinny/client"
+	"github.com/danrl/skinny/config"
+	"github.com/danrl/skinny/proto/consensus"
+	"github.com/danrl/skinny/proto/control"
+	"github.com/danrl/skinny/proto/lock"
+	"github.com/danrl/skinny/skinny"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	// Timeout is the timeout for RPCs made by instances to their peers
+	Timeout = 200 * time.Millisecond
+
+	// ClientTimeout is the timeout for requests made by clients of the cluster
+	ClientTimeout = time.Second
+
+	// bufferSize is the size of the in-memory connections
+	bufferSize = 1024 * 1024
+
+	// reconnectDelay is the longest time a connection waits before it reconnects to an instance that was down
+	reconnectDelay = 50 * time.Millisecond
+)
+
+// Cluster is a quorum of Skinny instances running within the current process
+type Cluster struct {
+	t       testing.TB
+	mu      sync.Mutex
+	names   []string
+	members map[string]*member
+	// cut are the pairs of instances that are partitioned from each other
+	cut     map[string]map[string]bool
+	conns   map[string]*grpc.ClientConn
+	clients []*client.Client
+}
+
+// member is an instance of the cluster and everything it needs to serve
+type member struct {
+	in        *skinny.Instance
+	increment uint64
+	server    *grpc.Server
+	listener  *bufconn.Listener
+	// peers are the connections of the instance to its peers
+	peers   map[string]*grpc.ClientConn
+	running bool
+}
+
+// NewCluster starts a cluster of size instances, named instance-1 to instance-<size>. Close stops it. Setup errors
+// fail the test.
+func NewCluster(t testing.TB, size int) *Cluster {
+	t.Helper()
+	if size < 1 {
+		t.Fatalf("invalid cluster size: %v", size)
+	}
+	c := Cluster{
+		t:       t,
+		members: make(map[string]*member),
+		cut:     make(map[string]map[string]bool),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+	for i := 1; i <= size; i++ {
+		name := fmt.Sprintf("instance-%v", i)
+		c.names = append(c.names, name)
+		c.members[name] = &member{
+			increment: uint64(i),
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.names {
+		c.start(name)
+	}
+	return &c
+}
+
+// Names returns the names of all instances of the cluster
+func (c *Cluster) Names() []string {
+	return append([]string{}, c.names...)
+}
+
+// Config returns the configuration of the quorum, for clients of the cluster. The address of an instance is its name.
+// Connecting to an instance requires the cluster's dial options.
+func (c *Cluster) Config() *config.QuorumConfig {
+	quorum := config.QuorumConfig{
+		Timeout: ClientTimeout,
+	}
+	for _, name := range c.names {
+		quorum.Instances = append(quorum.Instances, config.Instance{
+			Name:    name,
+			Address: name,
+		})
+	}
+	return &quorum
+}
+
+// DialOptions returns the options needed to connect to instances of the cluster
+func (c *Cluster) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(c.dial),
+		grpc.WithBackoffMaxDelay(reconnectDelay),
+	}
+}
+
+// dial connects to the instance at address, i.e. the instance of that name
+func (c *Cluster) dial(ctx context.Context, address string) (net.Conn, error) {
+	c.mu.Lock()
+	m, ok := c.members[address]
+	var listener *bufconn.Listener
+	if ok && m.running {
+		listener = m.listener
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown instance: %v", address)
+	}
+	if listener == nil {
+		return nil, fmt.Errorf("instance stopped: %v", address)
+	}
+	return listener.Dial()
+}
+
+// Conn returns a connection to an instance, e.g. to use the Lock or Control API directly. The connection is closed
+// with the cluster and survives restarts of the instance.
+func (c *Cluster) Conn(name string) *grpc.ClientConn {
+	c.t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.member(name)
+	if conn, ok := c.conns[name]; ok {
+		return conn
+	}
+	conn, err := grpc.Dial(name, c.DialOptions()...)
+	if err != nil {
+		c.t.Fatalf("dial %v: %v", name, err)
+	}
+	c.conns[name] = conn
+	return conn
+}
+
+// Client returns a client for the quorum. It is closed with the cluster.
+func (c *Cluster) Client() *client.Client {
+	c.t.Helper()
+	cl, err := client.New(c.Config())
+	if err != nil {
+		c.t.Fatalf("new client: %v", err)
+	}
+	cl.SetDialOptions(c.DialOptions()...)
+	cl.SetPollInterval(10 * time.Millisecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clients = append(c.clients, cl)
+	return cl
+}
+
+// Instance returns an instance of the cluster. A restart replaces the instance.
+func (c *Cluster) Instance(name string) *skinny.Instance {
+	c.t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.member(name).in
+}
+
+// Holder returns the holder of the lock as known to an instance
+func (c *Cluster) Holder(name string) string {
+	c.t.Helper()
+	resp, err := c.Instance(name).Status(context.Background(), &control.StatusRequest{})
+	if err != nil {
+		c.t.Fatalf("status %v: %v", name, err)
+	}
+	return resp.Holder
+}
+
+// Stop stops an instance. It neither answers its peers nor its clients anymore, and requests it is serving fail.
+func (c *Cluster) Stop(name string) {
+	c.t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stop(name)
+}
+
+// Restart restarts an instance, stopping it first if it is running. Instances keep their state in memory only, so the
+// restarted instance starts from scratch, like a restarted skinnyd. Partitions stay in place. Restart returns once the
+// connections of the cluster and of the other instances are reconnected.
+func (c *Cluster) Restart(name string) {
+	c.t.Helper()
+	c.mu.Lock()
+	c.stop(name)
+	conns := []*grpc.ClientConn{}
+	if conn, ok := c.conns[name]; ok {
+		conns = append(conns, conn)
+	}
+	for _, other := range c.names {
+		if m := c.members[other]; m.running && other != name {
+			conns = append(conns, m.peers[name])
+		}
+	}
+	c.mu.Unlock()
+
+	// Connections notice that the instance stopped a little later. Until they do, they would send requests to the
+	// stopped instance.
+	ctx, cancel := context.WithTimeout(context.Background(), ClientTimeout)
+	defer cancel()
+	for _, conn := range conns {
+		if conn.GetState() == connectivity.Ready && !conn.WaitForStateChange(ctx, connectivity.Ready) {
+			c.t.Fatalf("disconnect from %v: %v", name, ctx.Err())
+		}
+	}
+
+	c.mu.Lock()
+	c.start(name)
+	c.mu.Unlock()
+
+	// connections wait a while before they try again, reconnect right away instead
+	for _, conn := range conns {
+		conn.ResetConnectBackoff()
+		for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+			if !conn.WaitForStateChange(ctx, state) {
+				c.t.Fatalf("reconnect to %v: %v", name, ctx.Err())
+			}
+		}
+	}
+}
+
+// Partition cuts the given instances off from all other instances of the cluster. The given instances can still talk
+// to each other, and clients can still talk to all instances.
+func (c *Cluster) Partition(names ...string) {
+	c.t.Helper()
+	c.mu.Lock()
+	inside := make(map[string]bool)
+	for _, name := range names {
+		c.member(name)
+		inside[name] = true
+	}
+	cuts := []cut{}
+	for _, name := range names {
+		for _, other := range c.names {
+			if inside[other] {
+				continue
+			}
+			cuts = append(cuts, c.cutOff(name, other), c.cutOff(other, name))
+		}
+	}
+	c.mu.Unlock()
+
+	// injecting faults waits for the rounds in progress, the cluster must not be locked meanwhile
+	for _, ct := range cuts {
+		c.partition(ct)
+	}
+}
+
+// Heal removes all partitions
+func (c *Cluster) Heal() {
+	c.t.Helper()
+	c.mu.Lock()
+	instances := make(map[string]*skinny.Instance)
+	for _, name := range c.names {
+		c.cut[name] = nil
+		instances[name] = c.members[name].in
+	}
+	c.mu.Unlock()
+
+	for _, name := range c.names {
+		_, err := instances[name].InjectFault(context.Background(), &control.InjectFaultRequest{
+			Clear: true,
+		})
+		if err != nil {
+			c.t.Fatalf("heal %v: %v", name, err)
+		}
+	}
+}
+
+// Close stops all instances and closes all connections and clients of the cluster
+func (c *Cluster) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cl := range c.clients {
+		_ = cl.Close()
+	}
+	c.clients = nil
+	for name, conn := range c.conns {
+		_ = conn.Close()
+		delete(c.conns, name)
+	}
+	for _, name := range c.names {
+		c.stop(name)
+	}
+}
+
+// member returns an instance of the cluster, it fails the test for unknown instances. Caller must hold a lock on c
+// (Cluster).
+func (c *Cluster) member(name string) *member {
+	c.t.Helper()
+	m, ok := c.members[name]
+	if !ok {
+		c.t.Fatalf("unknown instance: %v", name)
+	}
+	return m
+}
+
+// start starts an instance with a fresh state. Caller must hold a lock on c (Cluster).
+func (c *Cluster) start(name string) {
+	c.t.Helper()
+	m := c.member(name)
+
+	in := skinny.New(name, m.increment, Timeout)
+	in.SetLogLevel(skinny.LogQuiet)
+	m.in = in
+	m.peers = make(map[string]*grpc.ClientConn)
+	for _, peer := range c.names {
+		if peer == name {
+			continue
+		}
+		opts := append(c.DialOptions(), grpc.WithUnaryInterceptor(in.FaultClientInterceptor(peer)))
+		conn, err := grpc.Dial(peer, opts...)
+		if err != nil {
+			c.t.Fatalf("dial %v: %v", peer, err)
+		}
+		m.peers[peer] = conn
+		if err := in.AddPeer(peer, skinny.NewGRPCTransport(conn)); err != nil {
+			c.t.Fatalf("add peer %v: %v", peer, err)
+		}
+	}
+
+	// partitions outlive restarts. The instance does not serve yet, so it is partitioned right away.
+	peers := []string{}
+	for peer := range c.cut[name] {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	for _, peer := range peers {
+		c.partition(cut{name: name, peer: peer, in: in})
+	}
+
+	m.server = grpc.NewServer(grpc.UnaryInterceptor(in.FaultServerInterceptor()))
+	consensus.RegisterConsensusServer(m.server, in)
+	control.RegisterControlServer(m.server, in)
+	lock.RegisterLockServer(m.server, in)
+	m.listener = bufconn.Listen(bufferSize)
+	go func(server *grpc.Server, listener net.Listener) {
+		_ = server.Serve(listener)
+	}(m.server, m.listener)
+	m.running = true
+}
+
+// stop stops an instance if it is running. Caller must hold a lock on c (Cluster).
+func (c *Cluster) stop(name string) {
+	m := c.member(name)
+	if !m.running {
+		return
+	}
+	m.running = false
+	m.in.StopWatchers()
+	m.server.Stop()
+	for _, conn := range m.peers {
+		_ = conn.Close()
+	}
+	m.peers = nil
+}
+
+// cut is an instance cut off from a peer
+type cut struct {
+	name string
+	peer string
+	in   *skinny.Instance
+}
+
+// cutOff records that an instance is cut off from a peer and returns the cut. A restarted instance is partitioned
+// again. Caller must hold a lock on c (Cluster).
+func (c *Cluster) cutOff(name, peer string) cut {
+	if c.cut[name] == nil {
+		c.cut[name] = make(map[string]bool)
+	}
+	c.cut[name][peer] = true
+	return cut{name: name, peer: peer, in: c.members[name].in}
+}
+
+// partition partitions an instance from a peer
+func (c *Cluster) partition(ct cut) {
+	c.t.Helper()
+	_, err := ct.in.InjectFault(context.Background(), &control.InjectFaultRequest{
+		Faults: []*control.Fault{{Peer: ct.peer, Partition: true}},
+	})
+	if err != nil {
+		c.t.Fatalf("partition %v from %v: %v", ct.name, ct.peer, err)
+	}
+}
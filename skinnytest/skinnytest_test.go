@@ -0,0 +1,98 @@
+package skinnytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danrl/skinny/proto/lock"
+)
+
+func TestCluster(t *testing.T) {
+	cluster := NewCluster(t, 3)
+	defer cluster.Close()
+
+	if got := len(cluster.Names()); got != 3 {
+		t.Fatalf("expected `%v` instances, got `%v`", 3, got)
+	}
+	c := cluster.Client()
+	if _, err := c.TryAcquire(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+	for _, name := range cluster.Names() {
+		if got := cluster.Holder(name); got != "beaver" {
+			t.Errorf("%v: expected holder `%v`, got `%v`", name, "beaver", got)
+		}
+	}
+	if _, err := c.Release(context.Background(), "beaver"); err != nil {
+		t.Fatalf("expected `%v`, got `%v`", nil, err)
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		cluster.Stop("instance-1")
+		resp, err := lock.NewLockClient(cluster.Conn("instance-2")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "hamster"})
+		if err != nil || !resp.Acquired {
+			t.Fatalf("expected the majority to grant the lock, got `%v`, `%v`", resp, err)
+		}
+		_, err = lock.NewLockClient(cluster.Conn("instance-1")).Release(context.Background(), &lock.ReleaseRequest{})
+		if err == nil {
+			t.Errorf("expected a stopped instance to fail requests")
+		}
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		cluster.Restart("instance-1")
+		if got := cluster.Holder("instance-1"); got != "" {
+			t.Errorf("expected a restarted instance to start from scratch, got holder `%v`", got)
+		}
+		// the restarted instance learns the holder from its peers
+		resp, err := lock.NewLockClient(cluster.Conn("instance-1")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "beaver"})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Acquired || resp.Holder != "hamster" {
+			t.Errorf("expected holder `%v`, got `%v`", "hamster", resp)
+		}
+		resp2, err := lock.NewLockClient(cluster.Conn("instance-1")).Release(context.Background(),
+			&lock.ReleaseRequest{})
+		if err != nil || !resp2.Released {
+			t.Fatalf("expected release, got `%v`, `%v`", resp2, err)
+		}
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		cluster.Partition("instance-3")
+		resp, err := lock.NewLockClient(cluster.Conn("instance-3")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "dolphin"})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Acquired {
+			t.Errorf("expected a minority not to grant the lock")
+		}
+		resp, err = lock.NewLockClient(cluster.Conn("instance-1")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "beaver"})
+		if err != nil || !resp.Acquired {
+			t.Fatalf("expected the majority to grant the lock, got `%v`, `%v`", resp, err)
+		}
+
+		// partitions outlive restarts
+		cluster.Restart("instance-3")
+		resp, err = lock.NewLockClient(cluster.Conn("instance-3")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "dolphin"})
+		if err != nil || resp.Acquired {
+			t.Errorf("expected a minority not to grant the lock, got `%v`, `%v`", resp, err)
+		}
+
+		cluster.Heal()
+		resp, err = lock.NewLockClient(cluster.Conn("instance-3")).Acquire(context.Background(),
+			&lock.AcquireRequest{Holder: "dolphin"})
+		if err != nil {
+			t.Fatalf("expected `%v`, got `%v`", nil, err)
+		}
+		if resp.Acquired || resp.Holder != "beaver" {
+			t.Errorf("expected holder `%v`, got `%v`", "beaver", resp)
+		}
+	})
+}